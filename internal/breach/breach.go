@@ -0,0 +1,222 @@
+// Package breach checks passwords against a local copy of the Have I Been Pwned
+// (HIBP) Pwned Passwords corpus, so the NIST SP 800-63B comparison against
+// breached passwords never sends a password, or a part of its hash, to an
+// external service.
+//
+// The corpus is read in one of the formats produced by the HIBP downloader,
+// with either the SHA-1 or the NTLM hashes:
+//
+//   - FormatRange: a directory of range files, named after the first five
+//     hexadecimal characters of the hashes and listing the SUFFIX:COUNT lines
+//     of the remaining characters. Each lookup reads a single small file.
+//   - FormatOrdered: a single file of HASH:COUNT lines sorted by hash. Each
+//     lookup is a binary search on the file.
+//   - FormatFilter: a bloom filter built from one of the above by BuildFilter,
+//     held in memory. The lookups are faster and never read the disk, but a
+//     small fraction of the passwords are wrongly reported as breached and the
+//     counts are unknown.
+package breach
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Corpus formats.
+const (
+	// FormatRange is a directory of HIBP range files.
+	FormatRange = "range"
+
+	// FormatOrdered is a single HIBP file sorted by hash.
+	FormatOrdered = "ordered"
+
+	// FormatFilter is a bloom filter built by BuildFilter.
+	FormatFilter = "filter"
+)
+
+// Hash types of the corpus.
+const (
+	// HashSHA1 is the SHA-1 hash of the UTF-8 password.
+	HashSHA1 = "sha1"
+
+	// HashNTLM is the MD4 hash of the UTF-16LE password, as used by Windows.
+	HashNTLM = "ntlm"
+)
+
+var (
+	// ErrInvalidHash is returned when a hash doesn't match the corpus hash
+	// type.
+	ErrInvalidHash = errors.New("invalid hash")
+
+	// errCorpus is wrapped by all the errors reporting an unusable corpus.
+	errCorpus = errors.New("invalid breach corpus")
+)
+
+// store looks up the hashes of a corpus format.
+type store interface {
+	// lookup returns the number of times the hash was seen in the breaches,
+	// or zero when it is not in the corpus.
+	lookup(digest []byte) (int, error)
+
+	// each calls fn with every hash of the corpus and its count.
+	each(fn func(digest []byte, count int) error) error
+
+	// close releases the resources of the store.
+	close() error
+}
+
+// Corpus is a loaded breached-password corpus. It is safe for concurrent use.
+type Corpus struct {
+	format    string
+	hash      string
+	path      string
+	updatedAt time.Time
+	store     store
+}
+
+// Info describes a loaded corpus.
+type Info struct {
+	// Format is the corpus format.
+	Format string `json:"format"`
+
+	// Hash is the hash type of the corpus.
+	Hash string `json:"hash"`
+
+	// UpdatedAt is the last modification time of the corpus files, or the
+	// date of the corpus a filter was built from.
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Probabilistic reports whether a password can be wrongly reported as
+	// breached, as with a filter.
+	Probabilistic bool `json:"probabilistic"`
+}
+
+// Result is the outcome of a corpus lookup.
+type Result struct {
+	// Breached reports whether the password was found in the corpus.
+	Breached bool `json:"breached"`
+
+	// Count is the number of times the password was seen in the breaches. It
+	// is zero when the password is not breached or the count is unknown.
+	Count int `json:"count,omitempty"`
+}
+
+// Load opens the corpus of the given format and hash type at the path, which
+// is a directory for FormatRange and a file otherwise. The format is checked
+// upfront so a misconfigured corpus fails at startup, not on the first lookup.
+func Load(format, hash, path string) (*Corpus, error) {
+	size, err := digestSize(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Corpus{format: format, hash: hash, path: path}
+
+	switch format {
+	case FormatRange:
+		c.store, c.updatedAt, err = openRange(path, size)
+	case FormatOrdered:
+		c.store, c.updatedAt, err = openOrdered(path, size)
+	case FormatFilter:
+		c.store, c.updatedAt, err = openFilter(path, hash)
+	default:
+		return nil, fmt.Errorf("%w: unknown format %q", errCorpus, format)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Close releases the open files and the memory of the corpus.
+func (c *Corpus) Close() error {
+	return c.store.close()
+}
+
+// Info returns the description of the corpus.
+func (c *Corpus) Info() Info {
+	return Info{
+		Format:        c.format,
+		Hash:          c.hash,
+		UpdatedAt:     c.updatedAt,
+		Probabilistic: c.format == FormatFilter,
+	}
+}
+
+// Age returns the time elapsed since the corpus was last updated.
+func (c *Corpus) Age() time.Duration {
+	return time.Since(c.updatedAt)
+}
+
+// Verify reports whether the corpus files are still readable, unless the
+// corpus is a filter held in memory, and the corpus is not older than maxAge,
+// unless maxAge is zero.
+func (c *Corpus) Verify(maxAge time.Duration) error {
+	if c.format != FormatFilter {
+		_, err := os.Stat(c.path)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errCorpus, err)
+		}
+	}
+
+	if maxAge > 0 && c.Age() > maxAge {
+		return fmt.Errorf("%w: updated on %s, older than the maximum age of %s", errCorpus, c.updatedAt.UTC().Format(time.DateOnly), maxAge)
+	}
+
+	return nil
+}
+
+// Check looks up the password in the corpus.
+func (c *Corpus) Check(password string) (Result, error) {
+	digest, err := hashPassword(c.hash, password)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return c.lookup(digest)
+}
+
+// CheckHash looks up the hexadecimal hash of a password in the corpus, so the
+// clients don't need to send the password itself.
+func (c *Corpus) CheckHash(hexHash string) (Result, error) {
+	size, err := digestSize(c.hash)
+	if err != nil {
+		return Result{}, err
+	}
+
+	digest, err := hex.DecodeString(hexHash)
+	if err != nil || len(digest) != size {
+		return Result{}, fmt.Errorf("%w: expected %d hexadecimal characters of a %s hash", ErrInvalidHash, 2*size, strings.ToUpper(c.hash))
+	}
+
+	return c.lookup(digest)
+}
+
+// Contains reports whether the password is in the corpus. It implements the
+// password.Blocklist interface.
+func (c *Corpus) Contains(password string) (bool, error) {
+	res, err := c.Check(password)
+
+	return res.Breached, err
+}
+
+// lookup looks up the binary hash in the corpus.
+func (c *Corpus) lookup(digest []byte) (Result, error) {
+	count, err := c.store.lookup(digest)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed reading the breach corpus: %w", err)
+	}
+
+	if c.format == FormatFilter {
+		// the filter only tells whether the hash is possibly in the corpus
+		return Result{Breached: count > 0}, nil
+	}
+
+	return Result{Breached: count > 0, Count: count}, nil
+}
@@ -0,0 +1,425 @@
+package breach
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testBreached maps the breached passwords of the test corpora to their count.
+var testBreached = map[string]int{ //nolint:gochecknoglobals
+	"password":  9545824,
+	"123456":    37359195,
+	"qwerty":    3912816,
+	"letmein":   288,
+	"trustno1":  5,
+	"hunter2":   1,
+	"p@ssw0rd!": 12,
+}
+
+// testClean lists passwords absent from the test corpora.
+var testClean = []string{"", "Password", "correct horse battery staple", "0"} //nolint:gochecknoglobals
+
+// writeOrdered writes the ordered corpus of the test passwords, with a padding
+// line of count zero, and returns its path.
+func writeOrdered(t *testing.T, hash string) string {
+	t.Helper()
+
+	lines := []string{strings.Repeat("0", 2*mustDigestSize(t, hash)) + ":0"}
+
+	for pwd, count := range testBreached {
+		digest, err := hashPassword(hash, pwd)
+		require.NoError(t, err)
+
+		lines = append(lines, fmt.Sprintf("%s:%d", upperHex(digest), count))
+	}
+
+	slices.Sort(lines)
+
+	path := filepath.Join(t.TempDir(), "pwned-passwords-"+hash+"-ordered-by-hash.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0o600))
+
+	return path
+}
+
+// writeRange writes the range files of the test passwords, along with the
+// first range file and the ones of the clean passwords, and returns the
+// directory.
+func writeRange(t *testing.T, hash string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := map[string][]string{"00000": {strings.Repeat("0", 2*mustDigestSize(t, hash)-rangePrefixLen) + ":0"}}
+
+	for _, pwd := range testClean {
+		digest, err := hashPassword(hash, pwd)
+		require.NoError(t, err)
+
+		files[string(upperHex(digest)[:rangePrefixLen])] = nil
+	}
+
+	for pwd, count := range testBreached {
+		digest, err := hashPassword(hash, pwd)
+		require.NoError(t, err)
+
+		h := string(upperHex(digest))
+		files[h[:rangePrefixLen]] = append(files[h[:rangePrefixLen]], fmt.Sprintf("%s:%d", h[rangePrefixLen:], count))
+	}
+
+	for prefix, lines := range files {
+		slices.Sort(lines)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, prefix+rangeExt), []byte(strings.Join(lines, "\r\n")), 0o600))
+	}
+
+	return dir
+}
+
+func mustDigestSize(t *testing.T, hash string) int {
+	t.Helper()
+
+	size, err := digestSize(hash)
+	require.NoError(t, err)
+
+	return size
+}
+
+func TestHashPassword(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		hash     string
+		password string
+		want     string
+	}{
+		{hash: HashSHA1, password: "password", want: "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8"},
+		{hash: HashSHA1, password: "", want: "DA39A3EE5E6B4B0D3255BFEF95601890AFD80709"},
+		{hash: HashNTLM, password: "password", want: "8846F7EAEE8FB117AD06BDD830B7586C"},
+		{hash: HashNTLM, password: "", want: "31D6CFE0D16AE931B73C59D7E0C089C0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.hash+"/"+tt.password, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := hashPassword(tt.hash, tt.password)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(upperHex(got)))
+		})
+	}
+
+	_, err := hashPassword("md5", "password")
+	require.Error(t, err)
+}
+
+func TestCorpus(t *testing.T) {
+	t.Parallel()
+
+	for _, hash := range []string{HashSHA1, HashNTLM} {
+		paths := map[string]string{
+			FormatRange:   writeRange(t, hash),
+			FormatOrdered: writeOrdered(t, hash),
+		}
+
+		for format, path := range paths {
+			t.Run(format+"/"+hash, func(t *testing.T) {
+				t.Parallel()
+
+				c, err := Load(format, hash, path)
+				require.NoError(t, err)
+
+				t.Cleanup(func() { require.NoError(t, c.Close()) })
+
+				for pwd, count := range testBreached {
+					res, err := c.Check(pwd)
+					require.NoError(t, err)
+					require.Equal(t, Result{Breached: true, Count: count}, res, pwd)
+
+					ok, err := c.Contains(pwd)
+					require.NoError(t, err)
+					require.True(t, ok, pwd)
+				}
+
+				for _, pwd := range testClean {
+					res, err := c.Check(pwd)
+					require.NoError(t, err)
+					require.False(t, res.Breached, pwd)
+				}
+
+				// the padding lines are not breached
+				res, err := c.CheckHash(strings.Repeat("0", 2*mustDigestSize(t, hash)))
+				require.NoError(t, err)
+				require.False(t, res.Breached)
+
+				digest, err := hashPassword(hash, "letmein")
+				require.NoError(t, err)
+
+				res, err = c.CheckHash(hex.EncodeToString(digest))
+				require.NoError(t, err)
+				require.Equal(t, Result{Breached: true, Count: 288}, res)
+
+				_, err = c.CheckHash("abc")
+				require.ErrorIs(t, err, ErrInvalidHash)
+
+				_, err = c.CheckHash(strings.Repeat("z", 2*mustDigestSize(t, hash)))
+				require.ErrorIs(t, err, ErrInvalidHash)
+
+				info := c.Info()
+				require.Equal(t, format, info.Format)
+				require.Equal(t, hash, info.Hash)
+				require.False(t, info.Probabilistic)
+				require.WithinDuration(t, time.Now(), info.UpdatedAt, time.Minute)
+			})
+		}
+	}
+}
+
+func TestCorpusVerify(t *testing.T) {
+	t.Parallel()
+
+	path := writeOrdered(t, HashSHA1)
+
+	c, err := Load(FormatOrdered, HashSHA1, path)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { require.NoError(t, c.Close()) })
+
+	require.NoError(t, c.Verify(0))
+	require.NoError(t, c.Verify(time.Hour))
+	require.Less(t, c.Age(), time.Hour)
+
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(path, old, old))
+
+	c2, err := Load(FormatOrdered, HashSHA1, path)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { require.NoError(t, c2.Close()) })
+
+	require.Error(t, c2.Verify(24*time.Hour))
+	require.NoError(t, c2.Verify(72*time.Hour))
+
+	require.NoError(t, os.Remove(path))
+	require.Error(t, c.Verify(0))
+}
+
+func TestLoadErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ordered := writeOrdered(t, HashSHA1)
+
+	malformed := filepath.Join(dir, "malformed.txt")
+	require.NoError(t, os.WriteFile(malformed, []byte("not a corpus\n"), 0o600))
+
+	tests := []struct {
+		name   string
+		format string
+		hash   string
+		path   string
+	}{
+		{name: "unknown format", format: "csv", hash: HashSHA1, path: ordered},
+		{name: "unknown hash", format: FormatOrdered, hash: "md5", path: ordered},
+		{name: "range missing", format: FormatRange, hash: HashSHA1, path: filepath.Join(dir, "missing")},
+		{name: "range not a directory", format: FormatRange, hash: HashSHA1, path: ordered},
+		{name: "range without files", format: FormatRange, hash: HashSHA1, path: dir},
+		{name: "ordered missing", format: FormatOrdered, hash: HashSHA1, path: filepath.Join(dir, "missing")},
+		{name: "ordered directory", format: FormatOrdered, hash: HashSHA1, path: dir},
+		{name: "ordered malformed", format: FormatOrdered, hash: HashSHA1, path: malformed},
+		{name: "ordered wrong hash", format: FormatOrdered, hash: HashNTLM, path: ordered},
+		{name: "filter missing", format: FormatFilter, hash: HashSHA1, path: filepath.Join(dir, "missing")},
+		{name: "filter not a filter", format: FormatFilter, hash: HashSHA1, path: ordered},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Load(tt.format, tt.hash, tt.path)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestRangeMalformed(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRange(t, HashSHA1)
+
+	digest, err := hashPassword(HashSHA1, "password")
+	require.NoError(t, err)
+
+	h := string(upperHex(digest))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, h[:rangePrefixLen]+rangeExt), []byte("no count\n"), 0o600))
+
+	c, err := Load(FormatRange, HashSHA1, dir)
+	require.NoError(t, err)
+
+	_, err = c.Check("password")
+	require.Error(t, err)
+
+	// a missing range file means an incomplete corpus
+	_, err = c.Check("not in any range file of the test corpus")
+	require.Error(t, err)
+}
+
+func TestBuildFilter(t *testing.T) {
+	t.Parallel()
+
+	for _, hash := range []string{HashSHA1, HashNTLM} {
+		t.Run(hash, func(t *testing.T) {
+			t.Parallel()
+
+			src, err := Load(FormatOrdered, hash, writeOrdered(t, hash))
+			require.NoError(t, err)
+
+			t.Cleanup(func() { require.NoError(t, src.Close()) })
+
+			corpusUpdated := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+			path := filepath.Join(t.TempDir(), "corpus.filter")
+
+			var buf bytes.Buffer
+
+			require.NoError(t, BuildFilter(&buf, src, 0.001, 1, corpusUpdated))
+			require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+			c, err := Load(FormatFilter, hash, path)
+			require.NoError(t, err)
+
+			t.Cleanup(func() { require.NoError(t, c.Close()) })
+
+			for pwd := range testBreached {
+				res, err := c.Check(pwd)
+				require.NoError(t, err)
+				require.Equal(t, Result{Breached: true}, res, pwd)
+			}
+
+			res, err := c.Check("correct horse battery staple")
+			require.NoError(t, err)
+			require.False(t, res.Breached)
+
+			info := c.Info()
+			require.Equal(t, Info{Format: FormatFilter, Hash: hash, UpdatedAt: corpusUpdated.Local(), Probabilistic: true}, info)
+			require.NoError(t, c.Verify(0))
+			require.Error(t, c.Verify(time.Hour))
+
+			other := HashNTLM
+			if hash == HashNTLM {
+				other = HashSHA1
+			}
+
+			_, err = Load(FormatFilter, other, path)
+			require.Error(t, err)
+
+			// truncated filter
+			require.NoError(t, os.WriteFile(path, buf.Bytes()[:buf.Len()-1], 0o600))
+
+			_, err = Load(FormatFilter, hash, path)
+			require.Error(t, err)
+
+			// trailing data
+			require.NoError(t, os.WriteFile(path, append(slices.Clone(buf.Bytes()), 0), 0o600))
+
+			_, err = Load(FormatFilter, hash, path)
+			require.Error(t, err)
+
+			// corrupt size in the header, checked before allocating the bits
+			for _, bits := range []uint64{0, 1 << 62, binary.LittleEndian.Uint64(buf.Bytes()[16:24]) + 64} {
+				data := slices.Clone(buf.Bytes())
+				binary.LittleEndian.PutUint64(data[16:24], bits)
+				require.NoError(t, os.WriteFile(path, data, 0o600))
+
+				_, err = Load(FormatFilter, hash, path)
+				require.ErrorIs(t, err, errCorpus)
+			}
+
+			// a filter can't be rebuilt
+			require.Error(t, BuildFilter(&buf, c, 0.001, 1, corpusUpdated))
+		})
+	}
+}
+
+func TestBuildFilterErrors(t *testing.T) {
+	t.Parallel()
+
+	src, err := Load(FormatRange, HashSHA1, writeRange(t, HashSHA1))
+	require.NoError(t, err)
+
+	require.Error(t, BuildFilter(&bytes.Buffer{}, src, 0, 1, time.Now()))
+	require.Error(t, BuildFilter(&bytes.Buffer{}, src, 1, 1, time.Now()))
+
+	// the whole range directory isn't present in the test corpus
+	require.Error(t, BuildFilter(&bytes.Buffer{}, src, 0.001, 10, time.Now()))
+}
+
+func TestFilterFalsePositiveRate(t *testing.T) {
+	t.Parallel()
+
+	const n = 10000
+
+	s := newFilterStore(n, 0.01)
+
+	for i := range n {
+		digest, err := hashPassword(HashSHA1, fmt.Sprintf("in-%d", i))
+		require.NoError(t, err)
+
+		s.add(digest)
+	}
+
+	var fp int
+
+	for i := range n {
+		digest, err := hashPassword(HashSHA1, fmt.Sprintf("out-%d", i))
+		require.NoError(t, err)
+
+		found, err := s.lookup(digest)
+		require.NoError(t, err)
+
+		fp += found
+	}
+
+	require.Less(t, fp, 2*n/100)
+}
+
+func TestOrderedLookupLarge(t *testing.T) {
+	t.Parallel()
+
+	const n = 5000
+
+	lines := make([]string, 0, n)
+
+	for i := range n {
+		digest, err := hashPassword(HashSHA1, fmt.Sprintf("in-%d", i))
+		require.NoError(t, err)
+
+		lines = append(lines, fmt.Sprintf("%s:%d", upperHex(digest), i+1))
+	}
+
+	slices.Sort(lines)
+
+	path := filepath.Join(t.TempDir(), "large.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600))
+
+	c, err := Load(FormatOrdered, HashSHA1, path)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { require.NoError(t, c.Close()) })
+
+	for i := range n {
+		res, err := c.Check(fmt.Sprintf("in-%d", i))
+		require.NoError(t, err)
+		require.Equal(t, Result{Breached: true, Count: i + 1}, res)
+
+		res, err = c.Check(fmt.Sprintf("out-%d", i))
+		require.NoError(t, err)
+		require.False(t, res.Breached)
+	}
+}
@@ -0,0 +1,75 @@
+package breach
+
+import (
+	"bytes"
+	"crypto/sha1" //nolint:gosec
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/md4" //nolint:staticcheck
+)
+
+// Sizes in bytes of the hashes.
+const (
+	sha1Size = 20
+	ntlmSize = 16
+)
+
+// digestSize returns the size in bytes of the hashes of the type.
+func digestSize(hash string) (int, error) {
+	switch hash {
+	case HashSHA1:
+		return sha1Size, nil
+	case HashNTLM:
+		return ntlmSize, nil
+	default:
+		return 0, fmt.Errorf("%w: unknown hash type %q", errCorpus, hash)
+	}
+}
+
+// hashPassword returns the binary hash of the password. SHA-1 and MD4 are only
+// used because they are the hashes of the HIBP corpus.
+func hashPassword(hash, password string) ([]byte, error) {
+	switch hash {
+	case HashSHA1:
+		sum := sha1.Sum([]byte(password)) //nolint:gosec
+
+		return sum[:], nil
+	case HashNTLM:
+		h := md4.New()
+
+		for _, u := range utf16.Encode([]rune(password)) {
+			_, _ = h.Write(binary.LittleEndian.AppendUint16(nil, u))
+		}
+
+		return h.Sum(nil), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown hash type %q", errCorpus, hash)
+	}
+}
+
+// upperHex returns the uppercase hexadecimal encoding of the corpus files.
+func upperHex(b []byte) []byte {
+	return bytes.ToUpper(hex.AppendEncode(nil, b))
+}
+
+// parseLine splits a HASH:COUNT line of a corpus file, ignoring the trailing
+// carriage return of the files downloaded on Windows.
+func parseLine(line []byte) ([]byte, int, error) {
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+
+	hash, count, ok := bytes.Cut(line, []byte{':'})
+	if !ok {
+		return nil, 0, fmt.Errorf("%w: malformed line %q", errCorpus, line)
+	}
+
+	n, err := strconv.Atoi(string(count))
+	if err != nil || n < 0 {
+		return nil, 0, fmt.Errorf("%w: malformed count in line %q", errCorpus, line)
+	}
+
+	return hash, n, nil
+}
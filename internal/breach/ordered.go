@@ -0,0 +1,162 @@
+package breach
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// orderedWindow is the number of bytes read to find a line of an ordered file,
+// which holds the end of the previous line and a whole HASH:COUNT line.
+const orderedWindow = 256
+
+// orderedStore reads a single file of HASH:COUNT lines sorted by hash.
+type orderedStore struct {
+	f    *os.File
+	size int64
+
+	// hexLen is the number of hexadecimal characters of the hashes.
+	hexLen int
+}
+
+// openOrdered opens the ordered file, checking that its first line is a hash
+// of the expected size. The corpus is as recent as the file.
+func openOrdered(path string, size int) (store, time.Time, error) {
+	f, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("%w: %w", errCorpus, err)
+	}
+
+	fi, err := f.Stat()
+	if err == nil && fi.IsDir() {
+		err = fmt.Errorf("%s is a directory", path)
+	}
+
+	if err != nil {
+		_ = f.Close()
+		return nil, time.Time{}, fmt.Errorf("%w: %w", errCorpus, err)
+	}
+
+	s := &orderedStore{f: f, size: fi.Size(), hexLen: 2 * size}
+
+	_, hash, _, err := s.lineAt(0)
+	if err == nil && len(hash) != s.hexLen {
+		err = fmt.Errorf("%w: the hashes are not %d hexadecimal characters long", errCorpus, s.hexLen)
+	}
+
+	if err != nil {
+		_ = f.Close()
+		return nil, time.Time{}, err
+	}
+
+	return s, fi.ModTime(), nil
+}
+
+// lookup implements the store interface. It binary searches the start of the
+// line of the hash, which is always in [lo, hi).
+func (s *orderedStore) lookup(digest []byte) (int, error) {
+	target := upperHex(digest)
+	lo, hi := int64(0), s.size
+
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+
+		start, hash, count, err := s.lineAt(mid)
+		if errors.Is(err, io.EOF) || start >= hi {
+			// no line starts in [mid, hi)
+			hi = mid
+			continue
+		}
+
+		if err != nil {
+			return 0, err
+		}
+
+		switch bytes.Compare(bytes.ToUpper(hash), target) {
+		case 0:
+			return count, nil
+		case -1:
+			lo = start + 1
+		default:
+			hi = mid
+		}
+	}
+
+	return 0, nil
+}
+
+// lineAt returns the first line starting at or after the offset, along with
+// its start offset, or io.EOF when there is none.
+func (s *orderedStore) lineAt(off int64) (int64, []byte, int, error) {
+	start := off
+	if off > 0 {
+		// the line starts at off only if the previous byte ends a line
+		start = off - 1
+	}
+
+	buf := make([]byte, orderedWindow)
+
+	n, err := s.f.ReadAt(buf, start)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, nil, 0, fmt.Errorf("%w: %w", errCorpus, err)
+	}
+
+	buf = buf[:n]
+
+	if off > 0 {
+		i := bytes.IndexByte(buf, '\n')
+		if i < 0 {
+			return 0, nil, 0, io.EOF
+		}
+
+		buf = buf[i+1:]
+		start += int64(i) + 1
+	}
+
+	line, _, _ := bytes.Cut(buf, []byte{'\n'})
+	if len(line) == 0 {
+		return 0, nil, 0, io.EOF
+	}
+
+	hash, count, err := parseLine(line)
+
+	return start, hash, count, err
+}
+
+// each implements the store interface.
+func (s *orderedStore) each(fn func(digest []byte, count int) error) error {
+	sc := bufio.NewScanner(io.NewSectionReader(s.f, 0, s.size))
+
+	for sc.Scan() {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+
+		hash, count, err := parseLine(sc.Bytes())
+		if err != nil {
+			return err
+		}
+
+		digest, err := hex.DecodeString(string(hash))
+		if err != nil || len(digest) != s.hexLen/2 {
+			return fmt.Errorf("%w: malformed hash %s", errCorpus, hash)
+		}
+
+		err = fn(digest, count)
+		if err != nil {
+			return err
+		}
+	}
+
+	return sc.Err() //nolint:wrapcheck
+}
+
+// close implements the store interface.
+func (s *orderedStore) close() error {
+	return s.f.Close() //nolint:wrapcheck
+}
@@ -0,0 +1,139 @@
+package breach
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Layout of the range files.
+const (
+	// rangePrefixLen is the number of hexadecimal characters of the hash
+	// prefix naming each range file.
+	rangePrefixLen = 5
+
+	// rangeCount is the number of range files.
+	rangeCount = 1 << (4 * rangePrefixLen)
+
+	// rangeExt is the extension of the range files.
+	rangeExt = ".txt"
+)
+
+// rangeStore reads a directory of range files.
+type rangeStore struct {
+	dir  string
+	size int
+}
+
+// openRange opens the directory of range files, checking that the first range
+// is present. The corpus is as recent as the directory or the first range file.
+func openRange(dir string, size int) (store, time.Time, error) {
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("%w: %w", errCorpus, err)
+	}
+
+	if !fi.IsDir() {
+		return nil, time.Time{}, fmt.Errorf("%w: %s is not a directory of range files", errCorpus, dir)
+	}
+
+	s := &rangeStore{dir: dir, size: size}
+
+	first, err := os.Stat(s.file(0))
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("%w: missing range file: %w", errCorpus, err)
+	}
+
+	updatedAt := fi.ModTime()
+	if first.ModTime().After(updatedAt) {
+		updatedAt = first.ModTime()
+	}
+
+	return s, updatedAt, nil
+}
+
+// file returns the path of the range file of the prefix.
+func (s *rangeStore) file(prefix int) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%0*X", rangePrefixLen, prefix)+rangeExt)
+}
+
+// lookup implements the store interface.
+func (s *rangeStore) lookup(digest []byte) (int, error) {
+	h := upperHex(digest)
+
+	var (
+		suffix = h[rangePrefixLen:]
+		count  int
+	)
+
+	err := s.scan(string(h[:rangePrefixLen]), func(hash []byte, n int) error {
+		if bytes.EqualFold(hash, suffix) {
+			count = n
+		}
+
+		return nil
+	})
+
+	return count, err
+}
+
+// each implements the store interface.
+func (s *rangeStore) each(fn func(digest []byte, count int) error) error {
+	for i := range rangeCount {
+		prefix := fmt.Sprintf("%0*X", rangePrefixLen, i)
+
+		err := s.scan(prefix, func(hash []byte, n int) error {
+			digest, err := hex.DecodeString(prefix + string(hash))
+			if err != nil || len(digest) != s.size {
+				return fmt.Errorf("%w: malformed hash %s%s", errCorpus, prefix, hash)
+			}
+
+			return fn(digest, n)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scan calls fn with the hash suffix and count of every line of the range
+// file of the prefix.
+func (s *rangeStore) scan(prefix string, fn func(hash []byte, count int) error) error {
+	f, err := os.Open(filepath.Join(s.dir, prefix+rangeExt)) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("%w: %w", errCorpus, err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	sc := bufio.NewScanner(f)
+
+	for sc.Scan() {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+
+		hash, count, err := parseLine(sc.Bytes())
+		if err != nil {
+			return err
+		}
+
+		err = fn(hash, count)
+		if err != nil {
+			return err
+		}
+	}
+
+	return sc.Err() //nolint:wrapcheck
+}
+
+// close implements the store interface.
+func (s *rangeStore) close() error {
+	return nil
+}
@@ -0,0 +1,299 @@
+// Package charset expands the named charset presets and class expressions.
+//
+// An expression is made of preset names and double-quoted literal strings
+// joined by "+" (add the characters) or "-" (remove the characters), evaluated
+// from left to right, e.g.:
+//
+//	upper+lower+digit-"0O1l"
+//
+// A space is also a "+" operator, as it is how an unescaped "+" in a URL query
+// string is decoded. A backslash in a quoted literal escapes the following
+// character. Any value that is not a valid expression, or that refers to a
+// name that is not a preset, is a literal charset and is returned unchanged, so
+// literal charsets such as "ABCDEF-abcdef" or "0-9a-f" keep working.
+package charset
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Character sets of the presets.
+const (
+	// Upper contains the ASCII uppercase letters.
+	Upper = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+	// Lower contains the ASCII lowercase letters.
+	Lower = "abcdefghijklmnopqrstuvwxyz"
+
+	// Digits contains the decimal digits.
+	Digits = "0123456789"
+
+	// Symbols contains the printable ASCII characters that are neither letters
+	// nor digits, space excluded.
+	Symbols = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+	// Printable contains all the printable ASCII characters, space excluded.
+	Printable = "!\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~"
+
+	// Base32 is the RFC 4648 base32 alphabet.
+	Base32 = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+
+	// Base58 is the Bitcoin base58 alphabet, without 0, O, I and l.
+	Base58 = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+	// URLSafe is the RFC 4648 base64url alphabet.
+	URLSafe = Upper + Lower + Digits + "-_"
+)
+
+// presets maps the preset names to their characters.
+var presets = map[string]string{ //nolint:gochecknoglobals
+	"upper":     Upper,
+	"lower":     Lower,
+	"digit":     Digits,
+	"digits":    Digits,
+	"symbol":    Symbols,
+	"symbols":   Symbols,
+	"alpha":     Upper + Lower,
+	"alnum":     Upper + Lower + Digits,
+	"hex":       Digits + "abcdef",
+	"HEX":       Digits + "ABCDEF",
+	"base32":    Base32,
+	"base58":    Base58,
+	"urlsafe":   URLSafe,
+	"printable": Printable,
+}
+
+// categories are the Unicode categories allowed in a charset: letters,
+// numbers, punctuation and symbols of any script. Spaces, control and format
+// characters and combining marks are excluded, as they are invisible or change
+// the neighboring characters.
+var categories = []*unicode.RangeTable{unicode.L, unicode.N, unicode.P, unicode.S} //nolint:gochecknoglobals
+
+// Set is a charset setting: a literal charset, a preset or a class expression.
+type Set string
+
+// Problem returns the description of the first invalid character of the
+// expanded charset, or an empty string if the charset is valid. When the value
+// looks like an expression with unknown names, they are reported too, as they
+// are the likely cause. It is used by the validation error messages.
+func (s Set) Problem() string {
+	chars := Expand(string(s))
+
+	problem := invalidChar(chars)
+	if problem == "" {
+		return ""
+	}
+
+	if names := unknownNames(string(s)); len(names) > 0 {
+		problem += fmt.Sprintf("; %q is not a preset, valid presets are: %s", names[0], strings.Join(Presets(), ", "))
+	}
+
+	return problem
+}
+
+// invalidChar describes the first character of the string that is invalid
+// UTF-8 or outside of the allowed Unicode categories, if any.
+func invalidChar(s string) string {
+	if !utf8.ValidString(s) {
+		return "invalid UTF-8 encoding"
+	}
+
+	for _, c := range s {
+		if !unicode.IsOneOf(categories, c) {
+			return fmt.Sprintf("invalid character %q", c)
+		}
+	}
+
+	return ""
+}
+
+// Presets returns the sorted names of the presets.
+func Presets() []string {
+	names := make([]string, 0, len(presets))
+
+	for name := range presets {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// Translator returns a strings.Replacer mapping each character of the ASCII
+// string from to the character of to at the same position, e.g. to convert
+// the digits of big.Int.Text to another alphabet of the same base.
+func Translator(from, to string) *strings.Replacer {
+	pairs := make([]string, 0, 2*len(from))
+
+	for i := range len(from) {
+		pairs = append(pairs, from[i:i+1], to[i:i+1])
+	}
+
+	return strings.NewReplacer(pairs...)
+}
+
+// term is an operand of an expression.
+type term struct {
+	remove  bool   // the term follows a "-" operator
+	name    string // preset name, empty for literals
+	literal string // characters of a quoted literal
+}
+
+// Expand returns the characters of a preset or class expression, in order of
+// first appearance and without duplicates, or the value itself when it is a
+// literal charset. A value is only expanded when all of its names are presets.
+func Expand(value string) string {
+	terms, ok := parse(value)
+	if !ok {
+		return value
+	}
+
+	var out []rune
+
+	for _, t := range terms {
+		chars := t.literal
+
+		if t.name != "" {
+			var found bool
+
+			chars, found = presets[t.name]
+			if !found {
+				return value
+			}
+		}
+
+		out = apply(out, chars, t.remove)
+	}
+
+	return string(out)
+}
+
+// unknownNames returns the names that are not presets in a syntactically
+// valid expression.
+func unknownNames(value string) []string {
+	terms, _ := parse(value)
+
+	var names []string
+
+	for _, t := range terms {
+		if _, found := presets[t.name]; t.name != "" && !found {
+			names = append(names, t.name)
+		}
+	}
+
+	return names
+}
+
+// apply adds or removes the characters to the set.
+func apply(set []rune, chars string, remove bool) []rune {
+	if remove {
+		return slices.DeleteFunc(set, func(c rune) bool {
+			return strings.ContainsRune(chars, c)
+		})
+	}
+
+	for _, c := range chars {
+		if !slices.Contains(set, c) {
+			set = append(set, c)
+		}
+	}
+
+	return set
+}
+
+// parse splits an expression into its terms. It reports false if the value is
+// not a syntactically valid expression.
+func parse(value string) ([]term, bool) {
+	var terms []term
+
+	remove := false
+
+	for i := 0; i < len(value); {
+		t, n := parseTerm(value[i:])
+		if n == 0 {
+			return nil, false
+		}
+
+		t.remove = remove
+		terms = append(terms, t)
+		i += n
+
+		if i == len(value) {
+			return terms, true
+		}
+
+		switch value[i] {
+		case '+', ' ':
+			remove = false
+		case '-':
+			remove = true
+		default:
+			return nil, false
+		}
+
+		i++
+
+		if i == len(value) {
+			// trailing operator
+			return nil, false
+		}
+	}
+
+	return nil, false
+}
+
+// parseTerm parses the name or quoted literal at the start of s and returns
+// the number of bytes consumed, or zero if there is no valid term.
+func parseTerm(s string) (term, int) {
+	if s[0] == '"' {
+		return parseLiteral(s)
+	}
+
+	n := 0
+
+	for n < len(s) && isNameChar(s[n], n == 0) {
+		n++
+	}
+
+	return term{name: s[:n]}, n
+}
+
+// parseLiteral parses the quoted literal at the start of s.
+func parseLiteral(s string) (term, int) {
+	var sb strings.Builder
+
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			if sb.Len() == 0 {
+				return term{}, 0
+			}
+
+			return term{literal: sb.String()}, i + 1
+		case '\\':
+			i++
+
+			if i == len(s) {
+				return term{}, 0
+			}
+		}
+
+		sb.WriteByte(s[i])
+	}
+
+	// missing closing quote
+	return term{}, 0
+}
+
+// isNameChar reports whether c can be part of a preset name. Names start with
+// a letter, followed by letters and digits.
+func isNameChar(c byte, first bool) bool {
+	isLetter := (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+
+	return isLetter || (!first && c >= '0' && c <= '9')
+}
@@ -0,0 +1,202 @@
+package charset
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{
+			name:  "literal",
+			value: "abc123",
+			want:  "abc123",
+		},
+		{
+			name:  "literal with duplicates",
+			value: "aabbcc",
+			want:  "aabbcc",
+		},
+		{
+			name:  "single unknown name",
+			value: "xyz",
+			want:  "xyz",
+		},
+		{
+			name:  "literal with symbols",
+			value: "ab+cd!",
+			want:  "ab+cd!",
+		},
+		{
+			name:  "trailing operator",
+			value: "upper+",
+			want:  "upper+",
+		},
+		{
+			name:  "unterminated literal",
+			value: `upper+"abc`,
+			want:  `upper+"abc`,
+		},
+		{
+			name:  "empty literal",
+			value: `""`,
+			want:  `""`,
+		},
+		{
+			name:  "preset",
+			value: "hex",
+			want:  "0123456789abcdef",
+		},
+		{
+			name:  "case-sensitive preset",
+			value: "HEX",
+			want:  "0123456789ABCDEF",
+		},
+		{
+			name:  "preset union",
+			value: "digit+HEX+hex",
+			want:  "0123456789ABCDEFabcdef",
+		},
+		{
+			name:  "class expression",
+			value: `upper+lower+digit-"0O1l"`,
+			want:  "ABCDEFGHIJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz23456789",
+		},
+		{
+			name:  "decoded query expression",
+			value: `upper lower digit-"0O1l"`,
+			want:  "ABCDEFGHIJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz23456789",
+		},
+		{
+			name:  "literal terms",
+			value: `"xyz"+"abc"-"b"`,
+			want:  "xyzac",
+		},
+		{
+			name:  "escaped literal",
+			value: `digits+"\"\\"`,
+			want:  `0123456789"\`,
+		},
+		{
+			name:  "unicode literal",
+			value: `lower-"aeiou"+"αβγ"`,
+			want:  "bcdfghjklmnpqrstvwxyzαβγ",
+		},
+		{
+			name:  "remove first",
+			value: `base58-base32`,
+			want:  "189abcdefghijkmnopqrstuvwxyz",
+		},
+		{
+			name:  "unknown preset in expression",
+			value: "upper+vowels",
+			want:  "upper+vowels",
+		},
+		{
+			name:  "literal with dash",
+			value: "ABCDEF-abcdef",
+			want:  "ABCDEF-abcdef",
+		},
+		{
+			name:  "literal with plus",
+			value: "abc+def",
+			want:  "abc+def",
+		},
+		{
+			name:  "literal range",
+			value: "0-9a-f",
+			want:  "0-9a-f",
+		},
+		{
+			name:  "literal with space",
+			value: "a b",
+			want:  "a b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, Expand(tt.value))
+		})
+	}
+}
+
+func TestPresets(t *testing.T) {
+	t.Parallel()
+
+	names := Presets()
+	require.Contains(t, names, "alnum")
+	require.Contains(t, names, "urlsafe")
+	require.IsIncreasing(t, names)
+
+	for _, name := range names {
+		require.NotEqual(t, name, Expand(name))
+	}
+
+	require.Len(t, URLSafe, 64)
+	require.Len(t, Base58, 58)
+	require.Len(t, Base32, 32)
+	require.Len(t, Printable, 94)
+}
+
+func TestTranslator(t *testing.T) {
+	t.Parallel()
+
+	r := Translator("0123", "wxyz")
+	require.Equal(t, "zyxw-w", r.Replace("3210-0"))
+	require.Equal(t, "", r.Replace(""))
+}
+
+func TestSet_Problem(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		value Set
+		want  string
+	}{
+		{
+			name:  "literal",
+			value: "ABCDEF-abcdef",
+			want:  "",
+		},
+		{
+			name:  "expression",
+			value: `upper+lower-"O"`,
+			want:  "",
+		},
+		{
+			name:  "invalid character",
+			value: "abc\tdef",
+			want:  `invalid character '\t'`,
+		},
+		{
+			name:  "invalid encoding",
+			value: "abc\xff",
+			want:  "invalid UTF-8 encoding",
+		},
+		{
+			name:  "unknown preset",
+			value: "upper lowr",
+			want:  `invalid character ' '; "lowr" is not a preset, valid presets are: ` + strings.Join(Presets(), ", "),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, tt.value.Problem())
+		})
+	}
+}
@@ -18,9 +18,11 @@ import (
 	"github.com/tecnickcom/nurago/pkg/metrics"
 	"github.com/tecnickcom/nurago/pkg/redact"
 	"github.com/tecnickcom/nurago/pkg/traceid"
+	"github.com/tecnickcom/rndpwd/internal/breach"
 	"github.com/tecnickcom/rndpwd/internal/httphandler"
 	instr "github.com/tecnickcom/rndpwd/internal/metrics"
 	"github.com/tecnickcom/rndpwd/internal/password"
+	"github.com/tecnickcom/rndpwd/internal/pwhash"
 	"github.com/tecnickcom/rndpwd/internal/validator"
 )
 
@@ -55,12 +57,26 @@ func bind(cfg *appConfig, appInfo *jsendx.AppInfo, mtr instr.Metrics, wg *sync.W
 			return err
 		}
 
-		serviceBinder, statusHandler := bindServiceHandlers(cfg, appInfo, jsx, l, mtr)
+		serviceBinder, statusHandler, err := bindServiceHandlers(cfg, appInfo, jsx, l, mtr)
+		if err != nil {
+			return err
+		}
 
 		middleware := func(args httpserver.MiddlewareArgs, next http.Handler) http.Handler {
 			return m.InstrumentHandler(args.Path, next.ServeHTTP)
 		}
 
+		// In the deterministic test mode every response carries a warning, so
+		// the reproducible output can't be mistaken for production output.
+		if cfg.Testing.Deterministic {
+			l.Warn("deterministic test mode enabled: the seeded output is reproducible and must never be used as a secret")
+
+			instrument := middleware
+			middleware = func(args httpserver.MiddlewareArgs, next http.Handler) http.Handler {
+				return httphandler.DeterministicWarning(instrument(args, next))
+			}
+		}
+
 		// start monitoring server
 		httpMonitoringOpts := []httpserver.Option{
 			httpserver.WithLogger(l),
@@ -182,16 +198,38 @@ func newIpifyClient(cfg *appConfig, baseHTTPClientOpts []httpclient.Option) (*ip
 //
 // When the service is disabled it returns a no-op binder and the default status
 // handler. When enabled it attaches the real password-generator handler and
-// upgrades the status handler to a health check.
+// upgrades the status handler to a health check of the entropy source, which
+// is also reported in the metrics, and of the breach corpus, if loaded.
 func bindServiceHandlers(
 	cfg *appConfig,
 	appInfo *jsendx.AppInfo,
 	jsx *jsendx.JSXResp,
 	l *slog.Logger,
 	mtr instr.Metrics,
-) (httpserver.Binder, http.HandlerFunc) {
+) (httpserver.Binder, http.HandlerFunc, error) {
 	if !cfg.Enabled {
-		return httpserver.NopBinder(), jsx.DefaultStatusHandler(appInfo)
+		return httpserver.NopBinder(), jsx.DefaultStatusHandler(appInfo), nil
+	}
+
+	src, err := cfg.Random.Source.newSource()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create the entropy source: %w", err)
+	}
+
+	mtr.SetEntropySource(src.Name())
+
+	if src.Name() == password.SourceFile {
+		l.Info("entropy source opened", slog.String("source", src.Name()), slog.String("path", cfg.Random.Source.Path))
+	}
+
+	corpus, err := cfg.Breach.newCorpus()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load the breach corpus: %w", err)
+	}
+
+	if corpus != nil {
+		info := corpus.Info()
+		l.Info("breach corpus loaded", slog.String("format", info.Format), slog.String("hash", info.Hash), slog.Time("updated_at", info.UpdatedAt))
 	}
 
 	// The validation options are static and already proven valid, so New cannot
@@ -203,19 +241,73 @@ func bindServiceHandlers(
 		appInfo,
 		mtr,
 		val,
-		password.New(
-			cfg.Random.Charset,
-			cfg.Random.Length,
-			cfg.Random.Quantity,
-		),
+		cfg.Random.newPassword(),
+		httphandler.WithBytes(cfg.Bytes.newBytes(), cfg.Bytes.MaxSize),
+		httphandler.WithSSHKey(cfg.SSHKey.newSSHKey(), cfg.SSHKey.MaxRSABits, cfg.SSHKey.MaxConcurrent),
+		httphandler.WithPassphrase(cfg.Passphrase.newPassphrase()),
+		httphandler.WithPIN(cfg.PIN.newPIN()),
+		httphandler.WithOTP(cfg.TOTP.newOTP()),
+		httphandler.WithToken(cfg.Token.newToken()),
+		httphandler.WithRecoveryCodes(cfg.Recovery.newRecoveryCodes(), cfg.Recovery.Hash),
+		httphandler.WithUID(cfg.UID.Format, cfg.UID.MaxQuantity),
+		httphandler.WithSnowflake(cfg.UID.SnowflakeNode, cfg.UID.SnowflakeEpoch),
+		httphandler.WithSource(src),
+		httphandler.WithDeterministic(cfg.Testing.Deterministic),
+		httphandler.WithStreamMaxQuantity(cfg.Random.StreamMaxQuantity),
+		httphandler.WithBreachCorpus(corpus, cfg.Breach.RejectPasswords),
+		httphandler.WithPasswordHash(pwhash.Params(cfg.Hash.Defaults), pwhash.Params(cfg.Hash.Max), cfg.Hash.MaxQuantity),
+		httphandler.WithHashMinimum(pwhash.Params(cfg.Hash.Min)),
 	)
 
 	// override the default status handler with a health check
 	healthCheckHandler := healthcheck.NewHandler(
-		[]healthcheck.HealthCheck{},
+		[]healthcheck.HealthCheck{
+			healthcheck.New("entropy_source:"+src.Name(), sourceCheck{src: src}),
+			newBreachHealthCheck(corpus, cfg.Breach.MaxAge),
+		},
 		healthcheck.WithLogger(l),
 		healthcheck.WithResultWriter(jsx.HealthCheckResultWriter(appInfo)),
 	)
 
-	return serviceBinder, healthCheckHandler.ServeHTTP
+	return serviceBinder, healthCheckHandler.ServeHTTP, nil
+}
+
+// sourceCheck is the health check of the entropy source.
+type sourceCheck struct {
+	src password.Source
+}
+
+// HealthCheck implements the healthcheck.HealthChecker interface.
+func (c sourceCheck) HealthCheck(_ context.Context) error {
+	return password.CheckSource(c.src) //nolint:wrapcheck
+}
+
+// breachCheck is the health check of the breach corpus.
+type breachCheck struct {
+	corpus *breach.Corpus
+	maxAge time.Duration
+}
+
+// newBreachHealthCheck returns the health check of the breach corpus, whose ID
+// reports whether the corpus is loaded and, if so, its format, hash type and
+// last update date. The corpus is unhealthy when it is no longer readable or
+// older than maxAgeDays, unless zero.
+func newBreachHealthCheck(corpus *breach.Corpus, maxAgeDays int) healthcheck.HealthCheck {
+	if corpus == nil {
+		return healthcheck.New("breach_corpus:not_loaded", breachCheck{})
+	}
+
+	info := corpus.Info()
+	id := fmt.Sprintf("breach_corpus:%s/%s@%s", info.Format, info.Hash, info.UpdatedAt.UTC().Format(time.DateOnly))
+
+	return healthcheck.New(id, breachCheck{corpus: corpus, maxAge: time.Duration(maxAgeDays) * 24 * time.Hour})
+}
+
+// HealthCheck implements the healthcheck.HealthChecker interface.
+func (c breachCheck) HealthCheck(_ context.Context) error {
+	if c.corpus == nil {
+		return nil
+	}
+
+	return c.corpus.Verify(c.maxAge) //nolint:wrapcheck
 }
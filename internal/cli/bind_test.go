@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/tecnickcom/nurago/pkg/bootstrap"
 	"github.com/tecnickcom/nurago/pkg/httputil/jsendx"
+	"github.com/tecnickcom/rndpwd/internal/breach"
 	"github.com/tecnickcom/rndpwd/internal/metrics"
 )
 
@@ -63,6 +64,31 @@ func Test_bind(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "fails with missing entropy source file",
+			fcfg: func(cfg appConfig) appConfig {
+				cfg.Servers.Monitoring.Address = ":30048"
+				cfg.Servers.Public.Address = ":30049"
+				cfg.Random.Source.Type = "file"
+				cfg.Random.Source.Path = "/nonexistent/hwrng"
+
+				return cfg
+			},
+			wantErr:        true,
+			wantTimeoutErr: false,
+		},
+		{
+			name: "fails with missing breach corpus",
+			fcfg: func(cfg appConfig) appConfig {
+				cfg.Servers.Monitoring.Address = ":30052"
+				cfg.Servers.Public.Address = ":30053"
+				cfg.Breach = breachConfig{Enabled: true, Format: "ordered", Hash: "sha1", Path: "/nonexistent/hibp.txt"}
+
+				return cfg
+			},
+			wantErr:        true,
+			wantTimeoutErr: false,
+		},
 		{
 			name: "fails with bad ipify client address",
 			fcfg: func(cfg appConfig) appConfig {
@@ -83,6 +109,35 @@ func Test_bind(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "success with deterministic test mode",
+			fcfg: func(cfg appConfig) appConfig {
+				cfg.Servers.Monitoring.Address = ":30050"
+				cfg.Servers.Public.Address = ":30051"
+				cfg.Testing.Deterministic = true
+
+				return cfg
+			},
+			wantErr: false,
+		},
+		{
+			name: "success with breach corpus",
+			fcfg: func(cfg appConfig) appConfig {
+				cfg.Servers.Monitoring.Address = ":30054"
+				cfg.Servers.Public.Address = ":30055"
+				cfg.Breach = breachConfig{
+					Enabled:         true,
+					Format:          "ordered",
+					Hash:            "sha1",
+					Path:            "../../resources/test/breach/pwned-passwords-sha1-ordered-by-hash.txt",
+					MaxAge:          36500,
+					RejectPasswords: true,
+				}
+
+				return cfg
+			},
+			wantErr: false,
+		},
 		{
 			name: "success with all features enabled",
 			fcfg: func(cfg appConfig) appConfig {
@@ -146,3 +201,24 @@ func Test_bind(t *testing.T) {
 		})
 	}
 }
+
+func Test_newBreachHealthCheck(t *testing.T) {
+	t.Parallel()
+
+	hc := newBreachHealthCheck(nil, 30)
+	require.Equal(t, "breach_corpus:not_loaded", hc.ID)
+	require.NoError(t, hc.Checker.HealthCheck(t.Context()))
+
+	corpus, err := breach.Load(breach.FormatOrdered, breach.HashSHA1, "../../resources/test/breach/pwned-passwords-sha1-ordered-by-hash.txt")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { require.NoError(t, corpus.Close()) })
+
+	hc = newBreachHealthCheck(corpus, 0)
+	require.Equal(t, "breach_corpus:ordered/sha1@"+corpus.Info().UpdatedAt.UTC().Format(time.DateOnly), hc.ID)
+	require.NoError(t, hc.Checker.HealthCheck(t.Context()))
+
+	// a one-day maximum age only holds for a corpus updated within the last day
+	hc = newBreachHealthCheck(corpus, 1)
+	require.Equal(t, corpus.Age() > 24*time.Hour, hc.Checker.HealthCheck(t.Context()) != nil)
+}
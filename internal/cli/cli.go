@@ -2,10 +2,12 @@
 package cli
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"strconv"
 	"sync"
 	"time"
 
@@ -15,9 +17,13 @@ import (
 	"github.com/tecnickcom/nurago/pkg/config"
 	"github.com/tecnickcom/nurago/pkg/httputil/jsendx"
 	"github.com/tecnickcom/nurago/pkg/logutil"
+	"github.com/tecnickcom/rndpwd/internal/breach"
 	"github.com/tecnickcom/rndpwd/internal/metrics"
 )
 
+// defaultFilterFPRate is the default false positive rate of the breach filters.
+const defaultFilterFPRate = 0.001
+
 type bootstrapFunc func(bindFn bootstrap.BindFunc, opts ...bootstrap.Option) error
 
 // New creates a new CLI instance.
@@ -89,7 +95,7 @@ func New(version, release string, bootstrapFn bootstrapFunc) (*cobra.Command, er
 		},
 	}
 
-	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(versionCmd, newBreachFilterCmd())
 
 	// Parse the flags early so invalid command-line arguments are reported by
 	// New (exit code 1) instead of at execution time. pflag returns ErrHelp
@@ -143,3 +149,67 @@ func newLogConfig(cfg *appConfig, version, release, argLogFormat, argLogLevel st
 
 	return logcfg, nil
 }
+
+// newBreachFilterCmd returns the sub-command building the bloom filter of a
+// breach corpus, to be loaded with the filter format. The arguments are
+// positional, as the root flags are parsed before the sub-command is known.
+func newBreachFilterCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "breach-filter FORMAT HASH CORPUS OUTPUT [FALSE_POSITIVE_RATE]",
+		Short: "Build the bloom filter of a breach corpus",
+		Long: "Build the bloom filter of the HIBP corpus of the given format (range or ordered) and hash type " +
+			"(sha1 or ntlm) at the CORPUS path, and write it to the OUTPUT file. " +
+			"The default false positive rate is " + strconv.FormatFloat(defaultFilterFPRate, 'g', -1, 64) + ".",
+		Args: cobra.RangeArgs(4, 5), //nolint:mnd
+		RunE: func(_ *cobra.Command, args []string) error {
+			fpRate := defaultFilterFPRate
+
+			if len(args) > 4 { //nolint:mnd
+				v, err := strconv.ParseFloat(args[4], 64)
+				if err != nil {
+					return fmt.Errorf("invalid false positive rate: %w", err)
+				}
+
+				fpRate = v
+			}
+
+			return buildBreachFilter(args[0], args[1], args[2], args[3], fpRate)
+		},
+	}
+}
+
+// buildBreachFilter writes the bloom filter of the corpus to the output file.
+// The hashes seen only once are kept, while the padding lines are left out.
+// The filter takes the date of the corpus, so its age stays the age of the
+// breach data.
+func buildBreachFilter(format, hash, corpusPath, outputPath string, fpRate float64) error {
+	corpus, err := breach.Load(format, hash, corpusPath)
+	if err != nil {
+		return fmt.Errorf("failed loading the breach corpus: %w", err)
+	}
+
+	defer func() { _ = corpus.Close() }()
+
+	f, err := os.Create(outputPath) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed creating the filter file: %w", err)
+	}
+
+	w := bufio.NewWriter(f)
+
+	err = breach.BuildFilter(w, corpus, fpRate, 1, corpus.Info().UpdatedAt)
+	if err == nil {
+		err = w.Flush()
+	}
+
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		_ = os.Remove(outputPath)
+		return fmt.Errorf("failed building the breach filter: %w", err)
+	}
+
+	return nil
+}
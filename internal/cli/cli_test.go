@@ -2,18 +2,24 @@ package cli
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/tecnickcom/nurago/pkg/bootstrap"
 	"github.com/tecnickcom/nurago/pkg/testutil"
+	"github.com/tecnickcom/rndpwd/internal/breach"
 )
 
 //nolint:gocognit,paralleltest,tparallel
 func TestNew(t *testing.T) {
 	t.Parallel()
 
+	corpus := "../../resources/test/breach/pwned-passwords-sha1-ordered-by-hash.txt"
+	filter := filepath.Join(t.TempDir(), "hibp.filter")
+
 	tests := []struct {
 		name          string
 		osArgs        []string
@@ -27,6 +33,36 @@ func TestNew(t *testing.T) {
 			wantErr:    false,
 			wantOutput: matchTestVersion,
 		},
+		{
+			name:    "call breach-filter subcommand",
+			osArgs:  []string{AppName, "breach-filter", "ordered", "sha1", corpus, filter, "0.01"},
+			wantErr: false,
+		},
+		{
+			name:    "fails breach-filter subcommand with missing arguments",
+			osArgs:  []string{AppName, "breach-filter", "ordered", "sha1", corpus},
+			wantErr: true,
+		},
+		{
+			name:    "fails breach-filter subcommand with invalid false positive rate",
+			osArgs:  []string{AppName, "breach-filter", "ordered", "sha1", corpus, filter, "often"},
+			wantErr: true,
+		},
+		{
+			name:    "fails breach-filter subcommand with out of range false positive rate",
+			osArgs:  []string{AppName, "breach-filter", "ordered", "sha1", corpus, filter + ".bad", "2"},
+			wantErr: true,
+		},
+		{
+			name:    "fails breach-filter subcommand with missing corpus",
+			osArgs:  []string{AppName, "breach-filter", "range", "sha1", "/nonexistent/hibp", filter},
+			wantErr: true,
+		},
+		{
+			name:    "fails breach-filter subcommand with invalid output path",
+			osArgs:  []string{AppName, "breach-filter", "ordered", "sha1", corpus, "/nonexistent/hibp.filter"},
+			wantErr: true,
+		},
 		{
 			name:       "prints help with --help flag",
 			osArgs:     []string{AppName, "--help"},
@@ -136,6 +172,31 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestBuildBreachFilterDate(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile("../../resources/test/breach/pwned-passwords-sha1-ordered-by-hash.txt")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	corpus := filepath.Join(dir, "hibp.txt")
+	filter := filepath.Join(dir, "hibp.filter")
+
+	require.NoError(t, os.WriteFile(corpus, data, 0o600))
+
+	old := time.Date(2020, time.March, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(corpus, old, old))
+
+	require.NoError(t, buildBreachFilter(breach.FormatOrdered, breach.HashSHA1, corpus, filter, 0.01))
+
+	c, err := breach.Load(breach.FormatFilter, breach.HashSHA1, filter)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { require.NoError(t, c.Close()) })
+
+	require.True(t, old.Equal(c.Info().UpdatedAt), c.Info().UpdatedAt)
+}
+
 func matchErrorOutput(t *testing.T, out string) {
 	t.Helper()
 
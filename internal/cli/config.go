@@ -1,7 +1,17 @@
 package cli
 
 import (
+	"fmt"
+
 	"github.com/tecnickcom/nurago/pkg/config"
+	"github.com/tecnickcom/rndpwd/internal/breach"
+	"github.com/tecnickcom/rndpwd/internal/charset"
+	"github.com/tecnickcom/rndpwd/internal/httphandler"
+	"github.com/tecnickcom/rndpwd/internal/password"
+	"github.com/tecnickcom/rndpwd/internal/pattern"
+	"github.com/tecnickcom/rndpwd/internal/pwhash"
+	"github.com/tecnickcom/rndpwd/internal/sshkey"
+	"github.com/tecnickcom/rndpwd/internal/uid"
 	"github.com/tecnickcom/rndpwd/internal/validator"
 )
 
@@ -51,21 +61,338 @@ type cfgClients struct {
 	Ipify cfgClientIpify `mapstructure:"ipify" validate:"required"`
 }
 
+// cfgRandomClass is a custom character class of the password policy.
+type cfgRandomClass struct {
+	Name  string      `mapstructure:"name"  validate:"required,max=32"`
+	Chars charset.Set `mapstructure:"chars" validate:"required,min=1,max=256,rndcharset"`
+	Min   int         `mapstructure:"min"   validate:"min=0,max=4096"`
+	Max   int         `mapstructure:"max"   validate:"omitempty,max=4096,gtefield=Min"`
+}
+
+// cfgRandomPolicy contains the default per-class bounds of the passwords.
+type cfgRandomPolicy struct {
+	MinUpper  int              `mapstructure:"min_upper"  validate:"min=0,max=4096"`
+	MaxUpper  int              `mapstructure:"max_upper"  validate:"omitempty,max=4096,gtefield=MinUpper"`
+	MinLower  int              `mapstructure:"min_lower"  validate:"min=0,max=4096"`
+	MaxLower  int              `mapstructure:"max_lower"  validate:"omitempty,max=4096,gtefield=MinLower"`
+	MinDigit  int              `mapstructure:"min_digit"  validate:"min=0,max=4096"`
+	MaxDigit  int              `mapstructure:"max_digit"  validate:"omitempty,max=4096,gtefield=MinDigit"`
+	MinSymbol int              `mapstructure:"min_symbol" validate:"min=0,max=4096"`
+	MaxSymbol int              `mapstructure:"max_symbol" validate:"omitempty,max=4096,gtefield=MinSymbol"`
+	Classes   []cfgRandomClass `mapstructure:"classes"    validate:"max=16,dive"`
+	problem   string           // reason of the last failed randomConfig.CheckPolicy
+}
+
+// Problem returns the reason why the last randomConfig.CheckPolicy call
+// failed, or an empty string. It is used by the validation error messages.
+func (c cfgRandomPolicy) Problem() string {
+	return c.problem
+}
+
+// cfgRandomConstraints contains the default repetition and sequence
+// constraints of the passwords.
+type cfgRandomConstraints struct {
+	MaxConsecutive int  `mapstructure:"max_consecutive" validate:"min=0,max=4096"`
+	MaxPerChar     int  `mapstructure:"max_per_char"    validate:"min=0,max=4096"`
+	MinDistinct    int  `mapstructure:"min_distinct"    validate:"min=0,max=4096"`
+	NoSequences    bool `mapstructure:"no_sequences"`
+}
+
+// cfgRandomSource selects the entropy source shared by all the generators.
+type cfgRandomSource struct {
+	Type           string `mapstructure:"type"            validate:"required,oneof=os chacha20 hmac_drbg file"`
+	Path           string `mapstructure:"path"            validate:"required_if=Type file,max=4096"`
+	ReseedInterval int    `mapstructure:"reseed_interval" validate:"required,min=1,max=1000000000"`
+}
+
+// newSource returns the entropy source defined by the configuration.
+func (c *cfgRandomSource) newSource() (password.Source, error) {
+	return password.NewSource(c.Type, c.Path, c.ReseedInterval) //nolint:wrapcheck
+}
+
 // randomConfig contains the random generator configuration.
 type randomConfig struct {
-	Charset  string `mapstructure:"charset"  validate:"required,min=1,max=256,rndcharset"`
-	Length   int    `mapstructure:"length"   validate:"required,min=1,max=4096"`
+	Charset           charset.Set          `mapstructure:"charset"             validate:"required,min=1,max=256,rndcharset,rndeffcharset"`
+	Length            int                  `mapstructure:"length"              validate:"required,min=1,max=4096"`
+	Quantity          int                  `mapstructure:"quantity"            validate:"required,min=1,max=100"`
+	StreamMaxQuantity int                  `mapstructure:"stream_max_quantity" validate:"required,min=1,max=100000000"`
+	Mode              string               `mapstructure:"mode"                validate:"required,oneof=random pronounceable template"`
+	Template          pattern.Template     `mapstructure:"template"            validate:"required_if=Mode template,max=1024,rndtemplate"`
+	ExcludeAmbiguous  bool                 `mapstructure:"exclude_ambiguous"`
+	Policy            cfgRandomPolicy      `mapstructure:"policy"              validate:"rndpolicy"`
+	Constraints       cfgRandomConstraints `mapstructure:"constraints"`
+	Unique            bool                 `mapstructure:"unique"`
+	Source            cfgRandomSource      `mapstructure:"source"              validate:"required"`
+}
+
+// newPassword returns the password generator defined by the configuration.
+func (c *randomConfig) newPassword() *password.Password {
+	pol := password.Policy{
+		MinUpper:  c.Policy.MinUpper,
+		MaxUpper:  c.Policy.MaxUpper,
+		MinLower:  c.Policy.MinLower,
+		MaxLower:  c.Policy.MaxLower,
+		MinDigit:  c.Policy.MinDigit,
+		MaxDigit:  c.Policy.MaxDigit,
+		MinSymbol: c.Policy.MinSymbol,
+		MaxSymbol: c.Policy.MaxSymbol,
+	}
+
+	for _, cl := range c.Policy.Classes {
+		pol.Classes = append(pol.Classes, password.Class(cl))
+	}
+
+	return password.New(
+		string(c.Charset),
+		c.Length,
+		c.Quantity,
+		password.WithMode(c.Mode),
+		password.WithTemplate(string(c.Template)),
+		password.WithExcludeAmbiguous(c.ExcludeAmbiguous),
+		password.WithPolicy(pol),
+		password.WithConstraints(password.Constraints(c.Constraints)),
+		password.WithUnique(c.Unique),
+	)
+}
+
+// CheckCharset implements the validator.CharsetChecker interface.
+func (c *randomConfig) CheckCharset() error {
+	return c.newPassword().CheckCharset() //nolint:wrapcheck
+}
+
+// CheckPolicy implements the validator.PolicyChecker interface. The default
+// quantity must also fit the keyspace when the uniqueness is required.
+func (c *randomConfig) CheckPolicy() error {
+	p := c.newPassword()
+
+	err := p.CheckPolicy()
+	if err == nil {
+		err = p.CheckUnique()
+	}
+
+	c.Policy.problem = ""
+	if err != nil {
+		c.Policy.problem = err.Error()
+	}
+
+	return err //nolint:wrapcheck
+}
+
+// bytesConfig contains the default random bytes configuration.
+type bytesConfig struct {
+	Size     int    `mapstructure:"size"     validate:"required,min=1,ltefield=MaxSize"`
+	MaxSize  int    `mapstructure:"max_size" validate:"required,min=1,max=65536"`
+	Quantity int    `mapstructure:"quantity" validate:"required,min=1,max=100"`
+	Encoding string `mapstructure:"encoding" validate:"required,oneof=hex base64 base64url base32 base58 base85 z85 go c"`
+}
+
+// newBytes returns the random bytes generator defined by the configuration.
+func (c *bytesConfig) newBytes() *password.Bytes {
+	return password.NewBytes(c.Size, c.Quantity, c.Encoding)
+}
+
+// sshKeyConfig contains the default SSH key pair configuration and the limits
+// of the CPU-heavy key generation.
+type sshKeyConfig struct {
+	Type          string `mapstructure:"type"           validate:"required,oneof=ed25519 ecdsa-p256 ecdsa-p384 rsa"`
+	RSABits       int    `mapstructure:"rsa_bits"       validate:"required,oneof=3072 4096,ltefield=MaxRSABits"`
+	MaxRSABits    int    `mapstructure:"max_rsa_bits"   validate:"required,oneof=3072 4096"`
+	MaxConcurrent int    `mapstructure:"max_concurrent" validate:"required,min=1,max=1024"`
+	Comment       string `mapstructure:"comment"        validate:"max=256,printascii"`
+}
+
+// newSSHKey returns the SSH key pair generator defined by the configuration.
+func (c *sshKeyConfig) newSSHKey() *sshkey.Generator {
+	return sshkey.New(c.Type, c.RSABits, c.Comment)
+}
+
+// passphraseConfig contains the default passphrase generator configuration.
+type passphraseConfig struct {
+	Wordlist   string `mapstructure:"wordlist"   validate:"required,oneof=eff_large eff_short"`
+	Words      int    `mapstructure:"words"      validate:"required,min=1,max=32"`
+	Separator  string `mapstructure:"separator"  validate:"required,max=3,printascii,excludesall=-ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"`
+	Capitalize string `mapstructure:"capitalize" validate:"required,oneof=none first upper random"`
+	Digit      bool   `mapstructure:"digit"`
+	Symbol     bool   `mapstructure:"symbol"`
+	Quantity   int    `mapstructure:"quantity"   validate:"required,min=1,max=100"`
+}
+
+// newPassphrase returns the passphrase generator defined by the configuration.
+func (c *passphraseConfig) newPassphrase() *password.Passphrase {
+	return password.NewPassphrase(
+		c.Wordlist,
+		c.Words,
+		c.Quantity,
+		password.WithSeparator(c.Separator),
+		password.WithCapitalize(c.Capitalize),
+		password.WithDigit(c.Digit),
+		password.WithSymbol(c.Symbol),
+	)
+}
+
+// pinConfig contains the default PIN generator configuration.
+type pinConfig struct {
+	Length   int `mapstructure:"length"   validate:"required,min=4,max=12"`
+	Quantity int `mapstructure:"quantity" validate:"required,min=1,max=100"`
+}
+
+// newPIN returns the PIN generator defined by the configuration.
+func (c *pinConfig) newPIN() *password.PIN {
+	return password.NewPIN(c.Length, c.Quantity)
+}
+
+// totpConfig contains the default one-time password secret configuration.
+type totpConfig struct {
+	Issuer    string `mapstructure:"issuer"    validate:"max=256,excludes=:"`
+	Algorithm string `mapstructure:"algorithm" validate:"required,oneof=SHA1 SHA256 SHA512"`
+	Digits    int    `mapstructure:"digits"    validate:"required,min=6,max=8"`
+	Period    int    `mapstructure:"period"    validate:"required,min=1,max=3600"`
+}
+
+// newOTP returns the one-time password secret generator defined by the
+// configuration. The account is set by each request.
+func (c *totpConfig) newOTP() *password.OTP {
+	return password.NewOTP(
+		"",
+		password.WithOTPIssuer(c.Issuer),
+		password.WithOTPAlgorithm(c.Algorithm),
+		password.WithOTPDigits(c.Digits),
+		password.WithOTPPeriod(c.Period),
+	)
+}
+
+// tokenConfig contains the default prefixed API token configuration.
+type tokenConfig struct {
+	Prefix   string `mapstructure:"prefix"   validate:"required,max=32"`
+	Bits     int    `mapstructure:"bits"     validate:"required,min=128,max=512"`
 	Quantity int    `mapstructure:"quantity" validate:"required,min=1,max=100"`
 }
 
+// newToken returns the API token generator defined by the configuration.
+func (c *tokenConfig) newToken() *password.Token {
+	return password.NewToken(c.Prefix, c.Bits, c.Quantity)
+}
+
+// check reports whether the prefix can be matched by the secret scanners.
+func (c *tokenConfig) check() error {
+	if !password.ValidTokenPrefix(c.Prefix) {
+		return fmt.Errorf("invalid token.prefix: %w", password.ErrInvalidTokenPrefix)
+	}
+
+	return nil
+}
+
+// recoveryConfig contains the default one-time recovery codes configuration.
+type recoveryConfig struct {
+	Groups    int    `mapstructure:"groups"     validate:"required,min=1,max=8"`
+	GroupSize int    `mapstructure:"group_size" validate:"required,min=4,max=8"`
+	Quantity  int    `mapstructure:"quantity"   validate:"required,min=1,max=100"`
+	Hash      string `mapstructure:"hash"       validate:"required,oneof=bcrypt argon2id scrypt pbkdf2-sha256 sha512-crypt"`
+}
+
+// newRecoveryCodes returns the recovery codes generator defined by the
+// configuration.
+func (c *recoveryConfig) newRecoveryCodes() *password.RecoveryCodes {
+	return password.NewRecoveryCodes(c.Groups, c.GroupSize, c.Quantity)
+}
+
+// check reports whether the default quantity of codes can be hashed by a
+// single request.
+func (c *recoveryConfig) check(maxQuantity int) error {
+	if c.Quantity > maxQuantity {
+		return fmt.Errorf("invalid recovery.quantity: at most %d codes can be hashed by a single request (hash.max_quantity)", maxQuantity)
+	}
+
+	return nil
+}
+
+// uidConfig contains the default unique identifier configuration.
+type uidConfig struct {
+	Format         string `mapstructure:"format"          validate:"required,oneof=uuidv4 uuidv7 ulid ksuid nanoid snowflake"`
+	MaxQuantity    int    `mapstructure:"max_quantity"    validate:"required,min=1,max=100000"`
+	SnowflakeNode  int64  `mapstructure:"snowflake_node"  validate:"min=0,max=1023"`
+	SnowflakeEpoch int64  `mapstructure:"snowflake_epoch" validate:"min=0"`
+}
+
+// breachConfig contains the settings of the local breached-password corpus.
+type breachConfig struct {
+	Enabled         bool   `mapstructure:"enabled"`
+	Format          string `mapstructure:"format"           validate:"required,oneof=range ordered filter"`
+	Hash            string `mapstructure:"hash"             validate:"required,oneof=sha1 ntlm"`
+	Path            string `mapstructure:"path"             validate:"required_if=Enabled true,max=4096"`
+	MaxAge          int    `mapstructure:"max_age"          validate:"min=0,max=36500"`
+	RejectPasswords bool   `mapstructure:"reject_passwords"`
+}
+
+// newCorpus loads the breach corpus defined by the configuration, or returns
+// nil when it is disabled.
+func (c *breachConfig) newCorpus() (*breach.Corpus, error) {
+	if !c.Enabled {
+		return nil, nil //nolint:nilnil
+	}
+
+	return breach.Load(c.Format, c.Hash, c.Path) //nolint:wrapcheck
+}
+
+// cfgHashParams contains the work factors of the password hashes.
+type cfgHashParams struct {
+	Cost       int `mapstructure:"cost"       validate:"min=4,max=31"`
+	Memory     int `mapstructure:"memory"     validate:"min=8,max=4194304"`
+	Time       int `mapstructure:"time"       validate:"min=1,max=1000"`
+	Threads    int `mapstructure:"threads"    validate:"min=1,max=255"`
+	LN         int `mapstructure:"ln"         validate:"min=1,max=30"`
+	R          int `mapstructure:"r"          validate:"min=1,max=64"`
+	P          int `mapstructure:"p"          validate:"min=1,max=64"`
+	Iterations int `mapstructure:"iterations" validate:"min=1,max=100000000"`
+	Rounds     int `mapstructure:"rounds"     validate:"min=1000,max=999999999"`
+}
+
+// hashConfig contains the settings of the password hashes returned by the
+// /password route and checked by the /verify route.
+type hashConfig struct {
+	MaxQuantity int           `mapstructure:"max_quantity" validate:"required,min=1,max=1000"`
+	Defaults    cfgHashParams `mapstructure:"defaults"     validate:"required"`
+	Max         cfgHashParams `mapstructure:"max"          validate:"required"`
+	Min         cfgHashParams `mapstructure:"min"          validate:"required"`
+}
+
+// check reports whether the default work factors of every algorithm are
+// within the bounds of the algorithm and the configured maximums.
+func (c *hashConfig) check() error {
+	for _, alg := range pwhash.Algorithms() {
+		err := pwhash.Params(c.Defaults).Check(alg, pwhash.Params(c.Max))
+		if err != nil {
+			return fmt.Errorf("invalid hash.defaults: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// testingConfig contains the settings reserved to the integration tests.
+type testingConfig struct {
+	Deterministic bool `mapstructure:"deterministic"`
+}
+
 // appConfig contains the full application configuration.
 type appConfig struct {
 	config.BaseConfig `mapstructure:",squash" validate:"required"`
 
-	Enabled bool         `mapstructure:"enabled"`
-	Servers cfgServers   `mapstructure:"servers" validate:"required"`
-	Clients cfgClients   `mapstructure:"clients" validate:"required"`
-	Random  randomConfig `mapstructure:"random"  validate:"required"`
+	Enabled    bool             `mapstructure:"enabled"`
+	Servers    cfgServers       `mapstructure:"servers"    validate:"required"`
+	Clients    cfgClients       `mapstructure:"clients"    validate:"required"`
+	Random     randomConfig     `mapstructure:"random"     validate:"required"`
+	Bytes      bytesConfig      `mapstructure:"bytes"      validate:"required"`
+	SSHKey     sshKeyConfig     `mapstructure:"sshkey"     validate:"required"`
+	Passphrase passphraseConfig `mapstructure:"passphrase" validate:"required"`
+	PIN        pinConfig        `mapstructure:"pin"        validate:"required"`
+	TOTP       totpConfig       `mapstructure:"totp"       validate:"required"`
+	Token      tokenConfig      `mapstructure:"token"      validate:"required"`
+	Recovery   recoveryConfig   `mapstructure:"recovery"   validate:"required"`
+	UID        uidConfig        `mapstructure:"uid"        validate:"required"`
+	Breach     breachConfig     `mapstructure:"breach"     validate:"required"`
+	Hash       hashConfig       `mapstructure:"hash"       validate:"required"`
+	Testing    testingConfig    `mapstructure:"testing"`
 }
 
 // SetDefaults sets the default configuration values in Viper.
@@ -84,6 +411,113 @@ func (c *appConfig) SetDefaults(v config.Viper) {
 	v.SetDefault("random.charset", validator.ValidCharset)
 	v.SetDefault("random.length", 32)
 	v.SetDefault("random.quantity", 10)
+	v.SetDefault("random.stream_max_quantity", httphandler.DefaultStreamMaxQuantity)
+	v.SetDefault("random.mode", password.ModeRandom)
+	v.SetDefault("random.template", "")
+	v.SetDefault("random.exclude_ambiguous", false)
+
+	v.SetDefault("random.policy.min_upper", 0)
+	v.SetDefault("random.policy.max_upper", 0)
+	v.SetDefault("random.policy.min_lower", 0)
+	v.SetDefault("random.policy.max_lower", 0)
+	v.SetDefault("random.policy.min_digit", 0)
+	v.SetDefault("random.policy.max_digit", 0)
+	v.SetDefault("random.policy.min_symbol", 0)
+	v.SetDefault("random.policy.max_symbol", 0)
+
+	v.SetDefault("random.constraints.max_consecutive", 0)
+	v.SetDefault("random.constraints.max_per_char", 0)
+	v.SetDefault("random.constraints.min_distinct", 0)
+	v.SetDefault("random.constraints.no_sequences", false)
+
+	v.SetDefault("random.unique", false)
+
+	v.SetDefault("random.source.type", password.SourceOS)
+	v.SetDefault("random.source.path", "")
+	v.SetDefault("random.source.reseed_interval", password.DefaultReseedInterval)
+
+	v.SetDefault("bytes.size", 32)
+	v.SetDefault("bytes.max_size", httphandler.DefaultBytesMaxSize)
+	v.SetDefault("bytes.quantity", 1)
+	v.SetDefault("bytes.encoding", password.EncodingHex)
+
+	v.SetDefault("sshkey.type", sshkey.TypeEd25519)
+	v.SetDefault("sshkey.rsa_bits", sshkey.MinRSABits)
+	v.SetDefault("sshkey.max_rsa_bits", sshkey.MaxRSABits)
+	v.SetDefault("sshkey.max_concurrent", httphandler.DefaultSSHKeyMaxConcurrent)
+	v.SetDefault("sshkey.comment", "")
+
+	v.SetDefault("passphrase.wordlist", password.WordlistEFFLarge)
+	v.SetDefault("passphrase.words", 6)
+	v.SetDefault("passphrase.separator", " ")
+	v.SetDefault("passphrase.capitalize", password.CapitalizeNone)
+	v.SetDefault("passphrase.digit", false)
+	v.SetDefault("passphrase.symbol", false)
+	v.SetDefault("passphrase.quantity", 5)
+
+	v.SetDefault("pin.length", 6)
+	v.SetDefault("pin.quantity", 5)
+
+	v.SetDefault("totp.issuer", "")
+	v.SetDefault("totp.algorithm", password.OTPAlgorithmSHA1)
+	v.SetDefault("totp.digits", 6)
+	v.SetDefault("totp.period", 30)
+
+	v.SetDefault("token.prefix", "rnd_")
+	v.SetDefault("token.bits", 160)
+	v.SetDefault("token.quantity", 1)
+
+	v.SetDefault("recovery.groups", 3)
+	v.SetDefault("recovery.group_size", 4)
+	v.SetDefault("recovery.quantity", 10)
+	v.SetDefault("recovery.hash", pwhash.Argon2id)
+
+	v.SetDefault("uid.format", uid.FormatUUIDv7)
+	v.SetDefault("uid.max_quantity", httphandler.DefaultUIDMaxQuantity)
+	v.SetDefault("uid.snowflake_node", 0)
+	v.SetDefault("uid.snowflake_epoch", uid.DefaultSnowflakeEpoch)
+
+	v.SetDefault("breach.enabled", false)
+	v.SetDefault("breach.format", breach.FormatRange)
+	v.SetDefault("breach.hash", breach.HashSHA1)
+	v.SetDefault("breach.path", "")
+	v.SetDefault("breach.max_age", 0)
+	v.SetDefault("breach.reject_passwords", false)
+
+	hashDefaults := pwhash.DefaultParams()
+	hashMax := pwhash.DefaultLimits()
+	hashMin := pwhash.DefaultMinimums()
+
+	v.SetDefault("hash.max_quantity", httphandler.DefaultHashMaxQuantity)
+	v.SetDefault("hash.defaults.cost", hashDefaults.Cost)
+	v.SetDefault("hash.defaults.memory", hashDefaults.Memory)
+	v.SetDefault("hash.defaults.time", hashDefaults.Time)
+	v.SetDefault("hash.defaults.threads", hashDefaults.Threads)
+	v.SetDefault("hash.defaults.ln", hashDefaults.LN)
+	v.SetDefault("hash.defaults.r", hashDefaults.R)
+	v.SetDefault("hash.defaults.p", hashDefaults.P)
+	v.SetDefault("hash.defaults.iterations", hashDefaults.Iterations)
+	v.SetDefault("hash.defaults.rounds", hashDefaults.Rounds)
+	v.SetDefault("hash.max.cost", hashMax.Cost)
+	v.SetDefault("hash.max.memory", hashMax.Memory)
+	v.SetDefault("hash.max.time", hashMax.Time)
+	v.SetDefault("hash.max.threads", hashMax.Threads)
+	v.SetDefault("hash.max.ln", hashMax.LN)
+	v.SetDefault("hash.max.r", hashMax.R)
+	v.SetDefault("hash.max.p", hashMax.P)
+	v.SetDefault("hash.max.iterations", hashMax.Iterations)
+	v.SetDefault("hash.max.rounds", hashMax.Rounds)
+	v.SetDefault("hash.min.cost", hashMin.Cost)
+	v.SetDefault("hash.min.memory", hashMin.Memory)
+	v.SetDefault("hash.min.time", hashMin.Time)
+	v.SetDefault("hash.min.threads", hashMin.Threads)
+	v.SetDefault("hash.min.ln", hashMin.LN)
+	v.SetDefault("hash.min.r", hashMin.R)
+	v.SetDefault("hash.min.p", hashMin.P)
+	v.SetDefault("hash.min.iterations", hashMin.Iterations)
+	v.SetDefault("hash.min.rounds", hashMin.Rounds)
+
+	v.SetDefault("testing.deterministic", false)
 }
 
 // Validate performs the validation of the configuration values.
@@ -93,5 +527,20 @@ func (c *appConfig) Validate() error {
 		return err
 	}
 
-	return v.ValidateStruct(c) //nolint:wrapcheck
+	err = v.ValidateStruct(c)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	err = c.Token.check()
+	if err != nil {
+		return err
+	}
+
+	err = c.Recovery.check(c.Hash.MaxQuantity)
+	if err != nil {
+		return err
+	}
+
+	return c.Hash.check()
 }
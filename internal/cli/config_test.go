@@ -7,6 +7,7 @@ import (
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
 	"github.com/tecnickcom/nurago/pkg/config"
+	"github.com/tecnickcom/rndpwd/internal/charset"
 	"github.com/tecnickcom/rndpwd/internal/validator"
 )
 
@@ -18,7 +19,7 @@ func Test_appConfig_SetDefaults(t *testing.T) {
 	c.SetDefaults(v)
 
 	require.True(t, v.GetBool("enabled"))
-	require.Len(t, v.AllKeys(), 10)
+	require.Len(t, v.AllKeys(), 98)
 }
 
 func getValidTestConfig() appConfig {
@@ -50,10 +51,75 @@ func getValidTestConfig() appConfig {
 			},
 		},
 		Random: randomConfig{
-			Charset:  validator.ValidCharset,
-			Length:   16,
+			Charset:           validator.ValidCharset,
+			Length:            16,
+			Quantity:          3,
+			Mode:              "random",
+			StreamMaxQuantity: 1000,
+			Source: cfgRandomSource{
+				Type:           "chacha20",
+				ReseedInterval: 100,
+			},
+		},
+		Bytes: bytesConfig{
+			Size:     16,
+			MaxSize:  256,
+			Quantity: 1,
+			Encoding: "base64url",
+		},
+		SSHKey: sshKeyConfig{
+			Type:          "ecdsa-p256",
+			RSABits:       3072,
+			MaxRSABits:    4096,
+			MaxConcurrent: 2,
+			Comment:       "rndpwd",
+		},
+		Passphrase: passphraseConfig{
+			Wordlist:   "eff_short",
+			Words:      4,
+			Separator:  ".",
+			Capitalize: "first",
+			Digit:      true,
+			Symbol:     true,
+			Quantity:   2,
+		},
+		PIN: pinConfig{
+			Length:   4,
 			Quantity: 3,
 		},
+		TOTP: totpConfig{
+			Issuer:    "Example",
+			Algorithm: "SHA256",
+			Digits:    8,
+			Period:    60,
+		},
+		Token: tokenConfig{
+			Prefix:   "acme_live_",
+			Bits:     160,
+			Quantity: 1,
+		},
+		Recovery: recoveryConfig{
+			Groups:    3,
+			GroupSize: 4,
+			Quantity:  5,
+			Hash:      "bcrypt",
+		},
+		UID: uidConfig{
+			Format:         "ulid",
+			MaxQuantity:    100,
+			SnowflakeNode:  1,
+			SnowflakeEpoch: 1288834974657,
+		},
+		Breach: breachConfig{
+			Format: "range",
+			Hash:   "sha1",
+		},
+		Hash: hashConfig{
+			MaxQuantity: 5,
+			Defaults:    cfgHashParams{Cost: 10, Memory: 19456, Time: 2, Threads: 1, LN: 14, R: 8, P: 1, Iterations: 310000, Rounds: 5000},
+			Max:         cfgHashParams{Cost: 12, Memory: 65536, Time: 4, Threads: 2, LN: 16, R: 8, P: 2, Iterations: 1000000, Rounds: 1000000},
+			Min:         cfgHashParams{Cost: 10, Memory: 19456, Time: 2, Threads: 1, LN: 15, R: 8, P: 1, Iterations: 600000, Rounds: 5000},
+		},
 	}
 }
 
@@ -206,7 +272,7 @@ func Test_appConfig_Validate(t *testing.T) {
 		{
 			name: "too big random.charset",
 			fcfg: func(cfg appConfig) appConfig {
-				cfg.Random.Charset = string(make([]byte, 257))
+				cfg.Random.Charset = charset.Set(make([]byte, 257))
 				return cfg
 			},
 			wantErr: true,
@@ -229,6 +295,372 @@ func Test_appConfig_Validate(t *testing.T) {
 			fcfg:    func(cfg appConfig) appConfig { cfg.Random.Quantity = 0; return cfg },
 			wantErr: true,
 		},
+		{
+			name:    "invalid random.mode",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Random.Mode = "unknown"; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "valid pronounceable random.mode",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Random.Mode = "pronounceable"; return cfg },
+			wantErr: false,
+		},
+		{
+			name: "pronounceable random.mode with policy",
+			fcfg: func(cfg appConfig) appConfig {
+				cfg.Random.Mode = "pronounceable"
+				cfg.Random.Policy = cfgRandomPolicy{MinDigit: 1}
+
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "random.exclude_ambiguous with ambiguous charset",
+			fcfg: func(cfg appConfig) appConfig {
+				cfg.Random.Charset = "0O1lI"
+				cfg.Random.ExcludeAmbiguous = true
+
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name:    "invalid passphrase.wordlist",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Passphrase.Wordlist = "unknown"; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "too many passphrase.words",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Passphrase.Words = 33; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "empty passphrase.separator",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Passphrase.Separator = ""; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "ambiguous passphrase.separator",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Passphrase.Separator = "-"; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "invalid passphrase.capitalize",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Passphrase.Capitalize = "title"; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "empty passphrase.quantity",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Passphrase.Quantity = 0; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "empty random.stream_max_quantity",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Random.StreamMaxQuantity = 0; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "missing random.template",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Random.Mode = "template"; return cfg },
+			wantErr: true,
+		},
+		{
+			name: "valid random.template",
+			fcfg: func(cfg appConfig) appConfig {
+				cfg.Random.Mode = "template"
+				cfg.Random.Template = "Cvcc-9999-SS"
+
+				return cfg
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid random.template",
+			fcfg: func(cfg appConfig) appConfig {
+				cfg.Random.Mode = "template"
+				cfg.Random.Template = "Cvcc-9999-[SS"
+
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid random.constraints",
+			fcfg: func(cfg appConfig) appConfig {
+				cfg.Random.Constraints = cfgRandomConstraints{MaxConsecutive: 2, MaxPerChar: 3, MinDistinct: 8, NoSequences: true}
+				return cfg
+			},
+			wantErr: false,
+		},
+		{
+			name:    "negative random.constraints.max_consecutive",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Random.Constraints.MaxConsecutive = -1; return cfg },
+			wantErr: true,
+		},
+		{
+			name: "unsatisfiable random.constraints",
+			fcfg: func(cfg appConfig) appConfig {
+				cfg.Random.Charset = "abc"
+				cfg.Random.Constraints.MinDistinct = 4
+
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid random.unique",
+			fcfg: func(cfg appConfig) appConfig {
+				cfg.Random.Unique = true
+				return cfg
+			},
+			wantErr: false,
+		},
+		{
+			name: "random.unique with a small keyspace",
+			fcfg: func(cfg appConfig) appConfig {
+				cfg.Random.Charset = "01"
+				cfg.Random.Length = 3
+				cfg.Random.Quantity = 5
+				cfg.Random.Unique = true
+
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name:    "invalid random.source.type",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Random.Source.Type = "rdrand"; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "missing random.source.path",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Random.Source.Type = "file"; return cfg },
+			wantErr: true,
+		},
+		{
+			name: "valid random.source.path",
+			fcfg: func(cfg appConfig) appConfig {
+				cfg.Random.Source.Type = "file"
+				cfg.Random.Source.Path = "/dev/urandom"
+
+				return cfg
+			},
+			wantErr: false,
+		},
+		{
+			name:    "empty random.source.reseed_interval",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Random.Source.ReseedInterval = 0; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "bytes.size above bytes.max_size",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Bytes.Size = 512; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "too large bytes.max_size",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Bytes.MaxSize = 65537; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "invalid sshkey.type",
+			fcfg:    func(cfg appConfig) appConfig { cfg.SSHKey.Type = "dsa"; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "sshkey.rsa_bits above sshkey.max_rsa_bits",
+			fcfg:    func(cfg appConfig) appConfig { cfg.SSHKey.RSABits = 4096; cfg.SSHKey.MaxRSABits = 3072; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "unsupported sshkey.max_rsa_bits",
+			fcfg:    func(cfg appConfig) appConfig { cfg.SSHKey.MaxRSABits = 8192; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "zero sshkey.max_concurrent",
+			fcfg:    func(cfg appConfig) appConfig { cfg.SSHKey.MaxConcurrent = 0; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "invalid uid.format",
+			fcfg:    func(cfg appConfig) appConfig { cfg.UID.Format = "uuidv5"; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "zero uid.max_quantity",
+			fcfg:    func(cfg appConfig) appConfig { cfg.UID.MaxQuantity = 0; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "too large uid.snowflake_node",
+			fcfg:    func(cfg appConfig) appConfig { cfg.UID.SnowflakeNode = 1024; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "invalid bytes.encoding",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Bytes.Encoding = "base36"; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "too short pin.length",
+			fcfg:    func(cfg appConfig) appConfig { cfg.PIN.Length = 3; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "too long pin.length",
+			fcfg:    func(cfg appConfig) appConfig { cfg.PIN.Length = 13; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "empty pin.quantity",
+			fcfg:    func(cfg appConfig) appConfig { cfg.PIN.Quantity = 0; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "colon in totp.issuer",
+			fcfg:    func(cfg appConfig) appConfig { cfg.TOTP.Issuer = "a:b"; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "invalid totp.algorithm",
+			fcfg:    func(cfg appConfig) appConfig { cfg.TOTP.Algorithm = "MD5"; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "too many totp.digits",
+			fcfg:    func(cfg appConfig) appConfig { cfg.TOTP.Digits = 9; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "empty totp.period",
+			fcfg:    func(cfg appConfig) appConfig { cfg.TOTP.Period = 0; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "empty token.prefix",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Token.Prefix = ""; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "invalid token.prefix",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Token.Prefix = "Acme-"; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "too few token.bits",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Token.Bits = 64; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "too many recovery.groups",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Recovery.Groups = 9; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "invalid recovery.hash",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Recovery.Hash = "md5"; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "recovery.quantity above hash.max_quantity",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Recovery.Quantity = 6; return cfg },
+			wantErr: true,
+		},
+		{
+			name: "valid random.policy",
+			fcfg: func(cfg appConfig) appConfig {
+				cfg.Random.Policy = cfgRandomPolicy{
+					MinUpper:  1,
+					MinLower:  1,
+					MinDigit:  1,
+					MinSymbol: 1,
+					MaxSymbol: 2,
+					Classes:   []cfgRandomClass{{Name: "vowel", Chars: "aeiou", Min: 1}},
+				}
+
+				return cfg
+			},
+			wantErr: false,
+		},
+		{
+			name: "unsatisfiable random.policy",
+			fcfg: func(cfg appConfig) appConfig {
+				cfg.Random.Policy = cfgRandomPolicy{MinUpper: 9, MinLower: 9}
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid random.policy bounds",
+			fcfg: func(cfg appConfig) appConfig {
+				cfg.Random.Policy = cfgRandomPolicy{MinDigit: 3, MaxDigit: 2}
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid random.policy.classes",
+			fcfg: func(cfg appConfig) appConfig {
+				cfg.Random.Policy.Classes = []cfgRandomClass{{Name: "", Chars: "in va lid"}}
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid breach",
+			fcfg: func(cfg appConfig) appConfig {
+				cfg.Breach = breachConfig{Enabled: true, Format: "filter", Hash: "ntlm", Path: "/var/lib/rndpwd/hibp.filter", MaxAge: 90}
+				return cfg
+			},
+			wantErr: false,
+		},
+		{
+			name:    "invalid breach.format",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Breach.Format = "csv"; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "invalid breach.hash",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Breach.Hash = "md5"; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "missing breach.path",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Breach.Enabled = true; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "negative breach.max_age",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Breach.MaxAge = -1; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "empty hash.max_quantity",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Hash.MaxQuantity = 0; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "invalid hash.max.cost",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Hash.Max.Cost = 32; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "hash.defaults.memory above hash.max.memory",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Hash.Defaults.Memory = cfg.Hash.Max.Memory + 1; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "hash.defaults.rounds above hash.max.rounds",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Hash.Max.Rounds = 1000; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "invalid hash.min.rounds",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Hash.Min.Rounds = 999; return cfg },
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,98 @@
+package httphandler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/tecnickcom/rndpwd/internal/breach"
+	"github.com/tecnickcom/rndpwd/internal/password"
+)
+
+// maxBreachedBodySize is the maximum size in bytes of the /breached request
+// body.
+const maxBreachedBodySize = 4 << 10
+
+// breachCorpus looks up passwords in a breached-password corpus.
+type breachCorpus interface {
+	Check(pwd string) (breach.Result, error)
+	CheckHash(hexHash string) (breach.Result, error)
+	Contains(pwd string) (bool, error)
+	Info() breach.Info
+}
+
+// breachedRequest is the body of the /breached request, containing either the
+// password or its hash.
+type breachedRequest struct {
+	// Password is the password to look up.
+	Password string `json:"password" validate:"required_without=Hash,excluded_with=Hash,max=1024"`
+
+	// Hash is the hexadecimal hash of the password, of the corpus hash type.
+	Hash string `json:"hash" validate:"omitempty,hexadecimal,max=64"`
+}
+
+// breachedResponse is the body of the /breached response.
+type breachedResponse struct {
+	breach.Result
+
+	// Corpus describes the corpus the password was looked up in.
+	Corpus breach.Info `json:"corpus"`
+}
+
+// WithBreachCorpus sets the breached-password corpus of the /breached route.
+// When reject is set, the /password route also discards and regenerates the
+// passwords found in the corpus.
+func WithBreachCorpus(c *breach.Corpus, reject bool) Option {
+	return func(h *HTTPHandler) {
+		if c != nil {
+			h.breach = c
+			h.rejectBreached = reject
+		}
+	}
+}
+
+// passwordBlocklist returns the blocklist of the /password route, if any.
+func (h *HTTPHandler) passwordBlocklist() password.Blocklist {
+	if h.breach == nil || !h.rejectBreached {
+		return nil
+	}
+
+	return h.breach
+}
+
+func (h *HTTPHandler) handleBreached(w http.ResponseWriter, r *http.Request) {
+	if h.breach == nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusServiceUnavailable, "breach corpus not loaded")
+		return
+	}
+
+	var req breachedRequest
+
+	err := decodeJSONBody(w, r, maxBreachedBodySize, &req)
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	err = h.val.ValidateStruct(req)
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var res breach.Result
+
+	if req.Hash != "" {
+		res, err = h.breach.CheckHash(req.Hash)
+	} else {
+		res, err = h.breach.Check(req.Password)
+	}
+
+	switch {
+	case errors.Is(err, breach.ErrInvalidHash):
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+	case err != nil:
+		h.httpres.SendJSON(r.Context(), w, http.StatusInternalServerError, "failed checking the breach corpus")
+	default:
+		h.httpres.SendJSON(r.Context(), w, http.StatusOK, breachedResponse{Result: res, Corpus: h.breach.Info()})
+	}
+}
@@ -0,0 +1,252 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/rndpwd/internal/breach"
+	"github.com/tecnickcom/rndpwd/internal/password"
+	"github.com/tecnickcom/rndpwd/internal/validator"
+)
+
+// testCorpus is a breach corpus stub containing the listed passwords and
+// hashes.
+type testCorpus struct {
+	breached map[string]int
+	err      error
+}
+
+func (c testCorpus) Check(pwd string) (breach.Result, error) {
+	return breach.Result{Breached: c.breached[pwd] > 0, Count: c.breached[pwd]}, c.err
+}
+
+func (c testCorpus) CheckHash(hexHash string) (breach.Result, error) {
+	if len(hexHash) != 40 {
+		return breach.Result{}, breach.ErrInvalidHash
+	}
+
+	return c.Check(strings.ToUpper(hexHash))
+}
+
+func (c testCorpus) Contains(pwd string) (bool, error) {
+	res, err := c.Check(pwd)
+
+	return res.Breached, err
+}
+
+func (c testCorpus) Info() breach.Info {
+	return breach.Info{Format: breach.FormatRange, Hash: breach.HashSHA1}
+}
+
+func TestHTTPHandler_handleBreached(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	sha1Hash := "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8"
+
+	h := New(nil, nil, nil, val, password.New("0123456789abcdefghijklmnopqrstuvwxyz", 16, 3))
+	h.breach = testCorpus{breached: map[string]int{"password": 42, sha1Hash: 42}}
+
+	tests := []struct {
+		name      string
+		body      string
+		wantCode  int
+		wantCount int
+	}{
+		{
+			name:      "breached password",
+			body:      `{"password":"password"}`,
+			wantCode:  http.StatusOK,
+			wantCount: 42,
+		},
+		{
+			name:     "clean password",
+			body:     `{"password":"correct horse battery staple"}`,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:      "breached hash",
+			body:      `{"hash":"` + strings.ToLower(sha1Hash) + `"}`,
+			wantCode:  http.StatusOK,
+			wantCount: 42,
+		},
+		{
+			name:     "invalid hash size",
+			body:     `{"hash":"5baa61e4"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "not hexadecimal hash",
+			body:     `{"hash":"not a hash"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "password and hash",
+			body:     `{"password":"password","hash":"` + sha1Hash + `"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "missing password and hash",
+			body:     `{}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown field",
+			body:     `{"password":"password","count":1}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid JSON",
+			body:     `password`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, "/breached", strings.NewReader(tt.body))
+
+			h.handleBreached(rr, req)
+
+			resp := rr.Result()
+			require.NotNil(t, resp)
+
+			defer func() {
+				err := resp.Body.Close()
+				require.NoError(t, err, "error closing resp.Body")
+			}()
+
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			body, _ := io.ReadAll(resp.Body)
+
+			var data breachedResponse
+
+			require.NoError(t, json.Unmarshal(body, &data))
+			require.Equal(t, tt.wantCount > 0, data.Breached)
+			require.Equal(t, tt.wantCount, data.Count)
+			require.Equal(t, breach.HashSHA1, data.Corpus.Hash)
+		})
+	}
+}
+
+func TestHTTPHandler_handleBreached_errors(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	tests := []struct {
+		name     string
+		corpus   breachCorpus
+		wantCode int
+	}{
+		{
+			name:     "no corpus",
+			wantCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:     "corpus failure",
+			corpus:   testCorpus{err: errors.New("corpus failure")},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := New(nil, nil, nil, val, password.New("0123456789abcdefghijklmnopqrstuvwxyz", 16, 3))
+			h.breach = tt.corpus
+
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, "/breached", strings.NewReader(`{"password":"password"}`))
+
+			h.handleBreached(rr, req)
+
+			resp := rr.Result()
+			require.NotNil(t, resp)
+
+			defer func() {
+				err := resp.Body.Close()
+				require.NoError(t, err, "error closing resp.Body")
+			}()
+
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestHTTPHandler_handlePassword_rejectBreached(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	h := New(nil, nil, nil, val, password.New("ab", 1, 20))
+	h.breach = testCorpus{breached: map[string]int{"a": 1}}
+	h.rejectBreached = true
+
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+
+	h.handlePassword(rr, req)
+
+	resp := rr.Result()
+	require.NotNil(t, resp)
+
+	defer func() {
+		err := resp.Body.Close()
+		require.NoError(t, err, "error closing resp.Body")
+	}()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, _ := io.ReadAll(resp.Body)
+
+	var data []string
+
+	require.NoError(t, json.Unmarshal(body, &data))
+	require.Len(t, data, 20)
+
+	for _, pwd := range data {
+		require.Equal(t, "b", pwd)
+	}
+}
+
+func TestWithBreachCorpus(t *testing.T) {
+	t.Parallel()
+
+	h := New(nil, nil, nil, nil, nil, WithBreachCorpus(nil, true))
+	require.Nil(t, h.breach)
+	require.Nil(t, h.passwordBlocklist())
+
+	path := filepath.Join(t.TempDir(), "corpus.txt")
+	require.NoError(t, os.WriteFile(path, []byte("5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:42\n"), 0o600))
+
+	c, err := breach.Load(breach.FormatOrdered, breach.HashSHA1, path)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { require.NoError(t, c.Close()) })
+
+	h = New(nil, nil, nil, nil, nil, WithBreachCorpus(c, false))
+	require.NotNil(t, h.breach)
+	require.Nil(t, h.passwordBlocklist())
+
+	h = New(nil, nil, nil, nil, nil, WithBreachCorpus(c, true))
+	require.Equal(t, c, h.passwordBlocklist())
+}
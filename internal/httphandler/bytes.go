@@ -0,0 +1,86 @@
+package httphandler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/tecnickcom/nurago/pkg/httputil"
+	"github.com/tecnickcom/rndpwd/internal/password"
+)
+
+// DefaultBytesMaxSize is the default maximum number of bytes of each /bytes
+// value.
+const DefaultBytesMaxSize = 1024
+
+// bytesResponse is the body of the /bytes response.
+type bytesResponse struct {
+	Size     int      `json:"size"`
+	Encoding string   `json:"encoding"`
+	Entropy  int      `json:"entropy"`
+	Values   []string `json:"values"`
+}
+
+// WithBytes sets the default settings of the /bytes route and the maximum size
+// of each value (default DefaultBytesMaxSize).
+func WithBytes(b *password.Bytes, maxSize int) Option {
+	return func(h *HTTPHandler) {
+		h.bytes = b
+		h.bytesMaxSize = maxSize
+	}
+}
+
+// bytesParams returns the query parameters accepted by the /bytes route.
+func bytesParams() map[string]paramType {
+	return map[string]paramType{
+		"size":     paramInt,
+		"encoding": paramString,
+		"quantity": paramInt,
+	}
+}
+
+func (h *HTTPHandler) handleBytes(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if !validQueryParams(query, bytesParams()) {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, "invalid query parameter")
+		return
+	}
+
+	// URL query parameters can override the config settings
+	b := password.NewBytes(
+		httputil.QueryIntOrDefault(query, "size", h.bytes.Size),
+		httputil.QueryIntOrDefault(query, "quantity", h.bytes.Quantity),
+		httputil.QueryStringOrDefault(query, "encoding", h.bytes.Encoding),
+		password.WithBytesSource(h.source),
+	)
+
+	err := h.val.ValidateStruct(b)
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if b.Size > h.bytesMaxSize {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, fmt.Sprintf("the size must be at most %d bytes", h.bytesMaxSize))
+		return
+	}
+
+	lst, err := b.Generate()
+	if errors.Is(err, password.ErrInvalidEncoding) {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusInternalServerError, "failed generating random bytes")
+		return
+	}
+
+	h.httpres.SendJSON(r.Context(), w, http.StatusOK, bytesResponse{
+		Size:     b.Size,
+		Encoding: b.Encoding,
+		Entropy:  8 * b.Size,
+		Values:   lst,
+	})
+}
@@ -0,0 +1,127 @@
+package httphandler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/rndpwd/internal/password"
+	"github.com/tecnickcom/rndpwd/internal/validator"
+)
+
+func TestHTTPHandler_handleBytes(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	h := New(
+		nil,
+		nil,
+		nil,
+		val,
+		password.New("0123456789abcdefghijklmnopqrstuvwxyz", 16, 3),
+		WithBytes(password.NewBytes(16, 2, password.EncodingBase64URL), 64),
+	)
+
+	tests := []struct {
+		name         string
+		params       string
+		wantErr      bool
+		wantSize     int
+		wantEncoding string
+		wantQty      int
+	}{
+		{
+			name:         "valid empty",
+			params:       "",
+			wantSize:     16,
+			wantEncoding: password.EncodingBase64URL,
+			wantQty:      2,
+		},
+		{
+			name:         "valid all params",
+			params:       "?size=64&encoding=hex&quantity=3",
+			wantSize:     64,
+			wantEncoding: password.EncodingHex,
+			wantQty:      3,
+		},
+		{
+			name:         "z85",
+			params:       "?size=32&encoding=z85",
+			wantSize:     32,
+			wantEncoding: password.EncodingZ85,
+			wantQty:      2,
+		},
+		{
+			name:    "above the max size",
+			params:  "?size=65",
+			wantErr: true,
+		},
+		{
+			name:    "zero size",
+			params:  "?size=0",
+			wantErr: true,
+		},
+		{
+			name:    "z85 size not multiple of 4",
+			params:  "?size=6&encoding=z85",
+			wantErr: true,
+		},
+		{
+			name:    "unknown encoding",
+			params:  "?encoding=base36",
+			wantErr: true,
+		},
+		{
+			name:    "unknown parameter",
+			params:  "?length=8",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "/bytes"+tt.params, nil)
+
+			h.handleBytes(rr, req)
+
+			resp := rr.Result()
+			require.NotNil(t, resp)
+
+			defer func() {
+				err := resp.Body.Close()
+				require.NoError(t, err, "error closing resp.Body")
+			}()
+
+			if tt.wantErr {
+				require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				return
+			}
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			body, _ := io.ReadAll(resp.Body)
+
+			var data bytesResponse
+
+			require.NoError(t, json.Unmarshal(body, &data))
+			require.Equal(t, tt.wantSize, data.Size)
+			require.Equal(t, tt.wantEncoding, data.Encoding)
+			require.Equal(t, 8*tt.wantSize, data.Entropy)
+			require.Len(t, data.Values, tt.wantQty)
+
+			if tt.wantEncoding == password.EncodingBase64URL {
+				raw, err := base64.RawURLEncoding.DecodeString(data.Values[0])
+				require.NoError(t, err)
+				require.Len(t, raw, tt.wantSize)
+			}
+		})
+	}
+}
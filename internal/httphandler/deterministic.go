@@ -0,0 +1,64 @@
+package httphandler
+
+import (
+	"net/http"
+
+	"github.com/tecnickcom/rndpwd/internal/password"
+)
+
+// Deterministic test mode.
+const (
+	// headerSeed contains the seed of the deterministic output, as an
+	// alternative to the seed query parameter.
+	headerSeed = "X-Test-Seed"
+
+	// paramSeed is the query parameter containing the seed of the
+	// deterministic output.
+	paramSeed = "seed"
+
+	// HeaderDeterministicWarning is set on every response while the
+	// deterministic test mode is enabled.
+	HeaderDeterministicWarning = "X-Deterministic-Warning"
+
+	// deterministicWarning is the value of the HeaderDeterministicWarning.
+	deterministicWarning = "deterministic test mode: the output is reproducible and must never be used as a secret"
+)
+
+// WithDeterministic enables the deterministic test mode, where the /password
+// and /uid output is reproducible from the seed supplied in the request.
+func WithDeterministic(enable bool) Option {
+	return func(h *HTTPHandler) {
+		h.deterministic = enable
+	}
+}
+
+// DeterministicWarning returns a handler adding the deterministic test mode
+// warning header to every response of the next handler.
+func DeterministicWarning(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderDeterministicWarning, deterministicWarning)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestSource returns the entropy source of the request: the deterministic
+// source of the seed supplied in the query or header, if any, or the service
+// source. It reports whether the source is seeded. A seed is rejected when the
+// deterministic test mode is disabled.
+func (h *HTTPHandler) requestSource(r *http.Request) (password.Source, bool, error) {
+	seed := r.URL.Query().Get(paramSeed)
+	if seed == "" {
+		seed = r.Header.Get(headerSeed)
+	}
+
+	if seed == "" {
+		return h.source, false, nil
+	}
+
+	src, err := password.NewDeterministicSource(seed, h.deterministic)
+	if err != nil {
+		return nil, false, err //nolint:wrapcheck
+	}
+
+	return src, true, nil
+}
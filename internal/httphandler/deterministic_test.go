@@ -0,0 +1,106 @@
+package httphandler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/rndpwd/internal/password"
+	"github.com/tecnickcom/rndpwd/internal/validator"
+)
+
+func TestDeterministicWarning(t *testing.T) {
+	t.Parallel()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+
+	DeterministicWarning(next).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusTeapot, rr.Code)
+	require.Equal(t, deterministicWarning, rr.Header().Get(HeaderDeterministicWarning))
+}
+
+func TestHTTPHandler_deterministic(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	newHandler := func(enabled bool) *HTTPHandler {
+		return New(nil, nil, nil, val, password.New("alnum", 16, 3), WithDeterministic(enabled))
+	}
+
+	serve := func(h *HTTPHandler, handler http.HandlerFunc, target, seedHeader string) (int, string) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, target, nil)
+
+		if seedHeader != "" {
+			req.Header.Set(headerSeed, seedHeader)
+		}
+
+		handler(rr, req)
+
+		resp := rr.Result()
+
+		defer func() {
+			err := resp.Body.Close()
+			require.NoError(t, err, "error closing resp.Body")
+		}()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp.StatusCode, string(body)
+	}
+
+	h := newHandler(true)
+
+	code, pwdA := serve(h, h.handlePassword, "/password?seed=abc", "")
+	require.Equal(t, http.StatusOK, code)
+
+	_, pwdB := serve(h, h.handlePassword, "/password?seed=abc", "")
+	require.Equal(t, pwdA, pwdB)
+
+	_, pwdB = serve(h, h.handlePassword, "/password", "abc")
+	require.Equal(t, pwdA, pwdB)
+
+	_, pwdB = serve(h, h.handlePassword, "/password?seed=abd", "")
+	require.NotEqual(t, pwdA, pwdB)
+
+	_, pwdB = serve(h, h.handlePassword, "/password", "")
+	require.NotEqual(t, pwdA, pwdB)
+
+	code, uidA := serve(h, h.handleGenUID, "/uid?seed=abc", "")
+	require.Equal(t, http.StatusOK, code)
+	require.Regexp(t, regexp.MustCompile(`^"[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}"`), uidA)
+
+	_, uidB := serve(h, h.handleGenUID, "/uid", "abc")
+	require.Equal(t, uidA, uidB)
+
+	code, uidA = serve(h, h.handleGenUID, "/uid?seed=abc&format=nanoid&quantity=3", "")
+	require.Equal(t, http.StatusOK, code)
+
+	_, uidB = serve(h, h.handleGenUID, "/uid?format=nanoid&quantity=3", "abc")
+	require.Equal(t, uidA, uidB)
+
+	code, _ = serve(h, h.handleGenUID, "/uid?seed=abc&format=uuidv7", "")
+	require.Equal(t, http.StatusBadRequest, code)
+
+	h = newHandler(false)
+
+	code, _ = serve(h, h.handlePassword, "/password", "abc")
+	require.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = serve(h, h.handleGenUID, "/uid?seed=abc", "")
+	require.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = serve(h, h.handleGenUID, "/uid", "")
+	require.Equal(t, http.StatusOK, code)
+}
@@ -0,0 +1,116 @@
+package httphandler
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/tecnickcom/nurago/pkg/httputil"
+	"github.com/tecnickcom/rndpwd/internal/pwhash"
+)
+
+// DefaultHashMaxQuantity is the default maximum number of passwords hashed by
+// a single request.
+const DefaultHashMaxQuantity = 10
+
+// hashParamPrefix is the prefix of the query parameters overriding the hash
+// work factors, as in hash_cost.
+const hashParamPrefix = "hash_"
+
+// hashedPassword is a generated password along with its hash.
+type hashedPassword struct {
+	Password string `json:"password"`
+	Hash     string `json:"hash"`
+}
+
+// WithPasswordHash sets the default work factors of the /password hashes, the
+// upper bounds a request can ask for and the maximum number of passwords
+// hashed by a request (default pwhash.DefaultParams, pwhash.DefaultLimits and
+// DefaultHashMaxQuantity).
+func WithPasswordHash(defaults, limits pwhash.Params, maxQuantity int) Option {
+	return func(h *HTTPHandler) {
+		h.hashDefaults = defaults
+		h.hashLimits = limits
+		h.hashMaxQuantity = maxQuantity
+	}
+}
+
+// hashParams returns the query parameters overriding the hash work factors.
+func hashParams() map[string]paramType {
+	params := map[string]paramType{"hash": paramString}
+
+	for _, alg := range pwhash.Algorithms() {
+		for _, name := range pwhash.ParamNames(alg) {
+			params[hashParamPrefix+name] = paramInt
+		}
+	}
+
+	return params
+}
+
+// hashRequest returns the hash algorithm of the request and its work factors,
+// the configured defaults overridden by the query parameters. The algorithm is
+// empty when no hash is requested. The passwords are up to size bytes long, and
+// are checked against the algorithm bounds before any of them is hashed.
+func (h *HTTPHandler) hashRequest(query url.Values, quantity, size int) (string, pwhash.Params, error) {
+	alg := query.Get("hash")
+	params := h.hashDefaults
+
+	fields := map[string]*int{
+		"cost":       &params.Cost,
+		"memory":     &params.Memory,
+		"time":       &params.Time,
+		"threads":    &params.Threads,
+		"ln":         &params.LN,
+		"r":          &params.R,
+		"p":          &params.P,
+		"iterations": &params.Iterations,
+		"rounds":     &params.Rounds,
+	}
+
+	used := make(map[string]bool, len(fields))
+
+	for _, name := range pwhash.ParamNames(alg) {
+		used[name] = true
+		*fields[name] = httputil.QueryIntOrDefault(query, hashParamPrefix+name, *fields[name])
+	}
+
+	for _, other := range pwhash.Algorithms() {
+		for _, name := range pwhash.ParamNames(other) {
+			if query.Has(hashParamPrefix+name) && !used[name] {
+				return "", params, fmt.Errorf("%s%s is not a parameter of the %q hash", hashParamPrefix, name, alg)
+			}
+		}
+	}
+
+	if alg == "" {
+		return "", params, nil
+	}
+
+	if quantity > h.hashMaxQuantity {
+		return "", params, fmt.Errorf("at most %d passwords can be hashed by a single request", h.hashMaxQuantity)
+	}
+
+	err := params.Check(alg, h.hashLimits)
+	if err != nil {
+		return "", params, err //nolint:wrapcheck
+	}
+
+	err = pwhash.CheckPassword(alg, size)
+	if err != nil {
+		return "", params, err //nolint:wrapcheck
+	}
+
+	return alg, params, nil
+}
+
+// passwordHasher returns the function hashing each password with the
+// algorithm, or nil when no hash is requested.
+func passwordHasher(alg string, params pwhash.Params) func(pwd string) (string, error) {
+	if alg == "" {
+		return nil
+	}
+
+	return func(pwd string) (string, error) {
+		return pwhash.Hash(alg, params, pwd) //nolint:wrapcheck
+	}
+}
@@ -0,0 +1,202 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/rndpwd/internal/password"
+	"github.com/tecnickcom/rndpwd/internal/pwhash"
+	"github.com/tecnickcom/rndpwd/internal/validator"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHTTPHandler_handlePassword_hash(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	// cheap work factors keep the test fast
+	defaults := pwhash.Params{Cost: 4, Memory: 64, Time: 1, Threads: 1, LN: 4, R: 8, P: 1, Iterations: 1000, Rounds: 1000}
+
+	h := New(
+		nil,
+		nil,
+		nil,
+		val,
+		password.New("0123456789abcdefghijklmnopqrstuvwxyz", 16, 3),
+		WithPasswordHash(defaults, pwhash.DefaultLimits(), 5),
+	)
+
+	tests := []struct {
+		name       string
+		params     string
+		wantCode   int
+		wantPrefix string
+	}{
+		{
+			name:       "bcrypt",
+			params:     "?hash=bcrypt",
+			wantCode:   http.StatusOK,
+			wantPrefix: "$2a$04$",
+		},
+		{
+			name:       "bcrypt with cost",
+			params:     "?hash=bcrypt&hash_cost=5",
+			wantCode:   http.StatusOK,
+			wantPrefix: "$2a$05$",
+		},
+		{
+			name:       "argon2id",
+			params:     "?hash=argon2id&hash_memory=128&hash_time=2&hash_threads=2",
+			wantCode:   http.StatusOK,
+			wantPrefix: "$argon2id$v=19$m=128,t=2,p=2$",
+		},
+		{
+			name:       "scrypt",
+			params:     "?hash=scrypt&hash_ln=5",
+			wantCode:   http.StatusOK,
+			wantPrefix: "$scrypt$ln=5,r=8,p=1$",
+		},
+		{
+			name:       "pbkdf2-sha256",
+			params:     "?hash=pbkdf2-sha256&hash_iterations=2000",
+			wantCode:   http.StatusOK,
+			wantPrefix: "$pbkdf2-sha256$i=2000,l=32$",
+		},
+		{
+			name:       "sha512-crypt",
+			params:     "?hash=sha512-crypt&hash_rounds=2000",
+			wantCode:   http.StatusOK,
+			wantPrefix: "$6$rounds=2000$",
+		},
+		{
+			name:     "unknown algorithm",
+			params:   "?hash=md5-crypt",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "cost above the limit",
+			params:   "?hash=bcrypt&hash_cost=15",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "parameter of another algorithm",
+			params:   "?hash=bcrypt&hash_memory=128",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "parameter without algorithm",
+			params:   "?hash_cost=4",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "not integer parameter",
+			params:   "?hash=bcrypt&hash_cost=high",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "quantity above the hash limit",
+			params:   "?hash=bcrypt&quantity=6",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "password too long for bcrypt",
+			params:   "?hash=bcrypt&length=73&quantity=1",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "password too long for sha512-crypt",
+			params:   "?hash=sha512-crypt&length=4096&quantity=5&hash_rounds=2000000",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "template too long for sha512-crypt",
+			params:   "?hash=sha512-crypt&mode=template&template=[αβγ]{129}&quantity=1",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "/"+tt.params, nil)
+
+			h.handlePassword(rr, req)
+
+			resp := rr.Result()
+			require.NotNil(t, resp)
+
+			defer func() {
+				err := resp.Body.Close()
+				require.NoError(t, err, "error closing resp.Body")
+			}()
+
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			body, _ := io.ReadAll(resp.Body)
+
+			var data []hashedPassword
+
+			require.NoError(t, json.Unmarshal(body, &data))
+			require.Len(t, data, 3)
+
+			for _, d := range data {
+				require.Len(t, d.Password, 16)
+				require.True(t, strings.HasPrefix(d.Hash, tt.wantPrefix), "unexpected hash %q", d.Hash)
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_handlePassword_hashDetail(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	h := New(
+		nil,
+		nil,
+		nil,
+		val,
+		password.New("0123456789abcdef", 8, 2),
+		WithPasswordHash(pwhash.Params{Cost: 4}, pwhash.DefaultLimits(), DefaultHashMaxQuantity),
+	)
+
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "/?hash=bcrypt&detail=true", nil)
+
+	h.handlePassword(rr, req)
+
+	resp := rr.Result()
+	require.NotNil(t, resp)
+
+	defer func() {
+		err := resp.Body.Close()
+		require.NoError(t, err, "error closing resp.Body")
+	}()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, _ := io.ReadAll(resp.Body)
+
+	var data []password.Detail
+
+	require.NoError(t, json.Unmarshal(body, &data))
+	require.Len(t, data, 2)
+
+	for _, d := range data {
+		require.Equal(t, "4294967296", d.Keyspace)
+		require.NoError(t, bcrypt.CompareHashAndPassword([]byte(d.Hash), []byte(d.Password)))
+	}
+}
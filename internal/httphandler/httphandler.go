@@ -3,7 +3,11 @@ package httphandler
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"maps"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -11,41 +15,144 @@ import (
 	"github.com/tecnickcom/nurago/pkg/httpserver"
 	"github.com/tecnickcom/nurago/pkg/httputil"
 	"github.com/tecnickcom/nurago/pkg/httputil/jsendx"
-	"github.com/tecnickcom/nurago/pkg/random"
 	"github.com/tecnickcom/rndpwd/internal/metrics"
 	"github.com/tecnickcom/rndpwd/internal/password"
+	"github.com/tecnickcom/rndpwd/internal/pwhash"
+	"github.com/tecnickcom/rndpwd/internal/sshkey"
+	"github.com/tecnickcom/rndpwd/internal/uid"
 	"github.com/tecnickcom/rndpwd/internal/validator"
 )
 
+// Response headers of the /password route.
+const (
+	// headerEntropy contains the entropy in bits of each generated password.
+	headerEntropy = "X-Password-Entropy"
+
+	// headerCharsetSize contains the number of characters of the effective
+	// charset.
+	headerCharsetSize = "X-Password-Charset-Size"
+)
+
 // generator produces random passwords.
 type generator interface {
 	Generate() ([]string, error)
+	GenerateDetails() ([]password.Detail, error)
+	Entropy() (float64, error)
+	CharsetSize() int
+	MaxSize() int
+	Stream(ctx context.Context, n int, emit func(pwd string) error) error
 }
 
 // HTTPHandler is the struct containing all the http handlers.
 type HTTPHandler struct {
-	httpres     *httputil.HTTPResp
-	appInfo     *jsendx.AppInfo
-	metric      metrics.Metrics
-	val         validator.Validator
-	rndpwd      *password.Password
-	rnd         *random.Rnd
-	newPassword func(charset string, length, quantity int) generator
+	httpres           *httputil.HTTPResp
+	appInfo           *jsendx.AppInfo
+	metric            metrics.Metrics
+	val               validator.Validator
+	rndpwd            *password.Password
+	passphrase        *password.Passphrase
+	pin               *password.PIN
+	otp               *password.OTP
+	token             *password.Token
+	recovery          *password.RecoveryCodes
+	recoveryHash      string
+	bytes             *password.Bytes
+	bytesMaxSize      int
+	sshKey            *sshkey.Generator
+	sshMaxRSABits     int
+	sshMaxConcurrent  int
+	sshSlots          chan struct{}
+	source            password.Source
+	deterministic     bool
+	streamMaxQuantity int
+	hashDefaults      pwhash.Params
+	hashLimits        pwhash.Params
+	hashMinimum       pwhash.Params
+	hashMaxQuantity   int
+	breach            breachCorpus
+	rejectBreached    bool
+	uid               *uid.Generator
+	uidFormat         string
+	uidMaxQuantity    int
+	snowflakeNode     int64
+	snowflakeEpoch    int64
+	newPassword       func(charset string, length, quantity int, opts ...password.Option) generator
+	newPassphrase     func(wordlist string, words, quantity int, opts ...password.PassphraseOption) passphraseGenerator
+	newPIN            func(length, quantity int, opts ...password.PINOption) pinGenerator
+}
+
+// Option is a type to allow setting custom handler options.
+type Option func(h *HTTPHandler)
+
+// WithPassphrase sets the default settings of the /passphrase route.
+func WithPassphrase(pp *password.Passphrase) Option {
+	return func(h *HTTPHandler) {
+		h.passphrase = pp
+	}
+}
+
+// WithPIN sets the default settings of the /pin route.
+func WithPIN(pin *password.PIN) Option {
+	return func(h *HTTPHandler) {
+		h.pin = pin
+	}
+}
+
+// WithSource sets the entropy source shared by all the generators (default the
+// OS CSPRNG).
+func WithSource(src password.Source) Option {
+	return func(h *HTTPHandler) {
+		h.source = src
+	}
 }
 
 // New creates a new instance of the HTTP handler.
-func New(l *slog.Logger, appInfo *jsendx.AppInfo, metric metrics.Metrics, val validator.Validator, rndpwd *password.Password) *HTTPHandler {
-	return &HTTPHandler{
-		httpres: httputil.NewHTTPResp(l),
-		appInfo: appInfo,
-		metric:  metric,
-		val:     val,
-		rndpwd:  rndpwd,
-		rnd:     random.New(nil),
-		newPassword: func(charset string, length, quantity int) generator {
-			return password.New(charset, length, quantity)
+func New(l *slog.Logger, appInfo *jsendx.AppInfo, metric metrics.Metrics, val validator.Validator, rndpwd *password.Password, opts ...Option) *HTTPHandler {
+	h := &HTTPHandler{
+		httpres:           httputil.NewHTTPResp(l),
+		appInfo:           appInfo,
+		metric:            metric,
+		val:               val,
+		rndpwd:            rndpwd,
+		passphrase:        password.NewPassphrase(password.WordlistEFFLarge, 6, 1),
+		pin:               password.NewPIN(6, 1),
+		otp:               password.NewOTP(""),
+		token:             password.NewToken("rnd_", 160, 1),
+		recovery:          password.NewRecoveryCodes(3, 4, 10),
+		recoveryHash:      pwhash.Argon2id,
+		bytes:             password.NewBytes(32, 1, password.EncodingHex),
+		bytesMaxSize:      DefaultBytesMaxSize,
+		sshKey:            sshkey.New(sshkey.TypeEd25519, sshkey.MinRSABits, ""),
+		sshMaxRSABits:     sshkey.MaxRSABits,
+		sshMaxConcurrent:  DefaultSSHKeyMaxConcurrent,
+		source:            password.NewOSSource(),
+		streamMaxQuantity: DefaultStreamMaxQuantity,
+		hashDefaults:      pwhash.DefaultParams(),
+		hashLimits:        pwhash.DefaultLimits(),
+		hashMinimum:       pwhash.DefaultMinimums(),
+		hashMaxQuantity:   DefaultHashMaxQuantity,
+		uidFormat:         uid.FormatUUIDv7,
+		uidMaxQuantity:    DefaultUIDMaxQuantity,
+		snowflakeEpoch:    uid.DefaultSnowflakeEpoch,
+		newPassword: func(charset string, length, quantity int, opts ...password.Option) generator {
+			return password.New(charset, length, quantity, opts...)
+		},
+		newPassphrase: func(wordlist string, words, quantity int, opts ...password.PassphraseOption) passphraseGenerator {
+			return password.NewPassphrase(wordlist, words, quantity, opts...)
+		},
+		newPIN: func(length, quantity int, opts ...password.PINOption) pinGenerator {
+			return password.NewPIN(length, quantity, opts...)
 		},
 	}
+
+	for _, applyOpt := range opts {
+		applyOpt(h)
+	}
+
+	h.sshSlots = make(chan struct{}, h.sshMaxConcurrent)
+	h.uid = uid.New(uid.WithSource(h.source), uid.WithSnowflake(h.snowflakeNode, h.snowflakeEpoch))
+
+	return h
 }
 
 // BindHTTP implements the function to bind the handler to a server.
@@ -55,62 +162,305 @@ func (h *HTTPHandler) BindHTTP(_ context.Context) []httpserver.Route {
 			Method:      http.MethodGet,
 			Path:        "/password",
 			Handler:     h.handlePassword,
-			Description: "Returns random passwords; charset, length and quantity can be specified as query parameters",
+			Description: "Returns random passwords, never breached when the breach corpus is configured to reject them; charset, length, quantity, mode, template, exclude_ambiguous, the per-class bounds and the repetition and sequence constraints can be specified as query parameters; unique=true guarantees distinct passwords within the response; hash adds the bcrypt, argon2id, scrypt, pbkdf2-sha256 or sha512-crypt hash of each password; detail=true adds the entropy and keyspace of each password; Accept: application/x-ndjson streams the passwords one per line; in the deterministic test mode the output is reproducible from the seed query parameter or X-Test-Seed header",
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/passphrase",
+			Handler:     h.handlePassphrase,
+			Description: "Returns random diceware passphrases and their entropy; wordlist, words, separator, capitalize, digit, symbol and quantity can be specified as query parameters",
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/pin",
+			Handler:     h.handlePIN,
+			Description: "Returns random numeric PINs, excluding the weak ones, and their entropy; length and quantity can be specified as query parameters",
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/totp",
+			Handler:     h.handleOTP,
+			Description: "Returns a random base32 TOTP or HOTP shared secret and its otpauth:// key URI; account, issuer, type, algorithm, digits, period and counter can be specified as query parameters",
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/totp/codes",
+			Handler:     h.handleOTPCodes,
+			Description: "Computes the current and adjacent TOTP or HOTP codes of the base32 secret in the JSON request body",
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/token",
+			Handler:     h.handleToken,
+			Description: "Returns random prefixed API tokens with a base62 body and a CRC32 checksum suffix, along with the regular expression matching them; prefix, bits and quantity can be specified as query parameters",
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/token/validate",
+			Handler:     h.handleTokenValidate,
+			Description: "Checks the format and checksum of the API token in the JSON request body, optionally against an expected prefix, without any storage",
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/recovery-codes",
+			Handler:     h.handleRecoveryCodes,
+			Description: "Returns a set of distinct one-time recovery codes of dash-separated Crockford base32 groups, each one along with its hash; groups, group_size, quantity, hash and the hash work factors can be specified as query parameters",
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/bytes",
+			Handler:     h.handleBytes,
+			Description: "Returns cryptographically random bytes encoded as hex, base64, base64url, base32, base58, base85, z85 or a Go or C byte-array literal; size, encoding and quantity can be specified as query parameters",
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/sshkey",
+			Handler:     h.handleSSHKey,
+			Description: "Generates an Ed25519, ECDSA P-256/P-384 or RSA 3072/4096 SSH key pair; returns the OpenSSH private key, optionally encrypted with the supplied or a generated passphrase, the authorized_keys line and the SHA256 fingerprint",
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/strength",
+			Handler:     h.handleStrength,
+			Description: "Estimates the strength of the password in the JSON request body, optionally along with user-specific inputs; returns the score from 0 to 4, the guesses, the matched patterns and the feedback",
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/breached",
+			Handler:     h.handleBreached,
+			Description: "Checks whether the password, or its hash, in the JSON request body is found in the local breached-password corpus; returns 503 when no corpus is loaded",
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/verify",
+			Handler:     h.handleVerify,
+			Description: "Verifies the password in the JSON request body against a stored bcrypt, argon2, scrypt, PBKDF2, SHA-crypt or LDAP salted SHA hash, and reports the detected algorithm and work factors and whether they are below the configured minimum",
 		},
 		{
 			Method:      http.MethodGet,
 			Path:        "/uid",
 			Handler:     h.handleGenUID,
-			Description: "Generates a random UID",
+			Description: "Generates one or more unique identifiers: UUIDv4, UUIDv5, UUIDv7, ULID, KSUID, NanoID or Snowflake; the time-ordered identifiers of a batch are strictly increasing; in the deterministic test mode the non time-ordered identifiers are reproducible from the seed query parameter or X-Test-Seed header",
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/uid/inspect",
+			Handler:     h.handleInspectUID,
+			Description: "Detects whether the id query parameter is a UUID, ULID, KSUID or Snowflake ID, and returns whether it is well formed along with its version, variant, timestamp, node and sequence",
 		},
 	}
 }
 
-func (h *HTTPHandler) handleGenUID(w http.ResponseWriter, r *http.Request) {
-	h.httpres.SendJSON(r.Context(), w, http.StatusOK, h.rnd.UUIDv7().String())
-}
-
 func (h *HTTPHandler) handlePassword(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 
-	if !validQueryParams(query) {
+	if !validQueryParams(query, passwordParams()) {
 		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, "invalid query parameter")
 		return
 	}
 
+	src, _, err := h.requestSource(r)
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	quantity := httputil.QueryIntOrDefault(query, "quantity", h.rndpwd.Quantity)
+	streamQuantity := 0
+
+	if acceptsNDJSON(r) {
+		err = h.checkStreamRequest(query, quantity)
+		if err != nil {
+			h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// the streamed passwords are generated one at a time
+		quantity, streamQuantity = 1, quantity
+	}
+
 	// URL query parameters can override the config settings
 	p := h.newPassword(
-		httputil.QueryStringOrDefault(query, "charset", h.rndpwd.Charset),
+		httputil.QueryStringOrDefault(query, "charset", string(h.rndpwd.Charset)),
 		httputil.QueryIntOrDefault(query, "length", h.rndpwd.Length),
-		httputil.QueryIntOrDefault(query, "quantity", h.rndpwd.Quantity),
+		quantity,
+		password.WithMode(h.passwordMode(query)),
+		password.WithTemplate(httputil.QueryStringOrDefault(query, "template", string(h.rndpwd.Template))),
+		password.WithExcludeAmbiguous(queryBoolOrDefault(query, "exclude_ambiguous", h.rndpwd.ExcludeAmbiguous)),
+		password.WithPolicy(policyFromQuery(query, h.rndpwd.Policy)),
+		password.WithConstraints(constraintsFromQuery(query, h.rndpwd.Constraints)),
+		password.WithUnique(queryBoolOrDefault(query, "unique", h.rndpwd.Unique)),
+		password.WithSource(src),
+		password.WithBlocklist(h.passwordBlocklist()),
 	)
 
-	err := h.val.ValidateStruct(p)
+	err = h.val.ValidateStruct(p)
 	if err != nil {
 		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	pwds, err := p.Generate()
+	alg, hp, err := h.hashRequest(query, quantity, p.MaxSize())
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bits, err := p.Entropy()
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusInternalServerError, "failed computing the password entropy")
+		return
+	}
+
+	if streamQuantity > 0 {
+		setStrengthHeaders(w, bits, p.CharsetSize())
+
+		// the status is sent before the first password, so a failure can only
+		// cut the output short
+		_ = streamPasswords(r.Context(), w, p, streamQuantity)
+
+		return
+	}
+
+	pwds, err := generatePasswords(p, queryBoolOrDefault(query, "detail", false), passwordHasher(alg, hp))
+	if errors.Is(err, password.ErrKeyspaceTooSmall) || errors.Is(err, pwhash.ErrPasswordTooLong) {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	if err != nil {
 		h.httpres.SendJSON(r.Context(), w, http.StatusInternalServerError, "failed generating passwords")
 		return
 	}
 
+	setStrengthHeaders(w, bits, p.CharsetSize())
+
 	h.httpres.SendJSON(r.Context(), w, http.StatusOK, pwds)
 }
 
-// validQueryParams reports whether the request query only contains the allowed
-// single-valued parameters, with integer values where required.
-func validQueryParams(query url.Values) bool {
-	validParam := map[string]bool{
-		"charset":  true,
-		"length":   true,
-		"quantity": true,
+// passwordMode returns the generation mode of the request. A template
+// parameter implies the template mode, unless the mode is also specified.
+func (h *HTTPHandler) passwordMode(query url.Values) string {
+	if query.Has("template") && !query.Has("mode") {
+		return password.ModeTemplate
+	}
+
+	return httputil.QueryStringOrDefault(query, "mode", h.rndpwd.Mode)
+}
+
+// setStrengthHeaders sets the entropy and charset size response headers.
+func setStrengthHeaders(w http.ResponseWriter, bits float64, charsetSize int) {
+	w.Header().Set(headerEntropy, strconv.FormatFloat(bits, 'f', 2, 64))
+	w.Header().Set(headerCharsetSize, strconv.Itoa(charsetSize))
+}
+
+// checkStreamRequest reports whether the streaming output can be used for the
+// request. The streaming quantity has its own ceiling.
+func (h *HTTPHandler) checkStreamRequest(query url.Values, quantity int) error {
+	if queryBoolOrDefault(query, "detail", false) {
+		return errors.New("detail is not supported by the streaming output")
+	}
+
+	if queryBoolOrDefault(query, "unique", h.rndpwd.Unique) {
+		return errors.New("unique is not supported by the streaming output")
 	}
 
+	if query.Has("hash") {
+		return errors.New("hash is not supported by the streaming output")
+	}
+
+	if quantity < 1 || quantity > h.streamMaxQuantity {
+		return fmt.Errorf("the streaming quantity must be between 1 and %d", h.streamMaxQuantity)
+	}
+
+	return nil
+}
+
+// generatePasswords returns the bare passwords, or the passwords along with
+// their entropy and keyspace when detail is set. The hash of each password is
+// added when hash is not nil.
+func generatePasswords(p generator, detail bool, hash func(pwd string) (string, error)) (any, error) {
+	if detail {
+		lst, err := p.GenerateDetails()
+		if err != nil || hash == nil {
+			return lst, err //nolint:wrapcheck
+		}
+
+		for i := range lst {
+			lst[i].Hash, err = hash(lst[i].Password)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return lst, nil
+	}
+
+	pwds, err := p.Generate()
+	if err != nil || hash == nil {
+		return pwds, err //nolint:wrapcheck
+	}
+
+	lst := make([]hashedPassword, len(pwds))
+
+	for i, pwd := range pwds {
+		lst[i].Password = pwd
+
+		lst[i].Hash, err = hash(pwd)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return lst, nil
+}
+
+// paramType is the expected format of a query parameter value.
+type paramType int
+
+const (
+	paramString paramType = iota
+	paramInt
+	paramBool
+)
+
+// passwordParams returns the query parameters accepted by the /password route.
+func passwordParams() map[string]paramType {
+	params := map[string]paramType{
+		"charset":           paramString,
+		"length":            paramInt,
+		"quantity":          paramInt,
+		"mode":              paramString,
+		"template":          paramString,
+		"detail":            paramBool,
+		"exclude_ambiguous": paramBool,
+		"seed":              paramString,
+		"min_upper":         paramInt,
+		"max_upper":         paramInt,
+		"min_lower":         paramInt,
+		"max_lower":         paramInt,
+		"min_digit":         paramInt,
+		"max_digit":         paramInt,
+		"min_symbol":        paramInt,
+		"max_symbol":        paramInt,
+		"max_consecutive":   paramInt,
+		"max_per_char":      paramInt,
+		"min_distinct":      paramInt,
+		"no_sequences":      paramBool,
+		"unique":            paramBool,
+	}
+
+	maps.Copy(params, hashParams())
+
+	return params
+}
+
+// validQueryParams reports whether the request query only contains the allowed
+// single-valued parameters, with values of the expected type.
+func validQueryParams(query url.Values, allowed map[string]paramType) bool {
 	for param := range query {
-		if !validParam[param] || len(query[param]) > 1 || query.Get(param) == "" || (param != "charset" && !isInteger(query.Get(param))) {
+		typ, ok := allowed[param]
+		if !ok || len(query[param]) > 1 || query.Get(param) == "" || !validParamValue(typ, query.Get(param)) {
 			return false
 		}
 	}
@@ -118,7 +468,76 @@ func validQueryParams(query url.Values) bool {
 	return true
 }
 
+func validParamValue(typ paramType, value string) bool {
+	switch typ {
+	case paramInt:
+		return isInteger(value)
+	case paramBool:
+		return isBool(value)
+	default:
+		return true
+	}
+}
+
+// policyFromQuery returns a copy of the default policy with the class bounds
+// overridden by the URL query parameters. The custom classes can only be
+// defined in the configuration.
+func policyFromQuery(query url.Values, pol password.Policy) password.Policy {
+	bounds := map[string]*int{
+		"min_upper":  &pol.MinUpper,
+		"max_upper":  &pol.MaxUpper,
+		"min_lower":  &pol.MinLower,
+		"max_lower":  &pol.MaxLower,
+		"min_digit":  &pol.MinDigit,
+		"max_digit":  &pol.MaxDigit,
+		"min_symbol": &pol.MinSymbol,
+		"max_symbol": &pol.MaxSymbol,
+	}
+
+	for param, bound := range bounds {
+		*bound = httputil.QueryIntOrDefault(query, param, *bound)
+	}
+
+	return pol
+}
+
+// constraintsFromQuery returns a copy of the default repetition and sequence
+// constraints overridden by the URL query parameters.
+func constraintsFromQuery(query url.Values, c password.Constraints) password.Constraints {
+	c.MaxConsecutive = httputil.QueryIntOrDefault(query, "max_consecutive", c.MaxConsecutive)
+	c.MaxPerChar = httputil.QueryIntOrDefault(query, "max_per_char", c.MaxPerChar)
+	c.MinDistinct = httputil.QueryIntOrDefault(query, "min_distinct", c.MinDistinct)
+	c.NoSequences = queryBoolOrDefault(query, "no_sequences", c.NoSequences)
+
+	return c
+}
+
 func isInteger(s string) bool {
 	_, err := strconv.ParseInt(s, 10, 64)
 	return err == nil
 }
+
+func isBool(s string) bool {
+	_, err := strconv.ParseBool(s)
+	return err == nil
+}
+
+// queryBoolOrDefault returns the boolean value of the query parameter, or the
+// default value when the parameter is missing or invalid.
+func queryBoolOrDefault(query url.Values, key string, defaultValue bool) bool {
+	v, err := strconv.ParseBool(query.Get(key))
+	if err != nil {
+		return defaultValue
+	}
+
+	return v
+}
+
+// decodeJSONBody decodes into v the JSON request body of at most maxSize bytes,
+// rejecting the unknown fields.
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, maxSize int64, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSize))
+	dec.DisallowUnknownFields()
+
+	return dec.Decode(v) //nolint:wrapcheck
+}
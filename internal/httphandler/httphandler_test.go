@@ -1,6 +1,8 @@
 package httphandler
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
@@ -19,11 +21,45 @@ func (errGenerator) Generate() ([]string, error) {
 	return nil, errors.New("generator failure")
 }
 
+func (errGenerator) GenerateDetails() ([]password.Detail, error) {
+	return nil, errors.New("generator failure")
+}
+
+func (errGenerator) Entropy() (float64, error) {
+	return 0, nil
+}
+
+func (errGenerator) CharsetSize() int {
+	return 0
+}
+
+func (errGenerator) MaxSize() int {
+	return 0
+}
+
+func (errGenerator) Stream(_ context.Context, _ int, _ func(string) error) error {
+	return errors.New("generator failure")
+}
+
+// errEntropyGenerator is a password generator stub failing to compute the
+// entropy.
+type errEntropyGenerator struct {
+	errGenerator
+}
+
+func (errEntropyGenerator) Entropy() (float64, error) {
+	return 0, errors.New("entropy failure")
+}
+
 func TestNew(t *testing.T) {
 	t.Parallel()
 
 	hh := New(nil, nil, nil, nil, nil)
 	require.NotNil(t, hh)
+	require.Equal(t, password.SourceOS, hh.source.Name())
+
+	hh = New(nil, nil, nil, nil, nil, WithSource(password.NewChaCha20Source(1)))
+	require.Equal(t, password.SourceChaCha20, hh.source.Name())
 }
 
 func TestHTTPHandler_BindHTTP(t *testing.T) {
@@ -31,7 +67,7 @@ func TestHTTPHandler_BindHTTP(t *testing.T) {
 
 	h := &HTTPHandler{}
 	got := h.BindHTTP(t.Context())
-	require.Len(t, got, 2)
+	require.Len(t, got, 15)
 }
 
 func TestHTTPHandler_handleGenUID(t *testing.T) {
@@ -103,6 +139,31 @@ func TestHTTPHandler_handlePassword(t *testing.T) {
 			params:  "?charset=0123456789&length=8&quantity=1",
 			wantErr: false,
 		},
+		{
+			name:    "valid unicode charset",
+			params:  "?charset=%CE%B1%CE%B2%CE%B3%D0%B6%E6%BC%A2",
+			wantErr: false,
+		},
+		{
+			name:    "valid charset preset",
+			params:  "?charset=base58",
+			wantErr: false,
+		},
+		{
+			name:    "valid charset expression",
+			params:  `?charset=upper+lower+digit-"0O1l"`,
+			wantErr: false,
+		},
+		{
+			name:    "seed with the deterministic mode disabled",
+			params:  "?seed=abc",
+			wantErr: true,
+		},
+		{
+			name:    "unknown charset preset",
+			params:  "?charset=upper+vowels",
+			wantErr: true,
+		},
 		{
 			name:    "invalid charset",
 			params:  "?charset=in va lid",
@@ -153,6 +214,141 @@ func TestHTTPHandler_handlePassword(t *testing.T) {
 			params:  "?quantity=0",
 			wantErr: true,
 		},
+		{
+			name:    "valid policy",
+			params:  "?length=12&min_digit=2&max_digit=4&min_lower=2",
+			wantErr: false,
+		},
+		{
+			name:    "policy minimums exceed length",
+			params:  "?length=4&min_digit=3&min_lower=3",
+			wantErr: true,
+		},
+		{
+			name:    "policy class missing from charset",
+			params:  "?min_upper=1",
+			wantErr: true,
+		},
+		{
+			name:    "policy minimum above maximum",
+			params:  "?min_digit=3&max_digit=2",
+			wantErr: true,
+		},
+		{
+			name:    "not integer policy bound",
+			params:  "?min_symbol=abc",
+			wantErr: true,
+		},
+		{
+			name:    "negative policy bound",
+			params:  "?min_lower=-1",
+			wantErr: true,
+		},
+		{
+			name:    "valid pronounceable mode",
+			params:  "?mode=pronounceable&length=12",
+			wantErr: false,
+		},
+		{
+			name:    "invalid mode",
+			params:  "?mode=unknown",
+			wantErr: true,
+		},
+		{
+			name:    "pronounceable mode without vowels",
+			params:  "?mode=pronounceable&charset=0123456789",
+			wantErr: true,
+		},
+		{
+			name:    "pronounceable mode with policy",
+			params:  "?mode=pronounceable&min_digit=1",
+			wantErr: true,
+		},
+		{
+			name:    "valid template",
+			params:  "?template=Cvcc-9999-SS",
+			wantErr: false,
+		},
+		{
+			name:    "valid template mode",
+			params:  "?mode=template&template=%5Bhex%5D%7B8%7D",
+			wantErr: false,
+		},
+		{
+			name:    "template mode without template",
+			params:  "?mode=template",
+			wantErr: true,
+		},
+		{
+			name:    "template with policy",
+			params:  "?template=Cvcc&min_digit=1",
+			wantErr: true,
+		},
+		{
+			name:    "valid constraints",
+			params:  "?max_consecutive=1&max_per_char=2&min_distinct=8&no_sequences=true&length=12",
+			wantErr: false,
+		},
+		{
+			name:    "unsatisfiable constraints",
+			params:  "?charset=ab&length=8&max_per_char=2",
+			wantErr: true,
+		},
+		{
+			name:    "negative constraint",
+			params:  "?max_consecutive=-1",
+			wantErr: true,
+		},
+		{
+			name:    "not boolean no sequences",
+			params:  "?no_sequences=yes",
+			wantErr: true,
+		},
+		{
+			name:    "valid detail",
+			params:  "?detail=true&min_digit=1",
+			wantErr: false,
+		},
+		{
+			name:    "valid no detail",
+			params:  "?detail=false",
+			wantErr: false,
+		},
+		{
+			name:    "not boolean detail",
+			params:  "?detail=maybe",
+			wantErr: true,
+		},
+		{
+			name:    "valid exclude ambiguous",
+			params:  "?exclude_ambiguous=true",
+			wantErr: false,
+		},
+		{
+			name:    "exclude ambiguous empty charset",
+			params:  "?exclude_ambiguous=true&charset=0O1l",
+			wantErr: true,
+		},
+		{
+			name:    "not boolean exclude ambiguous",
+			params:  "?exclude_ambiguous=2",
+			wantErr: true,
+		},
+		{
+			name:    "valid unique",
+			params:  "?unique=true&charset=01&length=4&quantity=8",
+			wantErr: false,
+		},
+		{
+			name:    "unique keyspace too small",
+			params:  "?unique=true&charset=01&length=4&quantity=9",
+			wantErr: true,
+		},
+		{
+			name:    "not boolean unique",
+			params:  "?unique=1x",
+			wantErr: true,
+		},
 		{
 			name:    "overflow length",
 			params:  "?length=99999999999999999999",
@@ -184,19 +380,139 @@ func TestHTTPHandler_handlePassword(t *testing.T) {
 			} else {
 				require.Equal(t, http.StatusOK, resp.StatusCode)
 				require.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+				require.NotEmpty(t, resp.Header.Get(headerEntropy))
 				require.NotEmpty(t, string(body))
 			}
 		})
 	}
 }
 
+func TestHTTPHandler_handlePassword_entropy(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	h := New(nil, nil, nil, val, password.New("0123456789abcdef", 8, 1))
+
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+
+	h.handlePassword(rr, req)
+
+	resp := rr.Result()
+	require.NotNil(t, resp)
+
+	defer func() {
+		err := resp.Body.Close()
+		require.NoError(t, err, "error closing resp.Body")
+	}()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "32.00", resp.Header.Get(headerEntropy))
+	require.Equal(t, "16", resp.Header.Get(headerCharsetSize))
+}
+
+func TestHTTPHandler_handlePassword_detail(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	h := New(nil, nil, nil, val, password.New("0123456789abcdef", 8, 1))
+
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "/?detail=true&quantity=2&min_digit=8", nil)
+
+	h.handlePassword(rr, req)
+
+	resp := rr.Result()
+	require.NotNil(t, resp)
+
+	defer func() {
+		err := resp.Body.Close()
+		require.NoError(t, err, "error closing resp.Body")
+	}()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, _ := io.ReadAll(resp.Body)
+
+	var data []password.Detail
+
+	require.NoError(t, json.Unmarshal(body, &data))
+	require.Len(t, data, 2)
+
+	for _, d := range data {
+		require.Len(t, d.Password, 8)
+		require.Equal(t, 16, d.CharsetSize)
+		require.Equal(t, "100000000", d.Keyspace)
+		require.Equal(t, password.ModeRandom, d.Mode)
+		require.NotNil(t, d.Policy)
+		require.Equal(t, 8, d.Policy.MinDigit)
+	}
+}
+
+func TestHTTPHandler_handlePassword_template(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	h := New(nil, nil, nil, val, password.New("0123456789abcdef", 8, 1))
+
+	serve := func(params string) (int, []byte) {
+		t.Helper()
+
+		rr := httptest.NewRecorder()
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "/"+params, nil)
+
+		h.handlePassword(rr, req)
+
+		resp := rr.Result()
+
+		defer func() {
+			err := resp.Body.Close()
+			require.NoError(t, err, "error closing resp.Body")
+		}()
+
+		body, _ := io.ReadAll(resp.Body)
+
+		return resp.StatusCode, body
+	}
+
+	status, body := serve("?template=A%7B2%7D-9%7B4%7D&detail=true&quantity=3")
+	require.Equal(t, http.StatusOK, status)
+
+	var data []password.Detail
+
+	require.NoError(t, json.Unmarshal(body, &data))
+	require.Len(t, data, 3)
+
+	for _, d := range data {
+		require.Regexp(t, `^[A-Z]{2}-[0-9]{4}$`, d.Password)
+		require.Equal(t, password.ModeTemplate, d.Mode)
+		require.Equal(t, "6760000", d.Keyspace)
+	}
+
+	status, body = serve("?template=Cvcc-9999-%5BSS")
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Contains(t, string(body), "position 11")
+
+	status, body = serve("?template=9%7B4%7D&min_digit=1")
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Contains(t, string(body), "character-class policies are not supported in template mode")
+
+	status, body = serve("?charset=0O1l&exclude_ambiguous=true&min_digit=1")
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Contains(t, string(body), "no characters left")
+	require.NotContains(t, string(body), "cannot be satisfied")
+}
+
 func TestHTTPHandler_handlePassword_generateError(t *testing.T) {
 	t.Parallel()
 
 	val, _ := validator.New("json")
 
 	h := New(nil, nil, nil, val, password.New("0123456789abcdefghijklmnopqrstuvwxyz", 16, 3))
-	h.newPassword = func(_ string, _, _ int) generator {
+	h.newPassword = func(_ string, _, _ int, _ ...password.Option) generator {
 		return errGenerator{}
 	}
 
@@ -215,3 +531,29 @@ func TestHTTPHandler_handlePassword_generateError(t *testing.T) {
 
 	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
 }
+
+func TestHTTPHandler_handlePassword_entropyError(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	h := New(nil, nil, nil, val, password.New("0123456789abcdefghijklmnopqrstuvwxyz", 16, 3))
+	h.newPassword = func(_ string, _, _ int, _ ...password.Option) generator {
+		return errEntropyGenerator{}
+	}
+
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+
+	h.handlePassword(rr, req)
+
+	resp := rr.Result()
+	require.NotNil(t, resp)
+
+	defer func() {
+		err := resp.Body.Close()
+		require.NoError(t, err, "error closing resp.Body")
+	}()
+
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
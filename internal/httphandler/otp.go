@@ -0,0 +1,187 @@
+package httphandler
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/tecnickcom/nurago/pkg/httputil"
+	"github.com/tecnickcom/rndpwd/internal/password"
+)
+
+// maxOTPCodesBodySize is the maximum size in bytes of the /totp/codes request
+// body.
+const maxOTPCodesBodySize = 4 << 10
+
+// otpCodesRequest is the body of the /totp/codes request. The unset settings
+// default to the ones of the /totp route.
+type otpCodesRequest struct {
+	// Secret is the base32 shared secret.
+	Secret string `json:"secret" validate:"required,max=256"`
+
+	// Type is the one-time password type, totp or hotp.
+	Type string `json:"type" validate:"omitempty,oneof=totp hotp"`
+
+	// Algorithm is the HMAC algorithm.
+	Algorithm string `json:"algorithm" validate:"omitempty,oneof=SHA1 SHA256 SHA512"`
+
+	// Digits is the number of digits of the codes.
+	Digits int `json:"digits" validate:"omitempty,min=6,max=8"`
+
+	// Period is the validity of the TOTP codes in seconds.
+	Period int `json:"period" validate:"omitempty,min=1,max=3600"`
+
+	// Counter is the current HOTP counter.
+	Counter int64 `json:"counter" validate:"min=0"`
+
+	// Time is the Unix time of the current TOTP code (default now).
+	Time *int64 `json:"time" validate:"omitempty,min=0"`
+
+	// Window is the number of adjacent codes on each side of the current one.
+	Window int `json:"window" validate:"min=0,max=10"`
+}
+
+// otpCodesResponse is the body of the /totp/codes response.
+type otpCodesResponse struct {
+	Type      string             `json:"type"`
+	Algorithm string             `json:"algorithm"`
+	Digits    int                `json:"digits"`
+	Period    int                `json:"period,omitempty"`
+	Time      int64              `json:"time,omitempty"`
+	Codes     []password.OTPCode `json:"codes"`
+}
+
+// WithOTP sets the default settings of the /totp route.
+func WithOTP(o *password.OTP) Option {
+	return func(h *HTTPHandler) {
+		h.otp = o
+	}
+}
+
+// otpParams returns the query parameters accepted by the /totp route.
+func otpParams() map[string]paramType {
+	return map[string]paramType{
+		"type":      paramString,
+		"issuer":    paramString,
+		"account":   paramString,
+		"algorithm": paramString,
+		"digits":    paramInt,
+		"period":    paramInt,
+		"counter":   paramInt,
+	}
+}
+
+func (h *HTTPHandler) handleOTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if !validQueryParams(query, otpParams()) {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, "invalid query parameter")
+		return
+	}
+
+	// URL query parameters can override the config settings
+	o := password.NewOTP(
+		query.Get("account"),
+		password.WithOTPType(httputil.QueryStringOrDefault(query, "type", h.otp.Type)),
+		password.WithOTPIssuer(httputil.QueryStringOrDefault(query, "issuer", h.otp.Issuer)),
+		password.WithOTPAlgorithm(httputil.QueryStringOrDefault(query, "algorithm", h.otp.Algorithm)),
+		password.WithOTPDigits(httputil.QueryIntOrDefault(query, "digits", h.otp.Digits)),
+		password.WithOTPPeriod(httputil.QueryIntOrDefault(query, "period", h.otp.Period)),
+		password.WithOTPCounter(queryInt64OrDefault(query, "counter", h.otp.Counter)),
+		password.WithOTPSource(h.source),
+	)
+
+	err := h.val.ValidateStruct(o)
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	key, err := o.Generate()
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusInternalServerError, "failed generating the OTP secret")
+		return
+	}
+
+	h.httpres.SendJSON(r.Context(), w, http.StatusOK, key)
+}
+
+func (h *HTTPHandler) handleOTPCodes(w http.ResponseWriter, r *http.Request) {
+	var req otpCodesRequest
+
+	err := decodeJSONBody(w, r, maxOTPCodesBodySize, &req)
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	err = h.val.ValidateStruct(req)
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	o := password.NewOTP(
+		"",
+		password.WithOTPType(stringOrDefault(req.Type, h.otp.Type)),
+		password.WithOTPAlgorithm(stringOrDefault(req.Algorithm, h.otp.Algorithm)),
+		password.WithOTPDigits(intOrDefault(req.Digits, h.otp.Digits)),
+		password.WithOTPPeriod(intOrDefault(req.Period, h.otp.Period)),
+		password.WithOTPCounter(req.Counter),
+	)
+
+	at := time.Now()
+	if req.Time != nil {
+		at = time.Unix(*req.Time, 0)
+	}
+
+	codes, err := o.Codes(req.Secret, at, req.Window)
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := otpCodesResponse{
+		Type:      o.Type,
+		Algorithm: o.Algorithm,
+		Digits:    o.Digits,
+		Codes:     codes,
+	}
+
+	if o.Type == password.OTPTypeTOTP {
+		res.Period = o.Period
+		res.Time = at.Unix()
+	}
+
+	h.httpres.SendJSON(r.Context(), w, http.StatusOK, res)
+}
+
+// queryInt64OrDefault returns the 64-bit integer value of the query parameter,
+// or the default value when the parameter is missing or invalid.
+func queryInt64OrDefault(query url.Values, key string, defaultValue int64) int64 {
+	v, err := strconv.ParseInt(query.Get(key), 10, 64)
+	if err != nil {
+		return defaultValue
+	}
+
+	return v
+}
+
+// stringOrDefault returns the value, or the default value when empty.
+func stringOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+
+	return value
+}
+
+// intOrDefault returns the value, or the default value when zero.
+func intOrDefault(value, defaultValue int) int {
+	if value == 0 {
+		return defaultValue
+	}
+
+	return value
+}
@@ -0,0 +1,246 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/rndpwd/internal/password"
+	"github.com/tecnickcom/rndpwd/internal/validator"
+)
+
+func TestHTTPHandler_handleOTP(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	h := New(
+		nil,
+		nil,
+		nil,
+		val,
+		password.New("0123456789abcdefghijklmnopqrstuvwxyz", 16, 3),
+		WithOTP(password.NewOTP("", password.WithOTPIssuer("Example"))),
+	)
+
+	tests := []struct {
+		name       string
+		params     string
+		wantErr    bool
+		wantSecret int
+		wantURI    string
+	}{
+		{
+			name:       "default totp",
+			params:     "?account=alice",
+			wantSecret: 32,
+			wantURI:    "otpauth://totp/Example:alice?algorithm=SHA1&digits=6&issuer=Example&period=30&secret=",
+		},
+		{
+			name:       "all params",
+			params:     "?account=svc&issuer=Acme&type=hotp&algorithm=SHA256&digits=8&counter=3",
+			wantSecret: 52,
+			wantURI:    "otpauth://hotp/Acme:svc?algorithm=SHA256&counter=3&digits=8&issuer=Acme&secret=",
+		},
+		{
+			name:       "sha512 totp",
+			params:     "?account=svc&algorithm=SHA512&period=60",
+			wantSecret: 103,
+			wantURI:    "otpauth://totp/Example:svc?algorithm=SHA512&digits=6&issuer=Example&period=60&secret=",
+		},
+		{
+			name:    "missing account",
+			params:  "",
+			wantErr: true,
+		},
+		{
+			name:    "colon in account",
+			params:  "?account=a:b",
+			wantErr: true,
+		},
+		{
+			name:    "unknown algorithm",
+			params:  "?account=alice&algorithm=MD5",
+			wantErr: true,
+		},
+		{
+			name:    "too many digits",
+			params:  "?account=alice&digits=9",
+			wantErr: true,
+		},
+		{
+			name:    "zero period",
+			params:  "?account=alice&period=0",
+			wantErr: true,
+		},
+		{
+			name:    "negative counter",
+			params:  "?account=alice&type=hotp&counter=-1",
+			wantErr: true,
+		},
+		{
+			name:    "unknown parameter",
+			params:  "?account=alice&length=8",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "/totp"+tt.params, nil)
+
+			h.handleOTP(rr, req)
+
+			resp := rr.Result()
+			require.NotNil(t, resp)
+
+			defer func() {
+				err := resp.Body.Close()
+				require.NoError(t, err, "error closing resp.Body")
+			}()
+
+			if tt.wantErr {
+				require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				return
+			}
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			body, _ := io.ReadAll(resp.Body)
+
+			var data password.OTPKey
+
+			require.NoError(t, json.Unmarshal(body, &data))
+			require.Len(t, data.Secret, tt.wantSecret)
+			require.Equal(t, tt.wantURI+data.Secret, data.URI)
+		})
+	}
+}
+
+func TestHTTPHandler_handleOTPCodes(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	h := New(
+		nil,
+		nil,
+		nil,
+		val,
+		password.New("0123456789abcdefghijklmnopqrstuvwxyz", 16, 3),
+	)
+
+	// base32 of the RFC 4226 and RFC 6238 SHA1 seed "12345678901234567890"
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	tests := []struct {
+		name      string
+		body      string
+		wantCode  int
+		wantCodes []string
+	}{
+		{
+			name:      "totp",
+			body:      `{"secret":"` + secret + `","digits":8,"time":59}`,
+			wantCode:  http.StatusOK,
+			wantCodes: []string{"94287082"},
+		},
+		{
+			name:      "totp window",
+			body:      `{"secret":"` + secret + `","time":1111111109,"window":1}`,
+			wantCode:  http.StatusOK,
+			wantCodes: []string{"731029", "081804", "050471"},
+		},
+		{
+			name:      "hotp window",
+			body:      `{"secret":"` + secret + `","type":"hotp","counter":1,"window":1}`,
+			wantCode:  http.StatusOK,
+			wantCodes: []string{"755224", "287082", "359152"},
+		},
+		{
+			name:     "current time",
+			body:     `{"secret":"` + secret + `"}`,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "invalid secret",
+			body:     `{"secret":"not base32!"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "missing secret",
+			body:     `{"time":59}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "large window",
+			body:     `{"secret":"` + secret + `","window":11}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown algorithm",
+			body:     `{"secret":"` + secret + `","algorithm":"MD5"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown field",
+			body:     `{"secret":"` + secret + `","account":"alice"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid JSON",
+			body:     `{"secret":`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, "/totp/codes", strings.NewReader(tt.body))
+
+			h.handleOTPCodes(rr, req)
+
+			resp := rr.Result()
+			require.NotNil(t, resp)
+
+			defer func() {
+				err := resp.Body.Close()
+				require.NoError(t, err, "error closing resp.Body")
+			}()
+
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			body, _ := io.ReadAll(resp.Body)
+
+			var data otpCodesResponse
+
+			require.NoError(t, json.Unmarshal(body, &data))
+
+			if tt.wantCodes == nil {
+				require.Len(t, data.Codes, 1)
+				return
+			}
+
+			codes := make([]string, len(data.Codes))
+			for i, c := range data.Codes {
+				codes[i] = c.Code
+			}
+
+			require.Equal(t, tt.wantCodes, codes)
+		})
+	}
+}
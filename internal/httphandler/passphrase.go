@@ -0,0 +1,81 @@
+package httphandler
+
+import (
+	"net/http"
+
+	"github.com/tecnickcom/nurago/pkg/httputil"
+	"github.com/tecnickcom/rndpwd/internal/password"
+)
+
+// passphraseGenerator produces random passphrases.
+type passphraseGenerator interface {
+	Generate() ([]string, error)
+	Entropy() float64
+	WordlistSize() int
+}
+
+// passphraseResponse is the body of the /passphrase response.
+type passphraseResponse struct {
+	Wordlist     string   `json:"wordlist"`
+	WordlistSize int      `json:"wordlist_size"`
+	Words        int      `json:"words"`
+	Entropy      float64  `json:"entropy"`
+	Passphrases  []string `json:"passphrases"`
+}
+
+// passphraseParams returns the query parameters accepted by the /passphrase route.
+func passphraseParams() map[string]paramType {
+	return map[string]paramType{
+		"wordlist":   paramString,
+		"words":      paramInt,
+		"separator":  paramString,
+		"capitalize": paramString,
+		"digit":      paramBool,
+		"symbol":     paramBool,
+		"quantity":   paramInt,
+	}
+}
+
+func (h *HTTPHandler) handlePassphrase(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if !validQueryParams(query, passphraseParams()) {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, "invalid query parameter")
+		return
+	}
+
+	// URL query parameters can override the config settings
+	wordlist := httputil.QueryStringOrDefault(query, "wordlist", h.passphrase.Wordlist)
+	words := httputil.QueryIntOrDefault(query, "words", h.passphrase.Words)
+
+	p := h.newPassphrase(
+		wordlist,
+		words,
+		httputil.QueryIntOrDefault(query, "quantity", h.passphrase.Quantity),
+		password.WithSeparator(httputil.QueryStringOrDefault(query, "separator", h.passphrase.Separator)),
+		password.WithCapitalize(httputil.QueryStringOrDefault(query, "capitalize", h.passphrase.Capitalize)),
+		password.WithDigit(queryBoolOrDefault(query, "digit", h.passphrase.Digit)),
+		password.WithSymbol(queryBoolOrDefault(query, "symbol", h.passphrase.Symbol)),
+		password.WithPassphraseSource(h.source),
+	)
+
+	err := h.val.ValidateStruct(p)
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	lst, err := p.Generate()
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusInternalServerError, "failed generating passphrases")
+		return
+	}
+
+	h.httpres.SendJSON(r.Context(), w, http.StatusOK, passphraseResponse{
+		Wordlist:     wordlist,
+		WordlistSize: p.WordlistSize(),
+		Words:        words,
+		Entropy:      p.Entropy(),
+		Passphrases:  lst,
+	})
+}
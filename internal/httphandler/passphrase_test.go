@@ -0,0 +1,185 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/rndpwd/internal/password"
+	"github.com/tecnickcom/rndpwd/internal/validator"
+)
+
+// errPassphraseGenerator is a passphrase generator stub that always fails.
+type errPassphraseGenerator struct{}
+
+func (errPassphraseGenerator) Generate() ([]string, error) {
+	return nil, errors.New("generator failure")
+}
+
+func (errPassphraseGenerator) Entropy() float64 {
+	return 0
+}
+
+func (errPassphraseGenerator) WordlistSize() int {
+	return 0
+}
+
+func TestHTTPHandler_handlePassphrase(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	h := New(
+		nil,
+		nil,
+		nil,
+		val,
+		password.New("0123456789abcdefghijklmnopqrstuvwxyz", 16, 3),
+		WithPassphrase(password.NewPassphrase(password.WordlistEFFLarge, 5, 2)),
+	)
+
+	tests := []struct {
+		name      string
+		params    string
+		wantErr   bool
+		wantWords int
+		wantQty   int
+		wantSep   string
+	}{
+		{
+			name:      "valid empty",
+			params:    "",
+			wantWords: 5,
+			wantQty:   2,
+			wantSep:   " ",
+		},
+		{
+			name:      "valid all params",
+			params:    "?wordlist=eff_short&words=4&separator=.&capitalize=random&digit=true&symbol=1&quantity=3",
+			wantWords: 4,
+			wantQty:   3,
+			wantSep:   ".",
+		},
+		{
+			name:    "invalid wordlist",
+			params:  "?wordlist=unknown",
+			wantErr: true,
+		},
+		{
+			name:    "too many words",
+			params:  "?words=33",
+			wantErr: true,
+		},
+		{
+			name:    "not integer words",
+			params:  "?words=abc",
+			wantErr: true,
+		},
+		{
+			name:    "ambiguous separator",
+			params:  "?separator=-",
+			wantErr: true,
+		},
+		{
+			name:    "letter separator",
+			params:  "?separator=x",
+			wantErr: true,
+		},
+		{
+			name:    "too long separator",
+			params:  "?separator=....",
+			wantErr: true,
+		},
+		{
+			name:    "invalid capitalize",
+			params:  "?capitalize=title",
+			wantErr: true,
+		},
+		{
+			name:    "not boolean digit",
+			params:  "?digit=maybe",
+			wantErr: true,
+		},
+		{
+			name:    "zero quantity",
+			params:  "?quantity=0",
+			wantErr: true,
+		},
+		{
+			name:    "unknown parameter",
+			params:  "?length=3",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "/"+tt.params, nil)
+
+			h.handlePassphrase(rr, req)
+
+			resp := rr.Result()
+			require.NotNil(t, resp)
+
+			defer func() {
+				err := resp.Body.Close()
+				require.NoError(t, err, "error closing resp.Body")
+			}()
+
+			body, _ := io.ReadAll(resp.Body)
+
+			if tt.wantErr {
+				require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				return
+			}
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+
+			var data passphraseResponse
+
+			require.NoError(t, json.Unmarshal(body, &data))
+			require.Equal(t, tt.wantWords, data.Words)
+			require.Positive(t, data.Entropy)
+			require.Len(t, data.Passphrases, tt.wantQty)
+
+			for _, pp := range data.Passphrases {
+				require.Len(t, strings.Split(pp, tt.wantSep), tt.wantWords)
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_handlePassphrase_generateError(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	h := New(nil, nil, nil, val, password.New("0123456789abcdefghijklmnopqrstuvwxyz", 16, 3))
+	h.newPassphrase = func(_ string, _, _ int, _ ...password.PassphraseOption) passphraseGenerator {
+		return errPassphraseGenerator{}
+	}
+
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+
+	h.handlePassphrase(rr, req)
+
+	resp := rr.Result()
+	require.NotNil(t, resp)
+
+	defer func() {
+		err := resp.Body.Close()
+		require.NoError(t, err, "error closing resp.Body")
+	}()
+
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
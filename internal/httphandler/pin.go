@@ -0,0 +1,68 @@
+package httphandler
+
+import (
+	"net/http"
+
+	"github.com/tecnickcom/nurago/pkg/httputil"
+	"github.com/tecnickcom/rndpwd/internal/password"
+)
+
+// pinGenerator produces random PINs.
+type pinGenerator interface {
+	Generate() ([]string, error)
+	Entropy() float64
+	Keyspace() int64
+}
+
+// pinResponse is the body of the /pin response.
+type pinResponse struct {
+	Length   int      `json:"length"`
+	Keyspace int64    `json:"keyspace"`
+	Entropy  float64  `json:"entropy"`
+	PINs     []string `json:"pins"`
+}
+
+// pinParams returns the query parameters accepted by the /pin route.
+func pinParams() map[string]paramType {
+	return map[string]paramType{
+		"length":   paramInt,
+		"quantity": paramInt,
+	}
+}
+
+func (h *HTTPHandler) handlePIN(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if !validQueryParams(query, pinParams()) {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, "invalid query parameter")
+		return
+	}
+
+	// URL query parameters can override the config settings
+	length := httputil.QueryIntOrDefault(query, "length", h.pin.Length)
+
+	p := h.newPIN(
+		length,
+		httputil.QueryIntOrDefault(query, "quantity", h.pin.Quantity),
+		password.WithPINSource(h.source),
+	)
+
+	err := h.val.ValidateStruct(p)
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	lst, err := p.Generate()
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusInternalServerError, "failed generating PINs")
+		return
+	}
+
+	h.httpres.SendJSON(r.Context(), w, http.StatusOK, pinResponse{
+		Length:   length,
+		Keyspace: p.Keyspace(),
+		Entropy:  p.Entropy(),
+		PINs:     lst,
+	})
+}
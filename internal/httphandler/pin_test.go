@@ -0,0 +1,158 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/rndpwd/internal/password"
+	"github.com/tecnickcom/rndpwd/internal/validator"
+)
+
+// errPINGenerator is a PIN generator stub that always fails.
+type errPINGenerator struct{}
+
+func (errPINGenerator) Generate() ([]string, error) {
+	return nil, errors.New("generator failure")
+}
+
+func (errPINGenerator) Entropy() float64 {
+	return 0
+}
+
+func (errPINGenerator) Keyspace() int64 {
+	return 0
+}
+
+func TestHTTPHandler_handlePIN(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	h := New(
+		nil,
+		nil,
+		nil,
+		val,
+		password.New("0123456789abcdefghijklmnopqrstuvwxyz", 16, 3),
+		WithPIN(password.NewPIN(4, 2)),
+	)
+
+	tests := []struct {
+		name       string
+		params     string
+		wantErr    bool
+		wantLength int
+		wantQty    int
+	}{
+		{
+			name:       "valid empty",
+			params:     "",
+			wantLength: 4,
+			wantQty:    2,
+		},
+		{
+			name:       "valid all params",
+			params:     "?length=8&quantity=5",
+			wantLength: 8,
+			wantQty:    5,
+		},
+		{
+			name:    "too short",
+			params:  "?length=3",
+			wantErr: true,
+		},
+		{
+			name:    "too long",
+			params:  "?length=13",
+			wantErr: true,
+		},
+		{
+			name:    "not integer length",
+			params:  "?length=abc",
+			wantErr: true,
+		},
+		{
+			name:    "zero quantity",
+			params:  "?quantity=0",
+			wantErr: true,
+		},
+		{
+			name:    "unknown parameter",
+			params:  "?charset=0123",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "/"+tt.params, nil)
+
+			h.handlePIN(rr, req)
+
+			resp := rr.Result()
+			require.NotNil(t, resp)
+
+			defer func() {
+				err := resp.Body.Close()
+				require.NoError(t, err, "error closing resp.Body")
+			}()
+
+			body, _ := io.ReadAll(resp.Body)
+
+			if tt.wantErr {
+				require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				return
+			}
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+
+			var data pinResponse
+
+			require.NoError(t, json.Unmarshal(body, &data))
+			require.Equal(t, tt.wantLength, data.Length)
+			require.Positive(t, data.Keyspace)
+			require.Positive(t, data.Entropy)
+			require.Len(t, data.PINs, tt.wantQty)
+
+			for _, pin := range data.PINs {
+				require.Len(t, pin, tt.wantLength)
+				require.False(t, password.IsWeakPIN(pin), pin)
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_handlePIN_generateError(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	h := New(nil, nil, nil, val, password.New("0123456789abcdefghijklmnopqrstuvwxyz", 16, 3))
+	h.newPIN = func(_, _ int, _ ...password.PINOption) pinGenerator {
+		return errPINGenerator{}
+	}
+
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+
+	h.handlePIN(rr, req)
+
+	resp := rr.Result()
+	require.NotNil(t, resp)
+
+	defer func() {
+		err := resp.Body.Close()
+		require.NoError(t, err, "error closing resp.Body")
+	}()
+
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
@@ -0,0 +1,105 @@
+package httphandler
+
+import (
+	"maps"
+	"net/http"
+
+	"github.com/tecnickcom/nurago/pkg/httputil"
+	"github.com/tecnickcom/rndpwd/internal/password"
+	"github.com/tecnickcom/rndpwd/internal/pwhash"
+)
+
+// recoveryCode is a recovery code along with its hash.
+type recoveryCode struct {
+	Code string `json:"code"`
+	Hash string `json:"hash"`
+}
+
+// recoveryResponse is the body of the /recovery-codes response.
+type recoveryResponse struct {
+	Algorithm string         `json:"algorithm"`
+	Entropy   float64        `json:"entropy"`
+	Codes     []recoveryCode `json:"codes"`
+}
+
+// WithRecoveryCodes sets the default settings of the /recovery-codes route and
+// the default algorithm of the code hashes (default pwhash.Argon2id).
+func WithRecoveryCodes(rc *password.RecoveryCodes, hashAlg string) Option {
+	return func(h *HTTPHandler) {
+		h.recovery = rc
+		h.recoveryHash = hashAlg
+	}
+}
+
+// recoveryParams returns the query parameters accepted by the /recovery-codes
+// route.
+func recoveryParams() map[string]paramType {
+	params := map[string]paramType{
+		"groups":     paramInt,
+		"group_size": paramInt,
+		"quantity":   paramInt,
+	}
+
+	maps.Copy(params, hashParams())
+
+	return params
+}
+
+func (h *HTTPHandler) handleRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if !validQueryParams(query, recoveryParams()) {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, "invalid query parameter")
+		return
+	}
+
+	// URL query parameters can override the config settings
+	rc := password.NewRecoveryCodes(
+		httputil.QueryIntOrDefault(query, "groups", h.recovery.Groups),
+		httputil.QueryIntOrDefault(query, "group_size", h.recovery.GroupSize),
+		httputil.QueryIntOrDefault(query, "quantity", h.recovery.Quantity),
+		password.WithRecoverySource(h.source),
+	)
+
+	err := h.val.ValidateStruct(rc)
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// the codes are always hashed, with the same work factors and limits as
+	// the /password hashes
+	if !query.Has("hash") {
+		query.Set("hash", h.recoveryHash)
+	}
+
+	alg, hp, err := h.hashRequest(query, rc.Quantity, rc.Size())
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	lst, err := rc.Generate()
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusInternalServerError, "failed generating recovery codes")
+		return
+	}
+
+	codes := make([]recoveryCode, len(lst))
+
+	for i, code := range lst {
+		codes[i].Code = code
+
+		codes[i].Hash, err = pwhash.Hash(alg, hp, code)
+		if err != nil {
+			h.httpres.SendJSON(r.Context(), w, http.StatusInternalServerError, "failed hashing recovery codes")
+			return
+		}
+	}
+
+	h.httpres.SendJSON(r.Context(), w, http.StatusOK, recoveryResponse{
+		Algorithm: alg,
+		Entropy:   rc.Entropy(),
+		Codes:     codes,
+	})
+}
@@ -0,0 +1,132 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/rndpwd/internal/password"
+	"github.com/tecnickcom/rndpwd/internal/pwhash"
+	"github.com/tecnickcom/rndpwd/internal/validator"
+)
+
+func TestHTTPHandler_handleRecoveryCodes(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	// cheap work factors keep the test fast
+	defaults := pwhash.Params{Cost: 4, Memory: 64, Time: 1, Threads: 1, LN: 4, R: 8, P: 1, Iterations: 1000, Rounds: 1000}
+
+	h := New(
+		nil,
+		nil,
+		nil,
+		val,
+		password.New("0123456789abcdefghijklmnopqrstuvwxyz", 16, 3),
+		WithPasswordHash(defaults, pwhash.DefaultLimits(), 10),
+		WithRecoveryCodes(password.NewRecoveryCodes(3, 4, 8), pwhash.Argon2id),
+	)
+
+	tests := []struct {
+		name       string
+		params     string
+		wantCode   int
+		wantAlg    string
+		wantQty    int
+		wantLength int
+	}{
+		{
+			name:       "valid empty",
+			params:     "",
+			wantCode:   http.StatusOK,
+			wantAlg:    pwhash.Argon2id,
+			wantQty:    8,
+			wantLength: 14,
+		},
+		{
+			name:       "valid all params",
+			params:     "?groups=2&group_size=5&quantity=3&hash=bcrypt&hash_cost=5",
+			wantCode:   http.StatusOK,
+			wantAlg:    pwhash.Bcrypt,
+			wantQty:    3,
+			wantLength: 11,
+		},
+		{
+			name:     "too many to hash",
+			params:   "?quantity=11",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "too many groups",
+			params:   "?groups=9",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "too short groups",
+			params:   "?group_size=3",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown hash",
+			params:   "?hash=md5",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "hash parameter of another algorithm",
+			params:   "?hash_cost=5",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown parameter",
+			params:   "?length=8",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "/recovery-codes"+tt.params, nil)
+
+			h.handleRecoveryCodes(rr, req)
+
+			resp := rr.Result()
+			require.NotNil(t, resp)
+
+			defer func() {
+				err := resp.Body.Close()
+				require.NoError(t, err, "error closing resp.Body")
+			}()
+
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			body, _ := io.ReadAll(resp.Body)
+
+			var data recoveryResponse
+
+			require.NoError(t, json.Unmarshal(body, &data))
+			require.Equal(t, tt.wantAlg, data.Algorithm)
+			require.Len(t, data.Codes, tt.wantQty)
+
+			for _, c := range data.Codes {
+				require.Len(t, c.Code, tt.wantLength)
+				require.Equal(t, strings.ToLower(c.Code), c.Code)
+
+				res, err := pwhash.Verify(c.Code, c.Hash, pwhash.DefaultLimits(), pwhash.Params{})
+				require.NoError(t, err)
+				require.True(t, res.Match)
+			}
+		})
+	}
+}
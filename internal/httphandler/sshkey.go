@@ -0,0 +1,141 @@
+package httphandler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/tecnickcom/rndpwd/internal/password"
+	"github.com/tecnickcom/rndpwd/internal/sshkey"
+)
+
+// DefaultSSHKeyMaxConcurrent is the default maximum number of SSH key pairs
+// generated at the same time.
+const DefaultSSHKeyMaxConcurrent = 2
+
+// maxSSHKeyBodySize is the maximum size in bytes of the /sshkey request body.
+const maxSSHKeyBodySize = 4 << 10
+
+// sshKeyRequest is the body of the /sshkey request. All the fields are
+// optional.
+type sshKeyRequest struct {
+	// Type is the key type (default the sshkey.type configuration).
+	Type string `json:"type" validate:"omitempty,oneof=ed25519 ecdsa-p256 ecdsa-p384 rsa"`
+
+	// Bits is the RSA key size (default the sshkey.rsa_bits configuration).
+	Bits int `json:"bits" validate:"omitempty,oneof=3072 4096"`
+
+	// Comment is stored in the private key and appended to the
+	// authorized_keys line.
+	Comment string `json:"comment" validate:"max=256,printascii"`
+
+	// Passphrase encrypts the private key.
+	Passphrase string `json:"passphrase" validate:"max=1024,excluded_with=GeneratePassphrase"`
+
+	// GeneratePassphrase encrypts the private key with a passphrase generated
+	// with the passphrase configuration, and returns it.
+	GeneratePassphrase bool `json:"generate_passphrase"`
+}
+
+// sshKeyResponse is the body of the /sshkey response.
+type sshKeyResponse struct {
+	*sshkey.KeyPair
+
+	// Passphrase is the generated passphrase of the private key.
+	Passphrase string `json:"passphrase,omitempty"`
+}
+
+// WithSSHKey sets the default settings of the /sshkey route, the maximum RSA
+// key size and the maximum number of key pairs generated at the same time
+// (default ed25519, sshkey.MaxRSABits and DefaultSSHKeyMaxConcurrent).
+func WithSSHKey(g *sshkey.Generator, maxRSABits, maxConcurrent int) Option {
+	return func(h *HTTPHandler) {
+		h.sshKey = g
+		h.sshMaxRSABits = maxRSABits
+		h.sshMaxConcurrent = maxConcurrent
+	}
+}
+
+// handleSSHKey generates an SSH key pair. The key generation is CPU-heavy, so
+// the requests beyond the concurrency limit are rejected rather than queued.
+func (h *HTTPHandler) handleSSHKey(w http.ResponseWriter, r *http.Request) {
+	var req sshKeyRequest
+
+	err := decodeJSONBody(w, r, maxSSHKeyBodySize, &req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	err = h.val.ValidateStruct(req)
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// the request fields can override the config settings
+	g := sshkey.New(stringOrDefault(req.Type, h.sshKey.Type), intOrDefault(req.Bits, h.sshKey.Bits), stringOrDefault(req.Comment, h.sshKey.Comment))
+
+	if g.Type == sshkey.TypeRSA && g.Bits > h.sshMaxRSABits {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, fmt.Sprintf("the RSA key size must be at most %d bits", h.sshMaxRSABits))
+		return
+	}
+
+	passphrase := req.Passphrase
+
+	if req.GeneratePassphrase {
+		passphrase, err = h.sshKeyPassphrase()
+		if err != nil {
+			h.httpres.SendJSON(r.Context(), w, http.StatusInternalServerError, "failed generating the passphrase")
+			return
+		}
+	}
+
+	select {
+	case h.sshSlots <- struct{}{}:
+		defer func() { <-h.sshSlots }()
+	default:
+		h.httpres.SendJSON(r.Context(), w, http.StatusServiceUnavailable, "too many concurrent SSH key generations")
+		return
+	}
+
+	kp, err := g.Generate([]byte(passphrase))
+	if errors.Is(err, sshkey.ErrInvalid) {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusInternalServerError, "failed generating the SSH key pair")
+		return
+	}
+
+	resp := sshKeyResponse{KeyPair: kp}
+
+	if req.GeneratePassphrase {
+		resp.Passphrase = passphrase
+	}
+
+	h.httpres.SendJSON(r.Context(), w, http.StatusOK, resp)
+}
+
+// sshKeyPassphrase returns a passphrase generated with the default /passphrase
+// settings.
+func (h *HTTPHandler) sshKeyPassphrase() (string, error) {
+	lst, err := h.newPassphrase(
+		h.passphrase.Wordlist,
+		h.passphrase.Words,
+		1,
+		password.WithSeparator(h.passphrase.Separator),
+		password.WithCapitalize(h.passphrase.Capitalize),
+		password.WithDigit(h.passphrase.Digit),
+		password.WithSymbol(h.passphrase.Symbol),
+		password.WithPassphraseSource(h.source),
+	).Generate()
+	if err != nil {
+		return "", err //nolint:wrapcheck
+	}
+
+	return lst[0], nil
+}
@@ -0,0 +1,212 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/rndpwd/internal/password"
+	"github.com/tecnickcom/rndpwd/internal/sshkey"
+	"github.com/tecnickcom/rndpwd/internal/validator"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestHTTPHandler_handleSSHKey(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	h := New(
+		nil,
+		nil,
+		nil,
+		val,
+		password.New("0123456789abcdefghijklmnopqrstuvwxyz", 16, 3),
+		WithSSHKey(sshkey.New(sshkey.TypeEd25519, 3072, "default"), 3072, 4),
+	)
+
+	tests := []struct {
+		name           string
+		body           string
+		wantStatus     int
+		wantType       string
+		wantComment    string
+		wantPassphrase bool
+	}{
+		{
+			name:        "empty body",
+			body:        "",
+			wantStatus:  http.StatusOK,
+			wantType:    sshkey.TypeEd25519,
+			wantComment: "default",
+		},
+		{
+			name:        "ecdsa with comment",
+			body:        `{"type":"ecdsa-p256","comment":"deploy@example.com"}`,
+			wantStatus:  http.StatusOK,
+			wantType:    sshkey.TypeECDSAP256,
+			wantComment: "deploy@example.com",
+		},
+		{
+			name:        "supplied passphrase",
+			body:        `{"passphrase":"correct horse battery staple"}`,
+			wantStatus:  http.StatusOK,
+			wantType:    sshkey.TypeEd25519,
+			wantComment: "default",
+		},
+		{
+			name:           "generated passphrase",
+			body:           `{"type":"ecdsa-p384","generate_passphrase":true}`,
+			wantStatus:     http.StatusOK,
+			wantType:       sshkey.TypeECDSAP384,
+			wantComment:    "default",
+			wantPassphrase: true,
+		},
+		{
+			name:       "rsa above the max size",
+			body:       `{"type":"rsa","bits":4096}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unsupported rsa size",
+			body:       `{"type":"rsa","bits":2048}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown type",
+			body:       `{"type":"dsa"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "supplied and generated passphrase",
+			body:       `{"passphrase":"secret","generate_passphrase":true}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown field",
+			body:       `{"size":256}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid json",
+			body:       `{"type":`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, "/sshkey", strings.NewReader(tt.body))
+
+			h.handleSSHKey(rr, req)
+
+			resp := rr.Result()
+			require.NotNil(t, resp)
+
+			defer func() {
+				err := resp.Body.Close()
+				require.NoError(t, err, "error closing resp.Body")
+			}()
+
+			require.Equal(t, tt.wantStatus, resp.StatusCode)
+
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			body, _ := io.ReadAll(resp.Body)
+
+			var data struct {
+				Type          string `json:"type"`
+				Comment       string `json:"comment"`
+				PrivateKey    string `json:"private_key"`
+				Encrypted     bool   `json:"encrypted"`
+				AuthorizedKey string `json:"authorized_key"`
+				Fingerprint   string `json:"fingerprint"`
+				Passphrase    string `json:"passphrase"`
+			}
+
+			require.NoError(t, json.Unmarshal(body, &data))
+			require.Equal(t, tt.wantType, data.Type)
+			require.Equal(t, tt.wantComment, data.Comment)
+			require.Equal(t, tt.wantPassphrase, data.Passphrase != "")
+			require.True(t, strings.HasSuffix(data.AuthorizedKey, " "+tt.wantComment))
+
+			pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(data.AuthorizedKey))
+			require.NoError(t, err)
+			require.Equal(t, data.Fingerprint, ssh.FingerprintSHA256(pub))
+
+			var sreq sshKeyRequest
+
+			_ = json.Unmarshal([]byte(tt.body), &sreq)
+
+			passphrase := sreq.Passphrase
+			if tt.wantPassphrase {
+				passphrase = data.Passphrase
+			}
+
+			require.Equal(t, passphrase != "", data.Encrypted)
+
+			if passphrase == "" {
+				_, err = ssh.ParsePrivateKey([]byte(data.PrivateKey))
+			} else {
+				_, err = ssh.ParsePrivateKeyWithPassphrase([]byte(data.PrivateKey), []byte(passphrase))
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestHTTPHandler_handleSSHKeyBusy(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	h := New(nil, nil, nil, val, nil, WithSSHKey(sshkey.New(sshkey.TypeEd25519, 3072, ""), 4096, 1))
+
+	// occupy the only key generation slot
+	h.sshSlots <- struct{}{}
+
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, "/sshkey", strings.NewReader("{}"))
+
+	h.handleSSHKey(rr, req)
+
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+	<-h.sshSlots
+
+	rr = httptest.NewRecorder()
+	req, _ = http.NewRequestWithContext(t.Context(), http.MethodPost, "/sshkey", strings.NewReader("{}"))
+
+	h.handleSSHKey(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Empty(t, h.sshSlots)
+}
+
+func TestHTTPHandler_handleSSHKey_passphraseError(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	h := New(nil, nil, nil, val, nil)
+	h.newPassphrase = func(_ string, _, _ int, _ ...password.PassphraseOption) passphraseGenerator {
+		return errPassphraseGenerator{}
+	}
+
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, "/sshkey", strings.NewReader(`{"generate_passphrase":true}`))
+
+	h.handleSSHKey(rr, req)
+
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+}
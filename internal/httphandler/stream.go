@@ -0,0 +1,89 @@
+package httphandler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// mimeNDJSON is the media type of the streaming output, where each line
+	// is a JSON string containing one password.
+	mimeNDJSON = "application/x-ndjson"
+
+	// DefaultStreamMaxQuantity is the default maximum number of passwords of a
+	// streaming request.
+	DefaultStreamMaxQuantity = 1_000_000
+
+	// streamFlushLines is the number of passwords written between two flushes
+	// of the streaming output.
+	streamFlushLines = 256
+)
+
+// WithStreamMaxQuantity sets the maximum number of passwords of a streaming
+// request (default DefaultStreamMaxQuantity).
+func WithStreamMaxQuantity(n int) Option {
+	return func(h *HTTPHandler) {
+		h.streamMaxQuantity = n
+	}
+}
+
+// acceptsNDJSON reports whether the client requested the streaming output.
+func acceptsNDJSON(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), mimeNDJSON)
+}
+
+// streamPasswords writes the passwords as newline-delimited JSON strings as
+// they are generated, flushing them every streamFlushLines lines, until the
+// quantity is reached, the request context is canceled or the client is gone.
+// The status is sent before the first password, so a late error can only cut
+// the output short.
+func streamPasswords(ctx context.Context, w http.ResponseWriter, p generator, quantity int) error {
+	w.Header().Set("Content-Type", mimeNDJSON)
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+
+	var lines int
+
+	err := p.Stream(ctx, quantity, func(pwd string) error {
+		err := enc.Encode(pwd)
+		if err != nil {
+			return fmt.Errorf("failed writing password: %w", err)
+		}
+
+		lines++
+
+		if lines%streamFlushLines != 0 {
+			return nil
+		}
+
+		return flushStream(bw, rc)
+	})
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return flushStream(bw, rc)
+}
+
+// flushStream sends the buffered output to the client.
+func flushStream(bw *bufio.Writer, rc *http.ResponseController) error {
+	err := bw.Flush()
+	if err != nil {
+		return fmt.Errorf("failed writing passwords: %w", err)
+	}
+
+	err = rc.Flush()
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return fmt.Errorf("failed flushing passwords: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package httphandler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/rndpwd/internal/password"
+	"github.com/tecnickcom/rndpwd/internal/validator"
+)
+
+func TestHTTPHandler_handlePassword_stream(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	h := New(
+		nil,
+		nil,
+		nil,
+		val,
+		password.New("0123456789abcdefghijklmnopqrstuvwxyz", 16, 3),
+		WithStreamMaxQuantity(5000),
+	)
+
+	tests := []struct {
+		name      string
+		params    string
+		canceled  bool
+		wantCode  int
+		wantLines int
+	}{
+		{
+			name:      "default quantity",
+			wantCode:  http.StatusOK,
+			wantLines: 3,
+		},
+		{
+			name:      "above the batch ceiling",
+			params:    "?quantity=5000&length=8",
+			wantCode:  http.StatusOK,
+			wantLines: 5000,
+		},
+		{
+			name:     "above the streaming ceiling",
+			params:   "?quantity=5001",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "zero quantity",
+			params:   "?quantity=0",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "detail",
+			params:   "?detail=true",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unique",
+			params:   "?unique=true",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "hash",
+			params:   "?hash=bcrypt",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid length",
+			params:   "?length=0",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:      "canceled request",
+			params:    "?quantity=5000",
+			canceled:  true,
+			wantCode:  http.StatusOK,
+			wantLines: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(t.Context())
+			defer cancel()
+
+			if tt.canceled {
+				cancel()
+			}
+
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/password"+tt.params, nil)
+			req.Header.Set("Accept", mimeNDJSON)
+
+			h.handlePassword(rr, req)
+
+			resp := rr.Result()
+
+			defer func() {
+				err := resp.Body.Close()
+				require.NoError(t, err, "error closing resp.Body")
+			}()
+
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			require.Equal(t, mimeNDJSON, resp.Header.Get("Content-Type"))
+			require.NotEmpty(t, resp.Header.Get(headerEntropy))
+
+			var lines int
+
+			scanner := bufio.NewScanner(resp.Body)
+
+			for scanner.Scan() {
+				var pwd string
+
+				require.NoError(t, json.Unmarshal(scanner.Bytes(), &pwd))
+				require.NotEmpty(t, pwd)
+
+				lines++
+			}
+
+			require.Equal(t, tt.wantLines, lines)
+		})
+	}
+}
+
+func TestStreamPasswords_error(t *testing.T) {
+	t.Parallel()
+
+	rr := httptest.NewRecorder()
+
+	err := streamPasswords(t.Context(), rr, errGenerator{}, 10)
+	require.Error(t, err)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Empty(t, rr.Body.String())
+}
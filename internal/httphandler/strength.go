@@ -0,0 +1,39 @@
+package httphandler
+
+import (
+	"net/http"
+
+	"github.com/tecnickcom/rndpwd/internal/strength"
+)
+
+// maxStrengthBodySize is the maximum size in bytes of the /strength request
+// body.
+const maxStrengthBodySize = 16 << 10
+
+// strengthRequest is the body of the /strength request.
+type strengthRequest struct {
+	// Password is the password to evaluate.
+	Password string `json:"password" validate:"required,max=1024"`
+
+	// UserInputs are the user-specific words an attacker would try first, such
+	// as the user name or the email address.
+	UserInputs []string `json:"user_inputs" validate:"max=16,dive,max=256"`
+}
+
+func (h *HTTPHandler) handleStrength(w http.ResponseWriter, r *http.Request) {
+	var req strengthRequest
+
+	err := decodeJSONBody(w, r, maxStrengthBodySize, &req)
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	err = h.val.ValidateStruct(req)
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.httpres.SendJSON(r.Context(), w, http.StatusOK, strength.Evaluate(req.Password, req.UserInputs...))
+}
@@ -0,0 +1,116 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/rndpwd/internal/password"
+	"github.com/tecnickcom/rndpwd/internal/strength"
+	"github.com/tecnickcom/rndpwd/internal/validator"
+)
+
+func TestHTTPHandler_handleStrength(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	h := New(nil, nil, nil, val, password.New("0123456789abcdefghijklmnopqrstuvwxyz", 16, 3))
+
+	tests := []struct {
+		name      string
+		body      string
+		wantErr   bool
+		wantScore int
+		wantWarn  string
+	}{
+		{
+			name:      "common password",
+			body:      `{"password":"password"}`,
+			wantScore: 0,
+			wantWarn:  "This is a top-10 common password.",
+		},
+		{
+			name:      "strong password",
+			body:      `{"password":"Xk9#vQ2!mR7$pL4&"}`,
+			wantScore: 4,
+		},
+		{
+			name:      "user inputs",
+			body:      `{"password":"jsmith1984","user_inputs":["jsmith","jsmith@example.com"]}`,
+			wantScore: 1,
+			wantWarn:  "Passwords based on personal information are easy to guess.",
+		},
+		{
+			name:    "missing password",
+			body:    `{"user_inputs":["jsmith"]}`,
+			wantErr: true,
+		},
+		{
+			name:    "password too long",
+			body:    `{"password":"` + strings.Repeat("a", 1025) + `"}`,
+			wantErr: true,
+		},
+		{
+			name:    "too many user inputs",
+			body:    `{"password":"secret","user_inputs":["a","b","c","d","e","f","g","h","i","j","k","l","m","n","o","p","q"]}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			body:    `{"password":"secret","charset":"abc"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid JSON",
+			body:    `{"password":`,
+			wantErr: true,
+		},
+		{
+			name:    "body too large",
+			body:    `{"password":"` + strings.Repeat("a", maxStrengthBodySize) + `"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, "/strength", strings.NewReader(tt.body))
+
+			h.handleStrength(rr, req)
+
+			resp := rr.Result()
+			require.NotNil(t, resp)
+
+			defer func() {
+				err := resp.Body.Close()
+				require.NoError(t, err, "error closing resp.Body")
+			}()
+
+			body, _ := io.ReadAll(resp.Body)
+
+			if tt.wantErr {
+				require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				return
+			}
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+
+			var data strength.Result
+
+			require.NoError(t, json.Unmarshal(body, &data))
+			require.Equal(t, tt.wantScore, data.Score)
+			require.Equal(t, tt.wantWarn, data.Feedback.Warning)
+			require.Positive(t, data.Guesses)
+			require.NotEmpty(t, data.Sequence)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package httphandler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/tecnickcom/nurago/pkg/httputil"
+	"github.com/tecnickcom/rndpwd/internal/password"
+)
+
+// maxTokenValidateBodySize is the maximum size in bytes of the /token/validate
+// request body.
+const maxTokenValidateBodySize = 4 << 10
+
+// tokenResponse is the body of the /token response.
+type tokenResponse struct {
+	Prefix  string   `json:"prefix"`
+	Length  int      `json:"length"`
+	Entropy float64  `json:"entropy"`
+	Pattern string   `json:"pattern"`
+	Tokens  []string `json:"tokens"`
+}
+
+// tokenValidateRequest is the body of the /token/validate request.
+type tokenValidateRequest struct {
+	// Token is the API token to check.
+	Token string `json:"token" validate:"required,max=256"`
+
+	// Prefix is the expected prefix of the token, if any.
+	Prefix string `json:"prefix" validate:"max=32"`
+}
+
+// WithToken sets the default settings of the /token route.
+func WithToken(t *password.Token) Option {
+	return func(h *HTTPHandler) {
+		h.token = t
+	}
+}
+
+// tokenParams returns the query parameters accepted by the /token route.
+func tokenParams() map[string]paramType {
+	return map[string]paramType{
+		"prefix":   paramString,
+		"bits":     paramInt,
+		"quantity": paramInt,
+	}
+}
+
+func (h *HTTPHandler) handleToken(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if !validQueryParams(query, tokenParams()) {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, "invalid query parameter")
+		return
+	}
+
+	// URL query parameters can override the config settings
+	t := password.NewToken(
+		httputil.QueryStringOrDefault(query, "prefix", h.token.Prefix),
+		httputil.QueryIntOrDefault(query, "bits", h.token.Bits),
+		httputil.QueryIntOrDefault(query, "quantity", h.token.Quantity),
+		password.WithTokenSource(h.source),
+	)
+
+	err := h.val.ValidateStruct(t)
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	lst, err := t.Generate()
+	if errors.Is(err, password.ErrInvalidTokenPrefix) {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusInternalServerError, "failed generating tokens")
+		return
+	}
+
+	h.httpres.SendJSON(r.Context(), w, http.StatusOK, tokenResponse{
+		Prefix:  t.Prefix,
+		Length:  t.Length(),
+		Entropy: t.Entropy(),
+		Pattern: t.Pattern(),
+		Tokens:  lst,
+	})
+}
+
+func (h *HTTPHandler) handleTokenValidate(w http.ResponseWriter, r *http.Request) {
+	var req tokenValidateRequest
+
+	err := decodeJSONBody(w, r, maxTokenValidateBodySize, &req)
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	err = h.val.ValidateStruct(req)
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.httpres.SendJSON(r.Context(), w, http.StatusOK, password.ValidateToken(req.Token, req.Prefix))
+}
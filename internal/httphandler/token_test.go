@@ -0,0 +1,228 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/rndpwd/internal/password"
+	"github.com/tecnickcom/rndpwd/internal/validator"
+)
+
+func TestHTTPHandler_handleToken(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	h := New(
+		nil,
+		nil,
+		nil,
+		val,
+		password.New("0123456789abcdefghijklmnopqrstuvwxyz", 16, 3),
+		WithToken(password.NewToken("acme_live_", 128, 2)),
+	)
+
+	tests := []struct {
+		name       string
+		params     string
+		wantErr    bool
+		wantPrefix string
+		wantLength int
+		wantQty    int
+	}{
+		{
+			name:       "valid empty",
+			params:     "",
+			wantPrefix: "acme_live_",
+			wantLength: 38,
+			wantQty:    2,
+		},
+		{
+			name:       "valid all params",
+			params:     "?prefix=acme_test_&bits=256&quantity=5",
+			wantPrefix: "acme_test_",
+			wantLength: 59,
+			wantQty:    5,
+		},
+		{
+			name:    "invalid prefix",
+			params:  "?prefix=Acme-",
+			wantErr: true,
+		},
+		{
+			name:    "prefix too long",
+			params:  "?prefix=" + strings.Repeat("a", 32) + "_",
+			wantErr: true,
+		},
+		{
+			name:    "too few bits",
+			params:  "?bits=127",
+			wantErr: true,
+		},
+		{
+			name:    "too many bits",
+			params:  "?bits=513",
+			wantErr: true,
+		},
+		{
+			name:    "zero quantity",
+			params:  "?quantity=0",
+			wantErr: true,
+		},
+		{
+			name:    "unknown parameter",
+			params:  "?length=8",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "/token"+tt.params, nil)
+
+			h.handleToken(rr, req)
+
+			resp := rr.Result()
+			require.NotNil(t, resp)
+
+			defer func() {
+				err := resp.Body.Close()
+				require.NoError(t, err, "error closing resp.Body")
+			}()
+
+			if tt.wantErr {
+				require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				return
+			}
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			body, _ := io.ReadAll(resp.Body)
+
+			var data tokenResponse
+
+			require.NoError(t, json.Unmarshal(body, &data))
+			require.Equal(t, tt.wantPrefix, data.Prefix)
+			require.Equal(t, tt.wantLength, data.Length)
+			require.Len(t, data.Tokens, tt.wantQty)
+
+			pattern := regexp.MustCompile(data.Pattern)
+
+			for _, token := range data.Tokens {
+				require.Len(t, token, tt.wantLength)
+				require.True(t, pattern.MatchString(token))
+				require.True(t, password.ValidateToken(token, tt.wantPrefix).Valid)
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_handleTokenValidate(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	h := New(
+		nil,
+		nil,
+		nil,
+		val,
+		password.New("0123456789abcdefghijklmnopqrstuvwxyz", 16, 3),
+	)
+
+	lst, err := password.NewToken("acme_live_", 128, 1).Generate()
+	require.NoError(t, err)
+
+	token := lst[0]
+	typo := token[:len(token)-1] + "!"
+
+	tests := []struct {
+		name       string
+		body       string
+		wantCode   int
+		wantValid  bool
+		wantReason string
+	}{
+		{
+			name:      "valid",
+			body:      `{"token":"` + token + `"}`,
+			wantCode:  http.StatusOK,
+			wantValid: true,
+		},
+		{
+			name:      "expected prefix",
+			body:      `{"token":"` + token + `","prefix":"acme_live_"}`,
+			wantCode:  http.StatusOK,
+			wantValid: true,
+		},
+		{
+			name:       "unexpected prefix",
+			body:       `{"token":"` + token + `","prefix":"acme_test_"}`,
+			wantCode:   http.StatusOK,
+			wantReason: "unexpected prefix",
+		},
+		{
+			name:       "typo",
+			body:       `{"token":"` + typo + `"}`,
+			wantCode:   http.StatusOK,
+			wantReason: "invalid body",
+		},
+		{
+			name:     "missing token",
+			body:     `{"prefix":"acme_live_"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown field",
+			body:     `{"token":"` + token + `","bits":128}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid JSON",
+			body:     `{"token":`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, "/token/validate", strings.NewReader(tt.body))
+
+			h.handleTokenValidate(rr, req)
+
+			resp := rr.Result()
+			require.NotNil(t, resp)
+
+			defer func() {
+				err := resp.Body.Close()
+				require.NoError(t, err, "error closing resp.Body")
+			}()
+
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			body, _ := io.ReadAll(resp.Body)
+
+			var data password.TokenCheck
+
+			require.NoError(t, json.Unmarshal(body, &data))
+			require.Equal(t, tt.wantValid, data.Valid)
+			require.Equal(t, tt.wantReason, data.Reason)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package httphandler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/tecnickcom/nurago/pkg/httputil"
+	"github.com/tecnickcom/rndpwd/internal/uid"
+)
+
+// DefaultUIDMaxQuantity is the default maximum number of identifiers of each
+// /uid request.
+const DefaultUIDMaxQuantity = 1000
+
+// maxInspectLength is the maximum length of the identifiers accepted by the
+// /uid/inspect route, well above the longest supported format.
+const maxInspectLength = 256
+
+// WithUID sets the default format of the /uid route and the maximum number of
+// identifiers of each request (default uuidv7 and DefaultUIDMaxQuantity).
+func WithUID(format string, maxQuantity int) Option {
+	return func(h *HTTPHandler) {
+		h.uidFormat = format
+		h.uidMaxQuantity = maxQuantity
+	}
+}
+
+// WithSnowflake sets the node ID and the epoch, in milliseconds since the Unix
+// epoch, of the Snowflake identifiers (default 0 and uid.DefaultSnowflakeEpoch).
+func WithSnowflake(node, epoch int64) Option {
+	return func(h *HTTPHandler) {
+		h.snowflakeNode = node
+		h.snowflakeEpoch = epoch
+	}
+}
+
+// uidParams returns the query parameters accepted by the /uid route.
+func uidParams() map[string]paramType {
+	return map[string]paramType{
+		"format":    paramString,
+		"quantity":  paramInt,
+		"namespace": paramString,
+		"name":      paramString,
+		"alphabet":  paramString,
+		"length":    paramInt,
+		paramSeed:   paramString,
+	}
+}
+
+// handleGenUID returns a single identifier as a JSON string, or an array of
+// identifiers when the quantity parameter is set. The time-ordered identifiers
+// of the shared generator are strictly increasing, also across requests.
+func (h *HTTPHandler) handleGenUID(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if !validQueryParams(query, uidParams()) {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, "invalid query parameter")
+		return
+	}
+
+	src, seeded, err := h.requestSource(r)
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	gen := h.uid
+	format := h.uidFormat
+
+	if seeded {
+		// the time-ordered identifiers can't be reproduced from a seed
+		gen = uid.New(uid.WithSource(src))
+		format = uid.FormatUUIDv4
+	}
+
+	format = httputil.QueryStringOrDefault(query, "format", format)
+
+	if seeded && uid.TimeOrdered(format) {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, "the "+format+" format embeds a timestamp and can't be seeded")
+		return
+	}
+
+	quantity := httputil.QueryIntOrDefault(query, "quantity", 1)
+	if quantity < 1 || quantity > h.uidMaxQuantity {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, fmt.Sprintf("the quantity must be between 1 and %d", h.uidMaxQuantity))
+		return
+	}
+
+	if format == uid.FormatUUIDv5 && quantity > 1 {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, "the uuidv5 identifier is unique for each namespace and name")
+		return
+	}
+
+	lst, err := gen.Generate(format, quantity, uid.Params{
+		Namespace: query.Get("namespace"),
+		Name:      query.Get("name"),
+		Alphabet:  query.Get("alphabet"),
+		Length:    httputil.QueryIntOrDefault(query, "length", 0),
+	})
+	if errors.Is(err, uid.ErrInvalid) {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusInternalServerError, "failed generating UID")
+		return
+	}
+
+	if !query.Has("quantity") {
+		h.httpres.SendJSON(r.Context(), w, http.StatusOK, lst[0])
+		return
+	}
+
+	h.httpres.SendJSON(r.Context(), w, http.StatusOK, lst)
+}
+
+// handleInspectUID detects the format of the identifier in the id query
+// parameter and returns its decoded fields. The Snowflake timestamps are
+// relative to the configured epoch.
+func (h *HTTPHandler) handleInspectUID(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if !validQueryParams(query, map[string]paramType{"id": paramString}) {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, "invalid query parameter")
+		return
+	}
+
+	id := query.Get("id")
+	if id == "" || len(id) > maxInspectLength {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, fmt.Sprintf("the id must contain 1 to %d characters", maxInspectLength))
+		return
+	}
+
+	h.httpres.SendJSON(r.Context(), w, http.StatusOK, uid.Inspect(id, h.snowflakeEpoch))
+}
@@ -0,0 +1,285 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/rndpwd/internal/uid"
+)
+
+// failingSource is an entropy source that always fails.
+type failingSource struct{}
+
+func (failingSource) Read([]byte) (int, error) { return 0, errors.New("rng failure") }
+
+func (failingSource) Name() string { return "failing" }
+
+func TestHTTPHandler_handleGenUIDFormats(t *testing.T) {
+	t.Parallel()
+
+	h := New(nil, nil, nil, nil, nil, WithUID(uid.FormatULID, 50), WithSnowflake(3, uid.DefaultSnowflakeEpoch))
+
+	tests := []struct {
+		name       string
+		params     string
+		wantStatus int
+		wantQty    int
+		pattern    string
+	}{
+		{
+			name:       "default format",
+			params:     "",
+			wantStatus: http.StatusOK,
+			pattern:    `^[0-7][0-9A-HJKMNP-TV-Z]{25}$`,
+		},
+		{
+			name:       "uuidv7 batch",
+			params:     "?format=uuidv7&quantity=50",
+			wantStatus: http.StatusOK,
+			wantQty:    50,
+			pattern:    `^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+		},
+		{
+			name:       "ksuid batch",
+			params:     "?format=ksuid&quantity=20",
+			wantStatus: http.StatusOK,
+			wantQty:    20,
+			pattern:    `^[0-9A-Za-z]{27}$`,
+		},
+		{
+			name:       "snowflake single in array",
+			params:     "?format=snowflake&quantity=1",
+			wantStatus: http.StatusOK,
+			wantQty:    1,
+			pattern:    `^[0-9]+$`,
+		},
+		{
+			name:       "uuidv5",
+			params:     "?format=uuidv5&namespace=dns&name=www.example.com",
+			wantStatus: http.StatusOK,
+			pattern:    `^2ed6657d-e927-568b-95e1-2665a8aea6a2$`,
+		},
+		{
+			name:       "nanoid custom",
+			params:     "?format=nanoid&alphabet=0123456789abcdef&length=32&quantity=2",
+			wantStatus: http.StatusOK,
+			wantQty:    2,
+			pattern:    `^[0-9a-f]{32}$`,
+		},
+		{
+			name:       "uuidv5 batch",
+			params:     "?format=uuidv5&namespace=dns&name=www.example.com&quantity=2",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "uuidv5 missing name",
+			params:     "?format=uuidv5&namespace=dns",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "above the max quantity",
+			params:     "?quantity=51",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "zero quantity",
+			params:     "?quantity=0",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown format",
+			params:     "?format=uuidv1",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown parameter",
+			params:     "?size=8",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "/uid"+tt.params, nil)
+
+			h.handleGenUID(rr, req)
+
+			resp := rr.Result()
+			require.NotNil(t, resp)
+
+			defer func() {
+				err := resp.Body.Close()
+				require.NoError(t, err, "error closing resp.Body")
+			}()
+
+			require.Equal(t, tt.wantStatus, resp.StatusCode)
+
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			body, _ := io.ReadAll(resp.Body)
+
+			var lst []string
+
+			if tt.wantQty == 0 {
+				var id string
+
+				require.NoError(t, json.Unmarshal(body, &id))
+
+				lst = []string{id}
+			} else {
+				require.NoError(t, json.Unmarshal(body, &lst))
+				require.Len(t, lst, tt.wantQty)
+			}
+
+			for i, id := range lst {
+				require.Regexp(t, regexp.MustCompile(tt.pattern), id)
+
+				if i > 0 && uid.TimeOrdered(req.URL.Query().Get("format")) {
+					require.Less(t, lst[i-1], id)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_handleGenUIDSourceFailure(t *testing.T) {
+	t.Parallel()
+
+	h := New(nil, nil, nil, nil, nil, WithSource(failingSource{}))
+
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "/uid?format=uuidv4", nil)
+
+	h.handleGenUID(rr, req)
+
+	resp := rr.Result()
+	require.NotNil(t, resp)
+
+	defer func() {
+		err := resp.Body.Close()
+		require.NoError(t, err, "error closing resp.Body")
+	}()
+
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
+
+func TestHTTPHandler_handleInspectUID(t *testing.T) {
+	t.Parallel()
+
+	h := New(nil, nil, nil, nil, nil)
+
+	tests := []struct {
+		name       string
+		params     string
+		wantStatus int
+		wantFormat string
+		wantValid  bool
+	}{
+		{
+			name:       "uuidv7",
+			params:     "?id=017f22e2-79b0-7cc3-98c4-dc0c0c07398f",
+			wantStatus: http.StatusOK,
+			wantFormat: uid.FormatUUID,
+			wantValid:  true,
+		},
+		{
+			name:       "snowflake",
+			params:     "?id=1382350606417817604",
+			wantStatus: http.StatusOK,
+			wantFormat: uid.FormatSnowflake,
+			wantValid:  true,
+		},
+		{
+			name:       "malformed ulid",
+			params:     "?id=01ARZ3NDEKTSV4RRFFQ69G5FAU",
+			wantStatus: http.StatusOK,
+			wantFormat: uid.FormatULID,
+		},
+		{
+			name:       "missing id",
+			params:     "",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown parameter",
+			params:     "?id=1&format=uuidv7",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "/uid/inspect"+tt.params, nil)
+
+			h.handleInspectUID(rr, req)
+
+			resp := rr.Result()
+			require.NotNil(t, resp)
+
+			defer func() {
+				err := resp.Body.Close()
+				require.NoError(t, err, "error closing resp.Body")
+			}()
+
+			require.Equal(t, tt.wantStatus, resp.StatusCode)
+
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			body, _ := io.ReadAll(resp.Body)
+
+			var info uid.Info
+
+			require.NoError(t, json.Unmarshal(body, &info))
+			require.Equal(t, tt.wantFormat, info.Format)
+			require.Equal(t, tt.wantValid, info.Valid)
+		})
+	}
+}
+
+func TestHTTPHandler_handleInspectUIDRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	h := New(nil, nil, nil, nil, nil)
+
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "/uid?format=uuidv7", nil)
+
+	before := time.Now().Truncate(time.Millisecond)
+
+	h.handleGenUID(rr, req)
+
+	var id string
+
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &id))
+
+	rr = httptest.NewRecorder()
+	req, _ = http.NewRequestWithContext(t.Context(), http.MethodGet, "/uid/inspect?id="+id, nil)
+
+	h.handleInspectUID(rr, req)
+
+	var info uid.Info
+
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &info))
+	require.True(t, info.Valid)
+	require.Equal(t, 7, info.Version)
+	require.NotNil(t, info.Time)
+	require.False(t, info.Time.Before(before))
+	require.False(t, info.Time.After(time.Now()))
+}
@@ -0,0 +1,60 @@
+package httphandler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/tecnickcom/rndpwd/internal/pwhash"
+)
+
+// maxVerifyBodySize is the maximum size in bytes of the /verify request body.
+const maxVerifyBodySize = 8 << 10
+
+// verifyRequest is the body of the /verify request.
+type verifyRequest struct {
+	// Password is the plaintext password.
+	Password string `json:"password" validate:"required,max=1024"`
+
+	// Hash is the stored hash string.
+	Hash string `json:"hash" validate:"required,max=1024"`
+}
+
+// WithHashMinimum sets the minimum work factors of the hashes checked by the
+// /verify route, below which they are reported (default
+// pwhash.DefaultMinimums).
+func WithHashMinimum(minimum pwhash.Params) Option {
+	return func(h *HTTPHandler) {
+		h.hashMinimum = minimum
+	}
+}
+
+func (h *HTTPHandler) handleVerify(w http.ResponseWriter, r *http.Request) {
+	var req verifyRequest
+
+	err := decodeJSONBody(w, r, maxVerifyBodySize, &req)
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	err = h.val.ValidateStruct(req)
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// the work factors of the hash are bounded as the ones of the /password
+	// hashes, so a crafted hash cannot exhaust the CPU or memory
+	res, err := pwhash.Verify(req.Password, req.Hash, h.hashLimits, h.hashMinimum)
+	if errors.Is(err, pwhash.ErrInvalidHash) || errors.Is(err, pwhash.ErrInvalidParams) || errors.Is(err, pwhash.ErrPasswordTooLong) {
+		h.httpres.SendJSON(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err != nil {
+		h.httpres.SendJSON(r.Context(), w, http.StatusInternalServerError, "failed verifying the hash")
+		return
+	}
+
+	h.httpres.SendJSON(r.Context(), w, http.StatusOK, res)
+}
@@ -0,0 +1,131 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/rndpwd/internal/password"
+	"github.com/tecnickcom/rndpwd/internal/pwhash"
+	"github.com/tecnickcom/rndpwd/internal/validator"
+)
+
+func TestHTTPHandler_handleVerify(t *testing.T) {
+	t.Parallel()
+
+	val, _ := validator.New("json")
+
+	h := New(
+		nil,
+		nil,
+		nil,
+		val,
+		password.New("0123456789abcdefghijklmnopqrstuvwxyz", 16, 3),
+		WithHashMinimum(pwhash.Params{Cost: 5, Rounds: 5000}),
+	)
+
+	bcryptHash := "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW"
+	shaHash := "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1"
+
+	tests := []struct {
+		name      string
+		body      string
+		wantCode  int
+		wantMatch bool
+		wantAlg   string
+	}{
+		{
+			name:      "bcrypt match",
+			body:      `{"password":"U*U","hash":"` + bcryptHash + `"}`,
+			wantCode:  http.StatusOK,
+			wantMatch: true,
+			wantAlg:   pwhash.Bcrypt,
+		},
+		{
+			name:     "bcrypt mismatch",
+			body:     `{"password":"U*V","hash":"` + bcryptHash + `"}`,
+			wantCode: http.StatusOK,
+			wantAlg:  pwhash.Bcrypt,
+		},
+		{
+			name:      "sha512-crypt match",
+			body:      `{"password":"Hello world!","hash":"` + shaHash + `"}`,
+			wantCode:  http.StatusOK,
+			wantMatch: true,
+			wantAlg:   pwhash.SHA512Crypt,
+		},
+		{
+			name:     "unknown hash format",
+			body:     `{"password":"secret","hash":"secret"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "work factor above the limit",
+			body:     `{"password":"secret","hash":"$scrypt$ln=30,r=8,p=1$c2FsdA$a2V5"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "missing hash",
+			body:     `{"password":"secret"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "password too long for sha512-crypt",
+			body:     `{"password":"` + strings.Repeat("a", 1024) + `","hash":"$6$rounds=2000000$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "hash too long",
+			body:     `{"password":"secret","hash":"` + strings.Repeat("a", 1025) + `"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown field",
+			body:     `{"password":"secret","hash":"x","cost":4}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid JSON",
+			body:     `{"password":`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, "/verify", strings.NewReader(tt.body))
+
+			h.handleVerify(rr, req)
+
+			resp := rr.Result()
+			require.NotNil(t, resp)
+
+			defer func() {
+				err := resp.Body.Close()
+				require.NoError(t, err, "error closing resp.Body")
+			}()
+
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			body, _ := io.ReadAll(resp.Body)
+
+			var data pwhash.Verification
+
+			require.NoError(t, json.Unmarshal(body, &data))
+			require.Equal(t, tt.wantMatch, data.Match)
+			require.Equal(t, tt.wantAlg, data.Algorithm)
+			require.False(t, data.BelowMinimum)
+		})
+	}
+}
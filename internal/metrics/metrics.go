@@ -11,19 +11,28 @@ const (
 	// NameExample is the name of an example custom collector.
 	NameExample = "example_collector_total"
 
-	labelCode = "code"
+	// NameEntropySource is the name of the collector reporting the entropy
+	// source of the generators.
+	NameEntropySource = "entropy_source_info"
+
+	labelCode   = "code"
+	labelSource = "source"
 )
 
 // Metrics is the interface for the custom metrics.
 type Metrics interface {
 	CreateMetricsClientFunc() (metrics.Client, error)
 	IncExampleCounter(code string)
+	SetEntropySource(name string)
 }
 
 // Client groups the custom collectors to be shared with other packages.
 type Client struct {
 	// collectorExample is an example collector.
 	collectorExample *prometheus.CounterVec
+
+	// collectorEntropySource reports the entropy source in its label.
+	collectorEntropySource *prometheus.GaugeVec
 }
 
 // New creates a new Client instance.
@@ -36,12 +45,19 @@ func New() *Client {
 			},
 			[]string{labelCode},
 		),
+		collectorEntropySource: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: NameEntropySource,
+				Help: "Entropy source of the generators, set to 1 for the active source.",
+			},
+			[]string{labelSource},
+		),
 	}
 }
 
 // CreateMetricsClientFunc returns the metrics Client.
 func (m *Client) CreateMetricsClientFunc() (metrics.Client, error) {
-	opt := prom.WithCollector(m.collectorExample)
+	opt := prom.WithCollector(m.collectorExample, m.collectorEntropySource)
 	return prom.New(opt) //nolint:wrapcheck
 }
 
@@ -49,3 +65,9 @@ func (m *Client) CreateMetricsClientFunc() (metrics.Client, error) {
 func (m *Client) IncExampleCounter(code string) {
 	m.collectorExample.With(prometheus.Labels{labelCode: code}).Inc()
 }
+
+// SetEntropySource reports the name of the active entropy source.
+func (m *Client) SetEntropySource(name string) {
+	m.collectorEntropySource.Reset()
+	m.collectorEntropySource.With(prometheus.Labels{labelSource: name}).Set(1)
+}
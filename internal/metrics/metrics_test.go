@@ -13,6 +13,7 @@ func TestNew(t *testing.T) {
 	m := New()
 	require.NotNil(t, m, "Metrics should not be nil")
 	require.NotNil(t, m.collectorExample, "collectorExample not be nil")
+	require.NotNil(t, m.collectorEntropySource, "collectorEntropySource not be nil")
 }
 
 func TestCreateMetricsClientFunc(t *testing.T) {
@@ -34,3 +35,15 @@ func TestIncExampleCounter(t *testing.T) {
 	i = testutil.CollectAndCount(m.collectorExample, NameExample)
 	require.Equal(t, 1, i, "failed to assert right metrics: got %v want %v", i, 1)
 }
+
+func TestSetEntropySource(t *testing.T) {
+	t.Parallel()
+
+	m := New()
+	m.SetEntropySource("os")
+	m.SetEntropySource("chacha20")
+
+	i := testutil.CollectAndCount(m.collectorEntropySource, NameEntropySource)
+	require.Equal(t, 1, i)
+	require.InDelta(t, 1.0, testutil.ToFloat64(m.collectorEntropySource.WithLabelValues("chacha20")), 0)
+}
@@ -0,0 +1,54 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+)
+
+// maxBlocklistAttempts is the maximum number of passwords drawn to find one
+// that is not blocklisted. A random password is almost never blocklisted, so
+// reaching it means the settings only allow blocklisted passwords.
+const maxBlocklistAttempts = 100
+
+// errBlocklisted is returned when no password outside the blocklist is found.
+var errBlocklisted = errors.New("only blocklisted passwords generated")
+
+// Blocklist reports the passwords that must never be returned, such as the ones
+// found in a breached-password corpus.
+type Blocklist interface {
+	// Contains reports whether the password is blocklisted.
+	Contains(pwd string) (bool, error)
+}
+
+// WithBlocklist sets the blocklist of the generator: the blocklisted passwords
+// are discarded and drawn again. The keyspace and entropy don't account for
+// them, as they are a negligible fraction of the output space.
+func WithBlocklist(bl Blocklist) Option {
+	return func(p *Password) {
+		p.blocklist = bl
+	}
+}
+
+// blocklistedSource returns the function drawing passwords from next until one
+// is not blocklisted.
+func blocklistedSource(bl Blocklist, next func() (string, error)) func() (string, error) {
+	return func() (string, error) {
+		for range maxBlocklistAttempts {
+			pwd, err := next()
+			if err != nil {
+				return "", err
+			}
+
+			blocked, err := bl.Contains(pwd)
+			if err != nil {
+				return "", fmt.Errorf("failed checking the blocklist: %w", err)
+			}
+
+			if !blocked {
+				return pwd, nil
+			}
+		}
+
+		return "", fmt.Errorf("%w: no valid password found after %d attempts", errBlocklisted, maxBlocklistAttempts)
+	}
+}
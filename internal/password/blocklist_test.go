@@ -0,0 +1,65 @@
+package password
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testBlocklist blocklists the listed passwords.
+type testBlocklist struct {
+	blocked map[string]bool
+	err     error
+}
+
+func (b testBlocklist) Contains(pwd string) (bool, error) {
+	return b.blocked[pwd], b.err
+}
+
+func TestGenerateBlocklist(t *testing.T) {
+	t.Parallel()
+
+	bl := testBlocklist{blocked: map[string]bool{"a": true}}
+
+	p := New("ab", 1, 50, WithBlocklist(bl))
+
+	pwds, err := p.Generate()
+	require.NoError(t, err)
+	require.Len(t, pwds, 50)
+
+	for _, pwd := range pwds {
+		require.Equal(t, "b", pwd)
+	}
+
+	err = p.Stream(t.Context(), 20, func(pwd string) error {
+		require.Equal(t, "b", pwd)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// the blocklist applies on top of the constrained modes
+	p = New("ab", 1, 20, WithBlocklist(bl), WithMode(ModeTemplate), WithTemplate("[ab]"))
+
+	pwds, err = p.Generate()
+	require.NoError(t, err)
+
+	for _, pwd := range pwds {
+		require.Equal(t, "b", pwd)
+	}
+}
+
+func TestGenerateBlocklistError(t *testing.T) {
+	t.Parallel()
+
+	p := New("ab", 1, 1, WithBlocklist(testBlocklist{blocked: map[string]bool{"a": true, "b": true}}))
+
+	_, err := p.Generate()
+	require.ErrorIs(t, err, errBlocklisted)
+
+	errCheck := errors.New("corpus unavailable")
+	p = New("ab", 1, 1, WithBlocklist(testBlocklist{err: errCheck}))
+
+	_, err = p.Generate()
+	require.ErrorIs(t, err, errCheck)
+}
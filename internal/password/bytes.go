@@ -0,0 +1,190 @@
+package password
+
+import (
+	"crypto/rand"
+	"encoding/ascii85"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/tecnickcom/rndpwd/internal/charset"
+)
+
+// Encodings of the random bytes.
+const (
+	EncodingHex       = "hex"
+	EncodingBase64    = "base64"
+	EncodingBase64URL = "base64url"
+	EncodingBase32    = "base32"
+	EncodingBase58    = "base58"
+	EncodingBase85    = "base85"
+	EncodingZ85       = "z85"
+	EncodingGo        = "go"
+	EncodingC         = "c"
+)
+
+const (
+	// bigBase58Digits are the digits of big.Int.Text in base 58.
+	bigBase58Digits = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUV"
+
+	// z85Alphabet is the ZeroMQ Z85 alphabet.
+	z85Alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ.-:+=^!/*?&<>()[]{}@%$#"
+)
+
+// ErrInvalidEncoding is returned when the bytes can't be encoded as requested.
+var ErrInvalidEncoding = errors.New("invalid encoding")
+
+// base58Digits maps the big.Int base 58 digits to the Bitcoin alphabet.
+var base58Digits = charset.Translator(bigBase58Digits, charset.Base58) //nolint:gochecknoglobals
+
+// Bytes contains the random bytes generator configuration.
+type Bytes struct {
+	Size     int    `json:"size"     validate:"required,min=1,max=65536"`
+	Quantity int    `json:"quantity" validate:"required,min=1,max=100"`
+	Encoding string `json:"encoding" validate:"required,oneof=hex base64 base64url base32 base58 base85 z85 go c"`
+	reader   io.Reader
+}
+
+// BytesOption is a type to allow setting custom random bytes options.
+type BytesOption func(b *Bytes)
+
+// WithBytesSource sets the entropy source of the generator (default the OS
+// CSPRNG).
+func WithBytesSource(src Source) BytesOption {
+	return func(b *Bytes) {
+		b.reader = src
+	}
+}
+
+// NewBytes instantiate a new random bytes generator object.
+func NewBytes(size, quantity int, encoding string, opts ...BytesOption) *Bytes {
+	b := &Bytes{
+		Size:     size,
+		Quantity: quantity,
+		Encoding: encoding,
+		reader:   rand.Reader,
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(b)
+	}
+
+	return b
+}
+
+// Generate returns the specified amount of random byte strings of the
+// configured size, in the configured encoding.
+func (b *Bytes) Generate() ([]string, error) {
+	if b.Encoding == EncodingZ85 && b.Size%4 != 0 {
+		return nil, fmt.Errorf("%w: the z85 size must be a multiple of 4", ErrInvalidEncoding)
+	}
+
+	lst := make([]string, b.Quantity)
+	buf := make([]byte, b.Size)
+
+	for i := range b.Quantity {
+		_, err := io.ReadFull(b.reader, buf)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading random bytes: %w", err)
+		}
+
+		lst[i], err = Encode(buf, b.Encoding)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return lst, nil
+}
+
+// Encode returns the data in the given encoding. The base64url encoding is
+// unpadded, while base64 and base32 are padded. The base85 encoding is the
+// Adobe Ascii85 without delimiters, and z85 requires a multiple of 4 bytes.
+// The go and c encodings are byte-array literals.
+func Encode(data []byte, encoding string) (string, error) {
+	switch encoding {
+	case EncodingHex:
+		return hex.EncodeToString(data), nil
+	case EncodingBase64:
+		return base64.StdEncoding.EncodeToString(data), nil
+	case EncodingBase64URL:
+		return base64.RawURLEncoding.EncodeToString(data), nil
+	case EncodingBase32:
+		return base32.StdEncoding.EncodeToString(data), nil
+	case EncodingBase58:
+		return encodeBase58(data), nil
+	case EncodingBase85:
+		out := make([]byte, ascii85.MaxEncodedLen(len(data)))
+		return string(out[:ascii85.Encode(out, data)]), nil
+	case EncodingZ85:
+		return encodeZ85(data)
+	case EncodingGo:
+		return "[]byte{" + byteList(data) + "}", nil
+	case EncodingC:
+		return "{" + byteList(data) + "}", nil
+	default:
+		return "", fmt.Errorf("%w: unknown encoding %q", ErrInvalidEncoding, encoding)
+	}
+}
+
+// encodeBase58 returns the Bitcoin base58 encoding of the data, where each
+// leading zero byte is encoded as a leading 1.
+func encodeBase58(data []byte) string {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+
+	var digits string
+
+	if zeros < len(data) {
+		digits = base58Digits.Replace(new(big.Int).SetBytes(data).Text(58))
+	}
+
+	return strings.Repeat(charset.Base58[:1], zeros) + digits
+}
+
+// encodeZ85 returns the ZeroMQ Z85 encoding of the data, whose size must be a
+// multiple of 4.
+func encodeZ85(data []byte) (string, error) {
+	if len(data)%4 != 0 {
+		return "", fmt.Errorf("%w: the z85 size must be a multiple of 4", ErrInvalidEncoding)
+	}
+
+	out := make([]byte, 0, len(data)/4*5)
+
+	for i := 0; i < len(data); i += 4 {
+		v := binary.BigEndian.Uint32(data[i:])
+		chunk := [5]byte{}
+
+		for j := 4; j >= 0; j-- {
+			chunk[j] = z85Alphabet[v%85]
+			v /= 85
+		}
+
+		out = append(out, chunk[:]...)
+	}
+
+	return string(out), nil
+}
+
+// byteList returns the comma-separated hexadecimal literals of the bytes.
+func byteList(data []byte) string {
+	var sb strings.Builder
+
+	for i, c := range data {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+
+		fmt.Fprintf(&sb, "0x%02x", c)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,84 @@
+package password
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		data     string
+		encoding string
+		want     string
+		wantErr  bool
+	}{
+		{"hex", "00ff10", EncodingHex, "00ff10", false},
+		{"base64", "fbff", EncodingBase64, "+/8=", false},
+		{"base64url", "fbff", EncodingBase64URL, "-_8", false},
+		{"base32", "666f6f", EncodingBase32, "MZXW6===", false},
+		{"base58", hex.EncodeToString([]byte("Hello World!")), EncodingBase58, "2NEpo7TZRRrLZSi2U", false},
+		{"base58 leading zeros", "0000287fb4cd", EncodingBase58, "11233QC4", false},
+		{"base58 all zeros", "0000", EncodingBase58, "11", false},
+		{"base85", hex.EncodeToString([]byte("Man ")), EncodingBase85, "9jqo^", false},
+		{"z85", "864fd26fb559f75b", EncodingZ85, "HelloWorld", false},
+		{"z85 size", "864fd26fb5", EncodingZ85, "", true},
+		{"go", "00ff10", EncodingGo, "[]byte{0x00, 0xff, 0x10}", false},
+		{"c", "00ff10", EncodingC, "{0x00, 0xff, 0x10}", false},
+		{"unknown", "00", "base36", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := hex.DecodeString(tt.data)
+			require.NoError(t, err)
+
+			got, err := Encode(data, tt.encoding)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidEncoding)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBytesGenerate(t *testing.T) {
+	t.Parallel()
+
+	b := NewBytes(32, 3, EncodingHex)
+
+	lst, err := b.Generate()
+	require.NoError(t, err)
+	require.Len(t, lst, 3)
+	require.NotEqual(t, lst[0], lst[1])
+
+	for _, s := range lst {
+		data, err := hex.DecodeString(s)
+		require.NoError(t, err)
+		require.Len(t, data, 32)
+	}
+}
+
+func TestBytesGenerateErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewBytes(6, 1, EncodingZ85).Generate()
+	require.ErrorIs(t, err, ErrInvalidEncoding)
+
+	b := NewBytes(16, 1, EncodingHex)
+	b.reader = iotest.ErrReader(errors.New("rng failure"))
+
+	_, err = b.Generate()
+	require.Error(t, err)
+}
@@ -0,0 +1,104 @@
+package password
+
+import (
+	"container/list"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// lruCache is a concurrency-safe cache holding up to a maximum number of
+// entries. When it is full, the least recently used entry is evicted, so a
+// burst of new keys can't flush the frequently used ones at once.
+type lruCache[K comparable, V any] struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List // most recently used first
+	entries map[K]*list.Element
+}
+
+// lruEntry is an entry of the lruCache.
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// newLRUCache returns an empty cache of up to size entries.
+func newLRUCache[K comparable, V any](size int) *lruCache[K, V] {
+	return &lruCache[K, V]{
+		max:     size,
+		order:   list.New(),
+		entries: make(map[K]*list.Element, size),
+	}
+}
+
+// get returns the value of the key, marking it as recently used.
+func (c *lruCache[K, V]) get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		var zero V
+
+		return zero, false
+	}
+
+	c.order.MoveToFront(el)
+
+	return el.Value.(*lruEntry[K, V]).value, true //nolint:forcetypeassert
+}
+
+// add stores the value of the key, evicting the least recently used entry if
+// the cache is full.
+func (c *lruCache[K, V]) add(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*lruEntry[K, V]).value = value //nolint:forcetypeassert
+		c.order.MoveToFront(el)
+
+		return
+	}
+
+	if c.order.Len() >= c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry[K, V]).key) //nolint:forcetypeassert
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value})
+}
+
+// len returns the number of cached entries.
+func (c *lruCache[K, V]) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// classesKey returns a cache key identifying the classes and their bounds. The
+// variable-length fields are prefixed by their size, so different classes
+// never share a key.
+func classesKey(classes []charClass) string {
+	var sb strings.Builder
+
+	for _, c := range classes {
+		chars := string(c.chars)
+
+		sb.WriteString(strconv.Itoa(len(c.name)))
+		sb.WriteByte(':')
+		sb.WriteString(c.name)
+		sb.WriteString(strconv.Itoa(len(chars)))
+		sb.WriteByte(':')
+		sb.WriteString(chars)
+		sb.WriteString(strconv.Itoa(c.min))
+		sb.WriteByte(',')
+		sb.WriteString(strconv.Itoa(c.max))
+		sb.WriteByte(';')
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,48 @@
+package password
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUCache(t *testing.T) {
+	t.Parallel()
+
+	c := newLRUCache[string, int](2)
+
+	c.add("a", 1)
+	c.add("b", 2)
+
+	v, ok := c.get("a")
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+
+	// b is the least recently used entry
+	c.add("c", 3)
+	require.Equal(t, 2, c.len())
+
+	_, ok = c.get("b")
+	require.False(t, ok)
+
+	c.add("a", 4)
+
+	v, ok = c.get("a")
+	require.True(t, ok)
+	require.Equal(t, 4, v)
+
+	v, ok = c.get("c")
+	require.True(t, ok)
+	require.Equal(t, 3, v)
+}
+
+func TestClassesKey(t *testing.T) {
+	t.Parallel()
+
+	a := []charClass{{name: "x", chars: []rune("ab"), min: 1}, {name: "y", chars: []rune("c")}}
+	b := []charClass{{name: "x", chars: []rune("a")}, {name: "y", chars: []rune("bc"), min: 1}}
+
+	require.Equal(t, classesKey(a), classesKey(slices.Clone(a)))
+	require.NotEqual(t, classesKey(a), classesKey(b))
+}
@@ -0,0 +1,273 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	mrand "math/rand/v2"
+	"slices"
+	"strings"
+)
+
+const (
+	// SequenceLength is the length of the runs rejected by the NoSequences
+	// constraint.
+	SequenceLength = 3
+
+	// constraintSamples is the number of passwords drawn to estimate the
+	// fraction of the output space satisfying the constraints.
+	constraintSamples = 4096
+
+	// minConstraintSamples is the minimum number of the sampled passwords that
+	// must satisfy the constraints. Below it the rejection sampling would be
+	// too slow and the keyspace estimate too coarse.
+	minConstraintSamples = 64
+
+	// maxConstraintAttempts is the maximum number of passwords drawn to find
+	// one satisfying the constraints.
+	maxConstraintAttempts = 1 << 16
+
+	// maxConstraintsCache is the maximum number of cached constraints
+	// estimates.
+	maxConstraintsCache = 1024
+)
+
+// errConstraints is wrapped by all the errors reporting unsatisfiable
+// repetition and sequence constraints.
+var errConstraints = errors.New("unsatisfiable repetition and sequence constraints")
+
+// constraintsKey identifies the settings that determine the constraints
+// estimate: the effective charset, the length, the mode and its template, the
+// policy classes and the constraints.
+type constraintsKey struct {
+	charset          string
+	length           int
+	mode             string
+	template         string
+	excludeAmbiguous bool
+	classes          string
+	maxConsecutive   int
+	maxPerChar       int
+	minDistinct      int
+	noSequences      bool
+}
+
+// constraintsEstimate is the cached result of sampleConstraints.
+type constraintsEstimate struct {
+	hits int
+	err  error
+}
+
+// constraintsCache holds the constraints estimates shared by the generators
+// with the same settings, as a new generator is built for each request.
+var constraintsCache = newLRUCache[constraintsKey, constraintsEstimate](maxConstraintsCache) //nolint:gochecknoglobals
+
+// sequences are the alphabetic, numeric and keyboard runs rejected by the
+// NoSequences constraint, in both directions and in any letter case.
+var sequences = []string{ //nolint:gochecknoglobals
+	"abcdefghijklmnopqrstuvwxyz",
+	"0123456789",
+	"1234567890",
+	"qwertyuiop",
+	"asdfghjkl",
+	"zxcvbnm",
+}
+
+// Constraints contains the repetition and sequence rules that every generated
+// password must satisfy, on top of the mode and policy. A zero value disables
+// the corresponding rule.
+//
+// MaxConsecutive limits the runs of identical characters, MaxPerChar the
+// occurrences of each character and MinDistinct sets the minimum number of
+// different characters. NoSequences rejects the passwords containing
+// SequenceLength consecutive characters of the alphabet, the digits or a
+// keyboard row, as abc, 321 or qwe.
+//
+// The passwords breaking a rule are discarded and drawn again, so the
+// remaining ones stay equally likely. The size of the reduced keyspace is
+// estimated by sampling.
+type Constraints struct {
+	MaxConsecutive int  `json:"max_consecutive" validate:"min=0,max=4096"`
+	MaxPerChar     int  `json:"max_per_char"    validate:"min=0,max=4096"`
+	MinDistinct    int  `json:"min_distinct"    validate:"min=0,max=4096"`
+	NoSequences    bool `json:"no_sequences"`
+}
+
+// WithConstraints sets the repetition and sequence constraints that every
+// password must satisfy.
+func WithConstraints(c Constraints) Option {
+	return func(p *Password) {
+		p.Constraints = c
+	}
+}
+
+// IsSet reports whether at least one constraint is enabled.
+func (c Constraints) IsSet() bool {
+	return c.MaxConsecutive > 0 || c.MaxPerChar > 0 || c.MinDistinct > 0 || c.NoSequences
+}
+
+// Allows reports whether the password satisfies the constraints.
+func (c Constraints) Allows(pwd string) bool {
+	runes := []rune(pwd)
+	count := make(map[rune]int, len(runes))
+	run := 0
+
+	for i, r := range runes {
+		run++
+
+		if i > 0 && runes[i-1] != r {
+			run = 1
+		}
+
+		count[r]++
+
+		if c.MaxConsecutive > 0 && run > c.MaxConsecutive {
+			return false
+		}
+
+		if c.MaxPerChar > 0 && count[r] > c.MaxPerChar {
+			return false
+		}
+
+		if c.NoSequences && i >= SequenceLength-1 && isSequence(runes[i-SequenceLength+1:i+1]) {
+			return false
+		}
+	}
+
+	return len(count) >= c.MinDistinct
+}
+
+// isSequence reports whether the characters are a run of one of the
+// sequences, in either direction.
+func isSequence(runes []rune) bool {
+	run := []rune(strings.ToLower(string(runes)))
+	rev := slices.Clone(run)
+	slices.Reverse(rev)
+
+	for _, seq := range sequences {
+		if strings.Contains(seq, string(run)) || strings.Contains(seq, string(rev)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// constrainedSource returns the function drawing passwords from next until one
+// satisfies the constraints.
+func (c Constraints) constrainedSource(next func() (string, error)) func() (string, error) {
+	return func() (string, error) {
+		for range maxConstraintAttempts {
+			pwd, err := next()
+			if err != nil {
+				return "", err
+			}
+
+			if c.Allows(pwd) {
+				return pwd, nil
+			}
+		}
+
+		return "", fmt.Errorf("%w: no valid password found after %d attempts", errConstraints, maxConstraintAttempts)
+	}
+}
+
+// checkConstraints estimates the number of sampled passwords satisfying the
+// constraints, building it on the first call.
+func (p *Password) checkConstraints() (int, error) {
+	p.constraintsOnce.Do(func() {
+		p.constraintsHits, p.constraintsErr = p.cachedConstraints()
+	})
+
+	return p.constraintsHits, p.constraintsErr
+}
+
+// cachedConstraints returns the constraints estimate of the generator
+// settings, sampling it only when it is not cached. The estimate is
+// deterministic, so the errors are cached too.
+func (p *Password) cachedConstraints() (int, error) {
+	key := constraintsKey{
+		charset:          p.charset,
+		length:           p.Length,
+		mode:             p.Mode,
+		template:         string(p.Template),
+		excludeAmbiguous: p.ExcludeAmbiguous,
+		maxConsecutive:   p.Constraints.MaxConsecutive,
+		maxPerChar:       p.Constraints.MaxPerChar,
+		minDistinct:      p.Constraints.MinDistinct,
+		noSequences:      p.Constraints.NoSequences,
+	}
+
+	if p.Policy.IsSet() {
+		key.classes = classesKey(p.classes)
+	}
+
+	est, ok := constraintsCache.get(key)
+	if ok {
+		return est.hits, est.err
+	}
+
+	est.hits, est.err = p.sampleConstraints()
+
+	constraintsCache.add(key, est)
+
+	return est.hits, est.err
+}
+
+// sampleConstraints counts how many of constraintSamples passwords drawn from
+// a fixed-seed generator satisfy the constraints, so the estimate is the same
+// for every request.
+func (p *Password) sampleConstraints() (int, error) {
+	if p.Mode != ModeTemplate && p.Length > MaxConstrainedLength {
+		return 0, fmt.Errorf("%w: the length %d exceeds the maximum of %d", errConstraints, p.Length, MaxConstrainedLength)
+	}
+
+	if p.Constraints.MinDistinct > len(p.runes) {
+		return 0, fmt.Errorf("%w: the minimum of %d distinct characters exceeds the charset size %d", errConstraints, p.Constraints.MinDistinct, len(p.runes))
+	}
+
+	// the estimate only needs a uniform generator, not a secret one
+	reader := mrand.NewChaCha8([32]byte{}) //nolint:gosec
+
+	next, err := p.newBaseSource(reader)
+	if err != nil {
+		return 0, err
+	}
+
+	hits := 0
+
+	for range constraintSamples {
+		pwd, err := next()
+		if err != nil {
+			return 0, fmt.Errorf("failed sampling the constraints: %w", err)
+		}
+
+		if p.Constraints.Allows(pwd) {
+			hits++
+		}
+	}
+
+	if hits < minConstraintSamples {
+		return 0, fmt.Errorf("%w: only %d of %d sampled passwords are valid, at least %d are required", errConstraints, hits, constraintSamples, minConstraintSamples)
+	}
+
+	return hits, nil
+}
+
+// constrainedKeyspace returns the estimated number of passwords of the
+// keyspace satisfying the constraints.
+func (p *Password) constrainedKeyspace(keyspace *big.Int) (*big.Int, error) {
+	hits, err := p.checkConstraints()
+	if err != nil {
+		return nil, err
+	}
+
+	n := new(big.Int).Mul(keyspace, big.NewInt(int64(hits)))
+	n.Quo(n, big.NewInt(constraintSamples))
+
+	if n.Sign() == 0 {
+		n.SetInt64(1)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,186 @@
+package password
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/rndpwd/internal/validator"
+)
+
+func TestConstraintsAllows(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		c    Constraints
+		pwd  string
+		want bool
+	}{
+		{"no constraints", Constraints{}, "aaaabc", true},
+		{"consecutive within limit", Constraints{MaxConsecutive: 2}, "aabaab", true},
+		{"consecutive above limit", Constraints{MaxConsecutive: 2}, "abaaab", false},
+		{"per char within limit", Constraints{MaxPerChar: 2}, "abab", true},
+		{"per char above limit", Constraints{MaxPerChar: 2}, "abaca", false},
+		{"enough distinct", Constraints{MinDistinct: 3}, "abcabc", true},
+		{"not enough distinct", Constraints{MinDistinct: 3}, "ababab", false},
+		{"multi-byte distinct", Constraints{MinDistinct: 3}, "ααβγ", true},
+		{"no sequence", Constraints{NoSequences: true}, "a1b2c3", true},
+		{"alphabetic run", Constraints{NoSequences: true}, "x#abc", false},
+		{"descending alphabetic run", Constraints{NoSequences: true}, "x#CBA", false},
+		{"numeric run", Constraints{NoSequences: true}, "pwd123!", false},
+		{"descending numeric run", Constraints{NoSequences: true}, "9876", false},
+		{"keyboard walk", Constraints{NoSequences: true}, "Qwe!", false},
+		{"keyboard digits wrap", Constraints{NoSequences: true}, "a890", false},
+		{"short run", Constraints{NoSequences: true}, "ab#12", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, tt.c.Allows(tt.pwd))
+		})
+	}
+}
+
+func TestGenerateConstraints(t *testing.T) {
+	t.Parallel()
+
+	c := Constraints{MaxConsecutive: 1, MaxPerChar: 2, MinDistinct: 10, NoSequences: true}
+
+	p := New("0123456789abcdef", 12, 300, WithConstraints(c))
+	require.NoError(t, p.CheckPolicy())
+
+	pwds, err := p.Generate()
+	require.NoError(t, err)
+	require.Len(t, pwds, 300)
+
+	for _, pwd := range pwds {
+		require.True(t, c.Allows(pwd), pwd)
+	}
+}
+
+func TestConstraintsEntropy(t *testing.T) {
+	t.Parallel()
+
+	// 4 characters with at most 2 of each: 4^4 - 4 (all same) - 4*3*4 (three of one)
+	p := New("abcd", 4, 1, WithConstraints(Constraints{MaxPerChar: 2}))
+
+	bits, err := p.Entropy()
+	require.NoError(t, err)
+	require.InDelta(t, math.Log2(4*4*4*4-4-48), bits, 0.1)
+
+	base, err := New("abcd", 4, 1).Entropy()
+	require.NoError(t, err)
+	require.Less(t, bits, base)
+
+	// the estimate is reproducible
+	again, err := New("abcd", 4, 1, WithConstraints(Constraints{MaxPerChar: 2})).Entropy()
+	require.NoError(t, err)
+	require.InDelta(t, bits, again, 0)
+}
+
+func TestConstraintsModes(t *testing.T) {
+	t.Parallel()
+
+	c := Constraints{NoSequences: true}
+
+	for _, p := range []*Password{
+		New(validator.ValidCharset, 16, 50, WithMode(ModePronounceable), WithConstraints(c)),
+		New(validator.ValidCharset, 16, 50, WithMode(ModeTemplate), WithTemplate("9{6}"), WithConstraints(c)),
+		New(validator.ValidCharset, 16, 50, WithPolicy(Policy{MinDigit: 8}), WithConstraints(c)),
+	} {
+		pwds, err := p.Generate()
+		require.NoError(t, err)
+
+		for _, pwd := range pwds {
+			require.True(t, c.Allows(pwd), pwd)
+		}
+	}
+}
+
+func TestConstraintsError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		p    *Password
+	}{
+		{
+			name: "more distinct characters than the charset",
+			p:    New("abc", 8, 1, WithConstraints(Constraints{MinDistinct: 4})),
+		},
+		{
+			name: "more distinct characters than the length",
+			p:    New("abcdef", 3, 1, WithConstraints(Constraints{MinDistinct: 4})),
+		},
+		{
+			name: "per char limit below the length",
+			p:    New("ab", 5, 1, WithConstraints(Constraints{MaxPerChar: 2})),
+		},
+		{
+			name: "template with a sequence",
+			p:    New("ab", 5, 1, WithMode(ModeTemplate), WithTemplate(`\a\b\c9`), WithConstraints(Constraints{NoSequences: true})),
+		},
+		{
+			name: "length too long",
+			p:    New("ab", MaxConstrainedLength+1, 1, WithConstraints(Constraints{MaxConsecutive: 8})),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.ErrorIs(t, tt.p.CheckPolicy(), errConstraints)
+
+			_, err := tt.p.Generate()
+			require.ErrorIs(t, err, errConstraints)
+
+			_, err = tt.p.Entropy()
+			require.ErrorIs(t, err, errConstraints)
+		})
+	}
+}
+
+func TestConstraintsDetail(t *testing.T) {
+	t.Parallel()
+
+	p := New("abcdef", 6, 2, WithConstraints(Constraints{MaxConsecutive: 1}))
+
+	details, err := p.GenerateDetails()
+	require.NoError(t, err)
+
+	for _, d := range details {
+		require.NotNil(t, d.Constraints)
+		require.Equal(t, 1, d.Constraints.MaxConsecutive)
+		require.True(t, d.Constraints.Allows(d.Password), d.Password)
+	}
+}
+
+func TestConstraintsCache(t *testing.T) {
+	t.Parallel()
+
+	c := Constraints{MaxConsecutive: 2, NoSequences: true}
+
+	hits, err := New("klmnop0123456789", 11, 1, WithConstraints(c)).checkConstraints()
+	require.NoError(t, err)
+
+	key := constraintsKey{charset: "klmnop0123456789", length: 11, mode: ModeRandom, maxConsecutive: 2, noSequences: true}
+
+	est, ok := constraintsCache.get(key)
+
+	require.True(t, ok)
+	require.Equal(t, hits, est.hits)
+
+	again, err := New("klmnop0123456789", 11, 1, WithConstraints(c)).checkConstraints()
+	require.NoError(t, err)
+	require.Equal(t, hits, again)
+
+	_, err = New("klmno", 11, 1, WithConstraints(Constraints{MinDistinct: 6})).checkConstraints()
+	require.ErrorIs(t, err, errConstraints)
+
+	_, err = New("klmno", 11, 1, WithConstraints(Constraints{MinDistinct: 6})).checkConstraints()
+	require.ErrorIs(t, err, errConstraints)
+}
@@ -0,0 +1,117 @@
+package password
+
+import (
+	"math/big"
+)
+
+// Detail contains a generated password along with the strength of the
+// generator that produced it.
+type Detail struct {
+	// Password is the generated password.
+	Password string `json:"password"`
+
+	// Entropy is the entropy of the password in bits.
+	Entropy float64 `json:"entropy"`
+
+	// CharsetSize is the number of characters of the effective charset.
+	CharsetSize int `json:"charset_size"`
+
+	// Keyspace is the number of equally likely passwords, in base 10. It is
+	// an estimate when repetition and sequence constraints are set.
+	Keyspace string `json:"keyspace"`
+
+	// Mode is the generation mode.
+	Mode string `json:"mode"`
+
+	// Policy is the character-class policy, when set.
+	Policy *Policy `json:"policy,omitempty"`
+
+	// Constraints are the repetition and sequence constraints, when set.
+	Constraints *Constraints `json:"constraints,omitempty"`
+
+	// Hash is the hash of the password, when requested.
+	Hash string `json:"hash,omitempty"`
+}
+
+// Keyspace returns the number of distinct passwords that can be generated,
+// all equally likely. With repetition and sequence constraints it is an
+// estimate.
+func (p *Password) Keyspace() (*big.Int, error) {
+	n, err := p.baseKeyspace()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.Constraints.IsSet() {
+		return n, nil
+	}
+
+	return p.constrainedKeyspace(n)
+}
+
+// baseKeyspace returns the number of passwords of the mode and policy, before
+// the repetition and sequence constraints are applied.
+func (p *Password) baseKeyspace() (*big.Int, error) {
+	if !p.constrained() {
+		if p.charset == "" {
+			return nil, errEmptyCharset
+		}
+
+		n := big.NewInt(int64(len(p.runes)))
+		return n.Exp(n, big.NewInt(int64(p.Length)), nil), nil
+	}
+
+	s, err := p.prepare()
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).Set(s.size()), nil
+}
+
+// GenerateDetails returns the specified amount of random passwords, each with
+// its entropy and keyspace.
+func (p *Password) GenerateDetails() ([]Detail, error) {
+	keyspace, err := p.Keyspace()
+	if err != nil {
+		return nil, err
+	}
+
+	pwds, err := p.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	var pol *Policy
+
+	if p.Policy.IsSet() {
+		cp := p.Policy
+		pol = &cp
+	}
+
+	var cons *Constraints
+
+	if p.Constraints.IsSet() {
+		cp := p.Constraints
+		cons = &cp
+	}
+
+	bits := log2Big(keyspace)
+	size := keyspace.String()
+
+	lst := make([]Detail, len(pwds))
+
+	for i, pwd := range pwds {
+		lst[i] = Detail{
+			Password:    pwd,
+			Entropy:     bits,
+			CharsetSize: p.CharsetSize(),
+			Keyspace:    size,
+			Mode:        p.Mode,
+			Policy:      pol,
+			Constraints: cons,
+		}
+	}
+
+	return lst, nil
+}
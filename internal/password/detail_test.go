@@ -0,0 +1,98 @@
+package password
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateDetails(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		p            *Password
+		wantCharset  int
+		wantKeyspace string
+		wantPolicy   bool
+	}{
+		{
+			name:         "random",
+			p:            New("00112233445566778899aabbccddeeff", 8, 3),
+			wantCharset:  16,
+			wantKeyspace: "4294967296",
+		},
+		{
+			name:         "policy",
+			p:            New("AB123", 4, 3, WithPolicy(Policy{MinUpper: 1, MinDigit: 1})),
+			wantCharset:  5,
+			wantKeyspace: "528",
+			wantPolicy:   true,
+		},
+		{
+			name:         "pronounceable",
+			p:            New("bai", 2, 3, WithMode(ModePronounceable)),
+			wantCharset:  3,
+			wantKeyspace: "5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			details, err := tt.p.GenerateDetails()
+			require.NoError(t, err)
+			require.Len(t, details, 3)
+
+			bits, err := tt.p.Entropy()
+			require.NoError(t, err)
+
+			for _, d := range details {
+				require.Len(t, d.Password, tt.p.Length)
+				require.Equal(t, tt.wantCharset, d.CharsetSize)
+				require.Equal(t, tt.wantKeyspace, d.Keyspace)
+				require.InDelta(t, bits, d.Entropy, 1e-12)
+				require.Equal(t, tt.p.Mode, d.Mode)
+				require.Equal(t, tt.wantPolicy, d.Policy != nil)
+			}
+		})
+	}
+}
+
+func TestKeyspace(t *testing.T) {
+	t.Parallel()
+
+	ks, err := New("ab", 100, 1).Keyspace()
+	require.NoError(t, err)
+	require.Equal(t, 101, ks.BitLen())
+
+	bits, err := New("ab", 100, 1).Entropy()
+	require.NoError(t, err)
+	require.InDelta(t, 100.0, bits, 1e-9)
+
+	bits, err = New("0123456789", 4096, 1).Entropy()
+	require.NoError(t, err)
+	require.InDelta(t, 4096*math.Log2(10), bits, 1e-6)
+
+	_, err = New("abc", 8, 1, WithPolicy(Policy{MinUpper: 1})).Keyspace()
+	require.ErrorIs(t, err, errPolicy)
+}
+
+func TestGenerateDetailsError(t *testing.T) {
+	t.Parallel()
+
+	details, err := New("abc", 8, 1, WithPolicy(Policy{MinUpper: 1})).GenerateDetails()
+	require.ErrorIs(t, err, errPolicy)
+	require.Nil(t, details)
+
+	p := New("abc", 8, 1, WithPolicy(Policy{MinLower: 1}))
+	p.reader = iotest.ErrReader(errors.New("rng failure"))
+
+	details, err = p.GenerateDetails()
+	require.Error(t, err)
+	require.Nil(t, details)
+}
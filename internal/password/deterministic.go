@@ -0,0 +1,45 @@
+package password
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math"
+)
+
+// SourceDeterministic is the name of the seeded test-only source.
+const SourceDeterministic = "deterministic"
+
+// ErrDeterministicDisabled is returned when a deterministic source is requested
+// while the deterministic test mode is disabled.
+var ErrDeterministicDisabled = errors.New("the deterministic test mode is disabled")
+
+// deterministicSource is a ChaCha20 DRBG keyed by the seed and never reseeded,
+// so its output only depends on the seed.
+type deterministicSource struct {
+	chacha20Source
+}
+
+// NewDeterministicSource returns a test-only source whose output is fully
+// reproducible from the seed. As its output must never be used as a secret,
+// it refuses to start, returning ErrDeterministicDisabled, unless the
+// deterministic test mode is explicitly enabled.
+func NewDeterministicSource(seed string, enabled bool) (Source, error) {
+	if !enabled {
+		return nil, ErrDeterministicDisabled
+	}
+
+	key := sha256.Sum256([]byte(seed))
+
+	return &deterministicSource{
+		chacha20Source: chacha20Source{
+			seed:     bytes.NewReader(key[:]),
+			interval: math.MaxInt,
+		},
+	}, nil
+}
+
+// Name implements Source.
+func (s *deterministicSource) Name() string {
+	return SourceDeterministic
+}
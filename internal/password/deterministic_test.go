@@ -0,0 +1,33 @@
+package password
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDeterministicSource(t *testing.T) {
+	t.Parallel()
+
+	src, err := NewDeterministicSource("seed", false)
+	require.ErrorIs(t, err, ErrDeterministicDisabled)
+	require.Nil(t, src)
+
+	generate := func(seed string) []string {
+		t.Helper()
+
+		src, err := NewDeterministicSource(seed, true)
+		require.NoError(t, err)
+		require.Equal(t, SourceDeterministic, src.Name())
+
+		pwds, err := New("alnum", 24, 3, WithSource(src)).Generate()
+		require.NoError(t, err)
+
+		return pwds
+	}
+
+	first := generate("seed")
+	require.Equal(t, first, generate("seed"))
+	require.NotEqual(t, first, generate("other"))
+	require.NotEqual(t, first[0], first[1])
+}
@@ -0,0 +1,312 @@
+package password
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Types of one-time passwords.
+const (
+	// OTPTypeTOTP is the time-based one-time password of RFC 6238.
+	OTPTypeTOTP = "totp"
+
+	// OTPTypeHOTP is the counter-based one-time password of RFC 4226.
+	OTPTypeHOTP = "hotp"
+)
+
+// HMAC algorithms of the one-time passwords.
+const (
+	OTPAlgorithmSHA1   = "SHA1"
+	OTPAlgorithmSHA256 = "SHA256"
+	OTPAlgorithmSHA512 = "SHA512"
+)
+
+// MaxOTPWindow is the maximum number of adjacent codes computed on each side of
+// the current one.
+const MaxOTPWindow = 10
+
+// errOTP is wrapped by all the errors reporting invalid one-time password
+// settings.
+var errOTP = errors.New("invalid one-time password settings")
+
+// otpEncoding is the base32 encoding of the secrets, without the padding that
+// the authenticator apps don't expect.
+var otpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding) //nolint:gochecknoglobals
+
+// OTP contains the one-time password secret generator configuration.
+//
+// The secrets have the size of the HMAC output, as recommended by RFC 4226: 20
+// bytes for SHA1, 32 for SHA256 and 64 for SHA512.
+type OTP struct {
+	Type      string `json:"type"      validate:"required,oneof=totp hotp"`
+	Issuer    string `json:"issuer"    validate:"max=256,excludes=:"`
+	Account   string `json:"account"   validate:"required,max=256,excludes=:"`
+	Algorithm string `json:"algorithm" validate:"required,oneof=SHA1 SHA256 SHA512"`
+	Digits    int    `json:"digits"    validate:"required,min=6,max=8"`
+	Period    int    `json:"period"    validate:"required,min=1,max=3600"`
+	Counter   int64  `json:"counter"   validate:"min=0"`
+	reader    io.Reader
+}
+
+// OTPKey is a provisioned one-time password secret.
+type OTPKey struct {
+	// Type is the one-time password type.
+	Type string `json:"type"`
+
+	// Secret is the base32 shared secret.
+	Secret string `json:"secret"`
+
+	// URI is the otpauth:// key URI, as encoded in the QR codes of the
+	// authenticator apps.
+	URI string `json:"uri"`
+
+	// Issuer is the provider of the account.
+	Issuer string `json:"issuer,omitempty"`
+
+	// Account is the name of the account.
+	Account string `json:"account"`
+
+	// Algorithm is the HMAC algorithm.
+	Algorithm string `json:"algorithm"`
+
+	// Digits is the number of digits of the codes.
+	Digits int `json:"digits"`
+
+	// Period is the validity of the TOTP codes in seconds.
+	Period int `json:"period,omitempty"`
+
+	// Counter is the initial HOTP counter.
+	Counter *int64 `json:"counter,omitempty"`
+}
+
+// OTPCode is a one-time password code.
+type OTPCode struct {
+	// Offset is the position of the code relative to the current one.
+	Offset int `json:"offset"`
+
+	// Counter is the moving factor of the code: the HOTP counter, or the TOTP
+	// time step.
+	Counter int64 `json:"counter"`
+
+	// Code is the one-time password.
+	Code string `json:"code"`
+}
+
+// OTPOption is a type to allow setting custom one-time password options.
+type OTPOption func(o *OTP)
+
+// WithOTPType sets the one-time password type (default OTPTypeTOTP).
+func WithOTPType(typ string) OTPOption {
+	return func(o *OTP) {
+		o.Type = typ
+	}
+}
+
+// WithOTPIssuer sets the provider of the account, shown by the authenticator
+// apps.
+func WithOTPIssuer(issuer string) OTPOption {
+	return func(o *OTP) {
+		o.Issuer = issuer
+	}
+}
+
+// WithOTPAlgorithm sets the HMAC algorithm (default OTPAlgorithmSHA1, the only
+// one supported by some authenticator apps).
+func WithOTPAlgorithm(alg string) OTPOption {
+	return func(o *OTP) {
+		o.Algorithm = alg
+	}
+}
+
+// WithOTPDigits sets the number of digits of the codes (default 6).
+func WithOTPDigits(digits int) OTPOption {
+	return func(o *OTP) {
+		o.Digits = digits
+	}
+}
+
+// WithOTPPeriod sets the validity of the TOTP codes in seconds (default 30).
+func WithOTPPeriod(period int) OTPOption {
+	return func(o *OTP) {
+		o.Period = period
+	}
+}
+
+// WithOTPCounter sets the initial HOTP counter (default 0).
+func WithOTPCounter(counter int64) OTPOption {
+	return func(o *OTP) {
+		o.Counter = counter
+	}
+}
+
+// WithOTPSource sets the entropy source of the secrets (default the OS
+// CSPRNG).
+func WithOTPSource(src Source) OTPOption {
+	return func(o *OTP) {
+		o.reader = src
+	}
+}
+
+// NewOTP instantiate a new one-time password secret generator object.
+func NewOTP(account string, opts ...OTPOption) *OTP {
+	o := &OTP{
+		Type:      OTPTypeTOTP,
+		Account:   account,
+		Algorithm: OTPAlgorithmSHA1,
+		Digits:    6,
+		Period:    30,
+		reader:    rand.Reader,
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(o)
+	}
+
+	return o
+}
+
+// Generate returns a new random secret along with its key URI.
+func (o *OTP) Generate() (OTPKey, error) {
+	newHash, err := otpHash(o.Algorithm)
+	if err != nil {
+		return OTPKey{}, err
+	}
+
+	secret := make([]byte, newHash().Size())
+
+	_, err = io.ReadFull(o.reader, secret)
+	if err != nil {
+		return OTPKey{}, fmt.Errorf("failed reading the OTP secret: %w", err)
+	}
+
+	key := OTPKey{
+		Type:      o.Type,
+		Secret:    otpEncoding.EncodeToString(secret),
+		Issuer:    o.Issuer,
+		Account:   o.Account,
+		Algorithm: o.Algorithm,
+		Digits:    o.Digits,
+	}
+
+	if o.Type == OTPTypeHOTP {
+		counter := o.Counter
+		key.Counter = &counter
+	} else {
+		key.Period = o.Period
+	}
+
+	key.URI = o.uri(key.Secret)
+
+	return key, nil
+}
+
+// uri returns the otpauth:// key URI of the secret, in the Key Uri Format of
+// Google Authenticator. The issuer is set both as the label prefix and as a
+// parameter, as recommended for compatibility.
+func (o *OTP) uri(secret string) string {
+	label := url.PathEscape(o.Account)
+	params := url.Values{}
+
+	params.Set("secret", secret)
+	params.Set("algorithm", o.Algorithm)
+	params.Set("digits", strconv.Itoa(o.Digits))
+
+	if o.Issuer != "" {
+		label = url.PathEscape(o.Issuer) + ":" + label
+		params.Set("issuer", o.Issuer)
+	}
+
+	if o.Type == OTPTypeHOTP {
+		params.Set("counter", strconv.FormatInt(o.Counter, 10))
+	} else {
+		params.Set("period", strconv.Itoa(o.Period))
+	}
+
+	return "otpauth://" + o.Type + "/" + label + "?" + strings.ReplaceAll(params.Encode(), "+", "%20")
+}
+
+// Codes returns the code of the given secret at the current moving factor,
+// along with the window adjacent codes on each side. The moving factor is the
+// HOTP counter, or the TOTP time step of the time.
+func (o *OTP) Codes(secret string, at time.Time, window int) ([]OTPCode, error) {
+	if window < 0 || window > MaxOTPWindow {
+		return nil, fmt.Errorf("%w: the window must be between 0 and %d", errOTP, MaxOTPWindow)
+	}
+
+	key, err := otpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(strings.ReplaceAll(secret, " ", ""), "=")))
+	if err != nil || len(key) == 0 {
+		return nil, fmt.Errorf("%w: the secret is not valid base32", errOTP)
+	}
+
+	newHash, err := otpHash(o.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	counter := o.Counter
+
+	if o.Type != OTPTypeHOTP {
+		if o.Period < 1 {
+			return nil, fmt.Errorf("%w: the period must be positive", errOTP)
+		}
+
+		counter = at.Unix() / int64(o.Period)
+	}
+
+	codes := make([]OTPCode, 0, 2*window+1)
+
+	for offset := -window; offset <= window; offset++ {
+		c := counter + int64(offset)
+		if c < 0 {
+			continue
+		}
+
+		codes = append(codes, OTPCode{
+			Offset:  offset,
+			Counter: c,
+			Code:    hotp(newHash, key, uint64(c), o.Digits),
+		})
+	}
+
+	return codes, nil
+}
+
+// otpHash returns the hash function of the HMAC algorithm.
+func otpHash(alg string) (func() hash.Hash, error) {
+	switch alg {
+	case OTPAlgorithmSHA1:
+		return sha1.New, nil
+	case OTPAlgorithmSHA256:
+		return sha256.New, nil
+	case OTPAlgorithmSHA512:
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown algorithm %q", errOTP, alg)
+	}
+}
+
+// hotp returns the RFC 4226 code of the key at the counter: the dynamically
+// truncated HMAC of the counter, modulo 10^digits.
+func hotp(newHash func() hash.Hash, key []byte, counter uint64, digits int) string {
+	mac := hmac.New(newHash, key)
+	_ = binary.Write(mac, binary.BigEndian, counter)
+	sum := mac.Sum(nil)
+
+	off := sum[len(sum)-1] & 0x0f
+	bin := uint64(binary.BigEndian.Uint32(sum[off:off+4]) & 0x7fffffff)
+
+	return fmt.Sprintf("%0*d", digits, bin%uint64(pow10(digits))) //nolint:gosec
+}
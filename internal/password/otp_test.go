@@ -0,0 +1,199 @@
+package password
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTPCodesHOTP(t *testing.T) {
+	t.Parallel()
+
+	// RFC 4226 Appendix D
+	secret := otpEncoding.EncodeToString([]byte("12345678901234567890"))
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+
+	o := NewOTP("test", WithOTPType(OTPTypeHOTP), WithOTPCounter(5))
+
+	codes, err := o.Codes(secret, time.Time{}, 5)
+	require.NoError(t, err)
+	require.Len(t, codes, 11)
+
+	for i, c := range codes[:len(want)] {
+		require.Equal(t, i-5, c.Offset)
+		require.Equal(t, int64(i), c.Counter)
+		require.Equal(t, want[i], c.Code)
+	}
+}
+
+func TestOTPCodesTOTP(t *testing.T) {
+	t.Parallel()
+
+	// RFC 6238 Appendix B
+	seeds := map[string]string{
+		OTPAlgorithmSHA1:   "12345678901234567890",
+		OTPAlgorithmSHA256: "12345678901234567890123456789012",
+		OTPAlgorithmSHA512: "1234567890123456789012345678901234567890123456789012345678901234",
+	}
+
+	tests := []struct {
+		unix int64
+		alg  string
+		code string
+	}{
+		{59, OTPAlgorithmSHA1, "94287082"},
+		{59, OTPAlgorithmSHA256, "46119246"},
+		{59, OTPAlgorithmSHA512, "90693936"},
+		{1111111109, OTPAlgorithmSHA1, "07081804"},
+		{1111111109, OTPAlgorithmSHA256, "68084774"},
+		{1111111109, OTPAlgorithmSHA512, "25091201"},
+		{1234567890, OTPAlgorithmSHA1, "89005924"},
+		{1234567890, OTPAlgorithmSHA256, "91819424"},
+		{1234567890, OTPAlgorithmSHA512, "93441116"},
+		{20000000000, OTPAlgorithmSHA1, "65353130"},
+		{20000000000, OTPAlgorithmSHA256, "77737706"},
+		{20000000000, OTPAlgorithmSHA512, "47863826"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.alg+"_"+tt.code, func(t *testing.T) {
+			t.Parallel()
+
+			o := NewOTP("test", WithOTPAlgorithm(tt.alg), WithOTPDigits(8))
+
+			codes, err := o.Codes(otpEncoding.EncodeToString([]byte(seeds[tt.alg])), time.Unix(tt.unix, 0), 0)
+			require.NoError(t, err)
+			require.Len(t, codes, 1)
+			require.Equal(t, tt.unix/30, codes[0].Counter)
+			require.Equal(t, tt.code, codes[0].Code)
+		})
+	}
+}
+
+func TestOTPCodesSecretFormat(t *testing.T) {
+	t.Parallel()
+
+	o := NewOTP("test", WithOTPType(OTPTypeHOTP))
+
+	// lowercase, grouped and padded secrets are accepted
+	codes, err := o.Codes("gezd gnbv gy3t qojq gezd gnbv gy3t qojq", time.Time{}, 0)
+	require.NoError(t, err)
+	require.Equal(t, "755224", codes[0].Code)
+
+	codes, err = o.Codes("GEZDGNBVGY3TQOJQ======", time.Time{}, 1)
+	require.NoError(t, err)
+	require.Len(t, codes, 2, "the negative counters are skipped")
+}
+
+func TestOTPCodesErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		otp    *OTP
+		secret string
+		window int
+	}{
+		{"invalid secret", NewOTP("test"), "GEZD1", 0},
+		{"empty secret", NewOTP("test"), " ", 0},
+		{"negative window", NewOTP("test"), "GEZDGNBV", -1},
+		{"large window", NewOTP("test"), "GEZDGNBV", MaxOTPWindow + 1},
+		{"unknown algorithm", NewOTP("test", WithOTPAlgorithm("MD5")), "GEZDGNBV", 0},
+		{"zero period", NewOTP("test", WithOTPPeriod(0)), "GEZDGNBV", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := tt.otp.Codes(tt.secret, time.Now(), tt.window)
+			require.ErrorIs(t, err, errOTP)
+		})
+	}
+}
+
+func TestOTPGenerate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts []OTPOption
+		want OTPKey
+	}{
+		{
+			name: "totp",
+			opts: []OTPOption{WithOTPIssuer("Example Co")},
+			want: OTPKey{
+				Type:      OTPTypeTOTP,
+				Secret:    strings.Repeat("A", 32),
+				URI:       "otpauth://totp/Example%20Co:alice@example.com?algorithm=SHA1&digits=6&issuer=Example%20Co&period=30&secret=" + strings.Repeat("A", 32),
+				Issuer:    "Example Co",
+				Account:   "alice@example.com",
+				Algorithm: OTPAlgorithmSHA1,
+				Digits:    6,
+				Period:    30,
+			},
+		},
+		{
+			name: "hotp",
+			opts: []OTPOption{WithOTPType(OTPTypeHOTP), WithOTPAlgorithm(OTPAlgorithmSHA512), WithOTPDigits(8), WithOTPCounter(7)},
+			want: OTPKey{
+				Type:      OTPTypeHOTP,
+				Secret:    strings.Repeat("A", 103),
+				URI:       "otpauth://hotp/alice@example.com?algorithm=SHA512&counter=7&digits=8&secret=" + strings.Repeat("A", 103),
+				Account:   "alice@example.com",
+				Algorithm: OTPAlgorithmSHA512,
+				Digits:    8,
+				Counter:   new(int64(7)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			o := NewOTP("alice@example.com", tt.opts...)
+			o.reader = bytes.NewReader(make([]byte, 64))
+
+			key, err := o.Generate()
+			require.NoError(t, err)
+			require.Equal(t, tt.want, key)
+		})
+	}
+}
+
+func TestOTPGenerateRandom(t *testing.T) {
+	t.Parallel()
+
+	a, err := NewOTP("test").Generate()
+	require.NoError(t, err)
+
+	b, err := NewOTP("test").Generate()
+	require.NoError(t, err)
+
+	require.Len(t, a.Secret, 32)
+	require.NotEqual(t, a.Secret, b.Secret)
+
+	// the generated secret computes codes
+	_, err = NewOTP("test").Codes(a.Secret, time.Now(), 1)
+	require.NoError(t, err)
+}
+
+func TestOTPGenerateErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewOTP("test", WithOTPAlgorithm("MD5")).Generate()
+	require.ErrorIs(t, err, errOTP)
+
+	o := NewOTP("test")
+	o.reader = iotest.ErrReader(errors.New("rng failure"))
+
+	_, err = o.Generate()
+	require.Error(t, err)
+}
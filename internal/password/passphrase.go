@@ -0,0 +1,240 @@
+package password
+
+import (
+	"crypto/rand"
+	_ "embed" // embeds the wordlists
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// Names of the embedded wordlists.
+const (
+	// WordlistEFFLarge is the EFF large wordlist (7776 words, five dice).
+	WordlistEFFLarge = "eff_large"
+
+	// WordlistEFFShort is the EFF short wordlist 2.0 (1296 words, four dice).
+	WordlistEFFShort = "eff_short"
+)
+
+// Capitalization modes of the passphrase words.
+const (
+	CapitalizeNone   = "none"
+	CapitalizeFirst  = "first"
+	CapitalizeUpper  = "upper"
+	CapitalizeRandom = "random"
+)
+
+// PassphraseSymbols contains the symbols that can be appended to a passphrase.
+// They are easy to spell out loud.
+const PassphraseSymbols = "!#$%&*+=?@"
+
+// The wordlists are the EFF Dice-Generated Passphrase lists
+// (https://www.eff.org/dice), by the Electronic Frontier Foundation, CC BY 3.0
+// US license, see wordlist/NOTICE.
+var (
+	//go:embed wordlist/eff_large_wordlist.txt
+	effLargeWordlist string //nolint:gochecknoglobals
+
+	//go:embed wordlist/eff_short_wordlist_2_0.txt
+	effShortWordlist string //nolint:gochecknoglobals
+
+	// wordlists maps the wordlist names to their words.
+	wordlists = map[string][]string{ //nolint:gochecknoglobals
+		WordlistEFFLarge: parseWordlist(effLargeWordlist),
+		WordlistEFFShort: parseWordlist(effShortWordlist),
+	}
+)
+
+// parseWordlist extracts the words from a diceware list, where each line
+// contains the dice roll followed by the word.
+func parseWordlist(data string) []string {
+	lines := strings.Split(strings.TrimSpace(data), "\n")
+	words := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		words = append(words, fields[len(fields)-1])
+	}
+
+	return words
+}
+
+// Passphrase contains the diceware passphrase generator configuration.
+//
+// The separator can't contain letters or dashes, as they appear in the words,
+// so every passphrase maps back to a single sequence of words and the reported
+// entropy is exact.
+type Passphrase struct {
+	Wordlist   string `json:"wordlist"   validate:"required,oneof=eff_large eff_short"`
+	Words      int    `json:"words"      validate:"required,min=1,max=32"`
+	Separator  string `json:"separator"  validate:"required,max=3,printascii,excludesall=-ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"`
+	Capitalize string `json:"capitalize" validate:"required,oneof=none first upper random"`
+	Digit      bool   `json:"digit"`
+	Symbol     bool   `json:"symbol"`
+	Quantity   int    `json:"quantity"   validate:"required,min=1,max=1000"`
+	reader     io.Reader
+}
+
+// PassphraseOption is a type to allow setting custom passphrase options.
+type PassphraseOption func(p *Passphrase)
+
+// WithSeparator sets the string placed between the words (default a space).
+func WithSeparator(sep string) PassphraseOption {
+	return func(p *Passphrase) {
+		p.Separator = sep
+	}
+}
+
+// WithCapitalize sets the capitalization mode of the words (default none).
+// The random mode capitalizes the first letter of each word with probability
+// 1/2, adding one bit of entropy per word.
+func WithCapitalize(mode string) PassphraseOption {
+	return func(p *Passphrase) {
+		p.Capitalize = mode
+	}
+}
+
+// WithDigit appends a random digit to each passphrase.
+func WithDigit(enable bool) PassphraseOption {
+	return func(p *Passphrase) {
+		p.Digit = enable
+	}
+}
+
+// WithSymbol appends a random symbol from PassphraseSymbols to each
+// passphrase, after the digit if any.
+func WithSymbol(enable bool) PassphraseOption {
+	return func(p *Passphrase) {
+		p.Symbol = enable
+	}
+}
+
+// WithPassphraseSource sets the entropy source of the generator (default the
+// OS CSPRNG).
+func WithPassphraseSource(src Source) PassphraseOption {
+	return func(p *Passphrase) {
+		p.reader = src
+	}
+}
+
+// NewPassphrase instantiate a new Passphrase generator object.
+func NewPassphrase(wordlist string, words, quantity int, opts ...PassphraseOption) *Passphrase {
+	p := &Passphrase{
+		Wordlist:   wordlist,
+		Words:      words,
+		Separator:  " ",
+		Capitalize: CapitalizeNone,
+		Quantity:   quantity,
+		reader:     rand.Reader,
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(p)
+	}
+
+	return p
+}
+
+// WordlistSize returns the number of words in the selected wordlist.
+func (p *Passphrase) WordlistSize() int {
+	return len(wordlists[p.Wordlist])
+}
+
+// Entropy returns the entropy in bits of each passphrase.
+func (p *Passphrase) Entropy() float64 {
+	bits := float64(p.Words) * math.Log2(float64(p.WordlistSize()))
+
+	if p.Capitalize == CapitalizeRandom {
+		bits += float64(p.Words)
+	}
+
+	if p.Digit {
+		bits += math.Log2(10)
+	}
+
+	if p.Symbol {
+		bits += math.Log2(float64(len(PassphraseSymbols)))
+	}
+
+	return bits
+}
+
+// Generate returns the specified amount of random passphrases.
+func (p *Passphrase) Generate() ([]string, error) {
+	if p.WordlistSize() == 0 {
+		return nil, fmt.Errorf("unknown wordlist: %q", p.Wordlist)
+	}
+
+	lst := make([]string, p.Quantity)
+
+	for i := range p.Quantity {
+		s, err := p.generateOne()
+		if err != nil {
+			return nil, fmt.Errorf("failed generating random passphrase: %w", err)
+		}
+
+		lst[i] = s
+	}
+
+	return lst, nil
+}
+
+func (p *Passphrase) generateOne() (string, error) {
+	list := wordlists[p.Wordlist]
+	words := make([]string, p.Words)
+
+	for i := range words {
+		n, err := randInt(p.reader, len(list))
+		if err != nil {
+			return "", err
+		}
+
+		words[i], err = p.capitalize(list[n])
+		if err != nil {
+			return "", err
+		}
+	}
+
+	s := strings.Join(words, p.Separator)
+
+	if p.Digit {
+		n, err := randInt(p.reader, 10)
+		if err != nil {
+			return "", err
+		}
+
+		s += string(rune('0' + n))
+	}
+
+	if p.Symbol {
+		n, err := randInt(p.reader, len(PassphraseSymbols))
+		if err != nil {
+			return "", err
+		}
+
+		s += PassphraseSymbols[n : n+1]
+	}
+
+	return s, nil
+}
+
+// capitalize applies the capitalization mode to a lowercase word.
+func (p *Passphrase) capitalize(word string) (string, error) {
+	switch p.Capitalize {
+	case CapitalizeFirst:
+		return strings.ToUpper(word[:1]) + word[1:], nil
+	case CapitalizeUpper:
+		return strings.ToUpper(word), nil
+	case CapitalizeRandom:
+		n, err := randInt(p.reader, 2)
+		if err != nil || n == 0 {
+			return word, err
+		}
+
+		return strings.ToUpper(word[:1]) + word[1:], nil
+	default:
+		return word, nil
+	}
+}
@@ -0,0 +1,189 @@
+package password
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWordlists(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		size  int
+		first string
+		last  string
+	}{
+		{WordlistEFFLarge, 7776, "abacus", "zoom"},
+		{WordlistEFFShort, 1296, "aardvark", "zucchini"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			list := wordlists[tt.name]
+			require.Len(t, list, tt.size)
+			require.Equal(t, tt.first, list[0])
+			require.Equal(t, tt.last, list[len(list)-1])
+
+			seen := make(map[string]bool, len(list))
+
+			for _, w := range list {
+				require.False(t, seen[w], "duplicate word %q", w)
+				require.Equal(t, strings.Trim(w, "abcdefghijklmnopqrstuvwxyz-"), "", "unexpected character in %q", w)
+
+				seen[w] = true
+			}
+		})
+	}
+}
+
+func TestPassphraseEntropy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		p    *Passphrase
+		want float64
+	}{
+		{
+			name: "eff large",
+			p:    NewPassphrase(WordlistEFFLarge, 6, 1),
+			want: 6 * math.Log2(7776),
+		},
+		{
+			name: "eff short",
+			p:    NewPassphrase(WordlistEFFShort, 4, 1),
+			want: 4 * math.Log2(1296),
+		},
+		{
+			name: "fixed capitalization adds nothing",
+			p:    NewPassphrase(WordlistEFFShort, 4, 1, WithCapitalize(CapitalizeUpper)),
+			want: 4 * math.Log2(1296),
+		},
+		{
+			name: "random capitalization",
+			p:    NewPassphrase(WordlistEFFShort, 4, 1, WithCapitalize(CapitalizeRandom)),
+			want: 4*math.Log2(1296) + 4,
+		},
+		{
+			name: "digit and symbol",
+			p:    NewPassphrase(WordlistEFFLarge, 5, 1, WithDigit(true), WithSymbol(true)),
+			want: 5*math.Log2(7776) + math.Log2(10) + math.Log2(float64(len(PassphraseSymbols))),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.InDelta(t, tt.want, tt.p.Entropy(), 1e-9)
+		})
+	}
+}
+
+func TestPassphraseGenerate(t *testing.T) {
+	t.Parallel()
+
+	p := NewPassphrase(
+		WordlistEFFShort,
+		4,
+		50,
+		WithSeparator("."),
+		WithCapitalize(CapitalizeFirst),
+		WithDigit(true),
+		WithSymbol(true),
+	)
+
+	lst, err := p.Generate()
+	require.NoError(t, err)
+	require.Len(t, lst, 50)
+
+	words := make(map[string]bool)
+	for _, w := range wordlists[WordlistEFFShort] {
+		words[w] = true
+	}
+
+	for _, s := range lst {
+		require.Contains(t, PassphraseSymbols, s[len(s)-1:])
+		require.Contains(t, "0123456789", s[len(s)-2:len(s)-1])
+
+		parts := strings.Split(s[:len(s)-2], ".")
+		require.Len(t, parts, 4)
+
+		for _, part := range parts {
+			require.Equal(t, strings.ToUpper(part[:1]), part[:1])
+			require.True(t, words[strings.ToLower(part)], "unknown word %q", part)
+		}
+	}
+}
+
+func TestPassphraseCapitalize(t *testing.T) {
+	t.Parallel()
+
+	modes := map[string]string{
+		CapitalizeNone:  "yo-yo",
+		CapitalizeFirst: "Yo-yo",
+		CapitalizeUpper: "YO-YO",
+	}
+
+	for mode, want := range modes {
+		p := NewPassphrase(WordlistEFFShort, 1, 1, WithCapitalize(mode))
+
+		got, err := p.capitalize("yo-yo")
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+
+	p := NewPassphrase(WordlistEFFShort, 1, 1, WithCapitalize(CapitalizeRandom))
+	seen := make(map[string]bool)
+
+	for range 100 {
+		got, err := p.capitalize("yo-yo")
+		require.NoError(t, err)
+
+		seen[got] = true
+	}
+
+	require.Equal(t, map[string]bool{"yo-yo": true, "Yo-yo": true}, seen)
+}
+
+func TestPassphraseGenerateError(t *testing.T) {
+	t.Parallel()
+
+	p := NewPassphrase("unknown", 4, 1)
+
+	lst, err := p.Generate()
+	require.Error(t, err)
+	require.Nil(t, lst)
+
+	// each draw from the short wordlist reads 2 bytes, so a 2 bytes prefix lets
+	// the word through and fails the following draw
+	tests := []struct {
+		name   string
+		opt    PassphraseOption
+		prefix int
+	}{
+		{"word", WithDigit(false), 0},
+		{"capitalize", WithCapitalize(CapitalizeRandom), 2},
+		{"digit", WithDigit(true), 2},
+		{"symbol", WithSymbol(true), 2},
+	}
+
+	for _, tt := range tests {
+		p = NewPassphrase(WordlistEFFShort, 1, 1, tt.opt)
+		p.reader = io.MultiReader(bytes.NewReader(make([]byte, tt.prefix)), iotest.ErrReader(errors.New("rng failure")))
+
+		lst, err = p.Generate()
+		require.Error(t, err, tt.name)
+		require.Nil(t, lst, tt.name)
+	}
+}
@@ -2,60 +2,325 @@
 package password
 
 import (
+	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strings"
+	"sync"
+	"unicode/utf8"
 
 	"github.com/tecnickcom/nurago/pkg/random"
+	"github.com/tecnickcom/rndpwd/internal/charset"
+	"github.com/tecnickcom/rndpwd/internal/pattern"
 )
 
+// Generation modes.
+const (
+	// ModeRandom draws every character independently from the charset.
+	ModeRandom = "random"
+
+	// ModePronounceable alternates consonant and vowel sounds.
+	ModePronounceable = "pronounceable"
+
+	// ModeTemplate follows the fixed shape of a template.
+	ModeTemplate = "template"
+)
+
+// MaxConstrainedLength is the maximum password length supported when the output
+// space is constrained by a character-class policy, by the pronounceable mode
+// or by repetition and sequence constraints. It is also the maximum length of a
+// template. Counting the valid passwords grows quadratically with the length,
+// so the bound keeps each request cheap.
+const MaxConstrainedLength = 256
+
+// AmbiguousChars contains the characters that are easily mistaken for one
+// another when printed or read aloud.
+const AmbiguousChars = "0Oo1lI|5S2Z'\"`"
+
+// errEmptyCharset is returned when no character is left to build the passwords.
+var errEmptyCharset = errors.New("empty effective charset")
+
 // Password contains the random generator configuration.
 type Password struct {
-	Charset  string `json:"charset"  validate:"required,min=1,max=256,rndcharset"`
-	Length   int    `json:"length"   validate:"required,min=1,max=4096"`
-	Quantity int    `json:"quantity" validate:"required,min=1,max=1000"`
-	rnd      *random.Rnd
+	Charset          charset.Set      `json:"charset"           validate:"required,min=1,max=256,rndcharset,rndeffcharset"`
+	Length           int              `json:"length"            validate:"required,min=1,max=4096"`
+	Quantity         int              `json:"quantity"          validate:"required,min=1,max=1000"`
+	Mode             string           `json:"mode"              validate:"required,oneof=random pronounceable template"`
+	Template         pattern.Template `json:"template"          validate:"required_if=Mode template,max=1024,rndtemplate"`
+	ExcludeAmbiguous bool             `json:"exclude_ambiguous"`
+	Policy           Policy           `json:"policy"            validate:"rndpolicy"`
+	Constraints      Constraints      `json:"constraints"`
+	Unique           bool             `json:"unique"`
+	charset          string           // effective charset
+	runes            []rune           // characters of the effective charset
+	rnd              *random.Rnd
+	reader           io.Reader
+	classes          []charClass
+	blocklist        Blocklist
+
+	// the sampler of the constrained modes is built once on first use
+	once       sync.Once
+	sampler    sampler
+	samplerErr error
+
+	// the constraints estimate is computed once on first use
+	constraintsOnce sync.Once
+	constraintsHits int
+	constraintsErr  error
 }
 
-// New instantiate a new Password generator object.
-func New(charset string, length, quantity int) *Password {
-	// Duplicate characters would bias the output toward them, so the effective
-	// charset only keeps the first occurrence of each character.
-	charset = dedupCharset(charset)
+// sampler draws passwords uniformly from a constrained output space.
+type sampler interface {
+	// sample returns a random password.
+	sample(reader io.Reader) (string, error)
+
+	// size returns the number of distinct passwords that can be drawn.
+	size() *big.Int
+}
+
+// Option is a type to allow setting custom generator options.
+type Option func(p *Password)
+
+// WithPolicy sets the character-class policy that every password must satisfy.
+func WithPolicy(policy Policy) Option {
+	return func(p *Password) {
+		p.Policy = policy
+	}
+}
+
+// WithMode sets the generation mode (default ModeRandom).
+func WithMode(mode string) Option {
+	return func(p *Password) {
+		p.Mode = mode
+	}
+}
 
-	return &Password{
-		Charset:  charset,
+// WithTemplate sets the template of the ModeTemplate, which defines the shape
+// of the passwords and overrides the length. See the pattern package for the
+// syntax.
+func WithTemplate(tpl string) Option {
+	return func(p *Password) {
+		p.Template = pattern.Template(tpl)
+	}
+}
+
+// WithExcludeAmbiguous removes the AmbiguousChars from the effective charset.
+func WithExcludeAmbiguous(enable bool) Option {
+	return func(p *Password) {
+		p.ExcludeAmbiguous = enable
+	}
+}
+
+// WithSource sets the entropy source of the generator (default the OS CSPRNG).
+func WithSource(src Source) Option {
+	return func(p *Password) {
+		p.reader = src
+	}
+}
+
+// New instantiate a new Password generator object.
+func New(charset string, length, quantity int, opts ...Option) *Password {
+	p := &Password{
+		Charset:  expandCharset(charset),
 		Length:   length,
 		Quantity: quantity,
-		rnd:      random.New(nil, random.WithByteToCharMap([]byte(charset))),
+		Mode:     ModeRandom,
+		reader:   rand.Reader,
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(p)
+	}
+
+	p.Policy = p.Policy.expand()
+	p.charset = string(p.Charset)
+
+	if p.ExcludeAmbiguous {
+		p.charset = removeChars(p.charset, AmbiguousChars)
+	}
+
+	p.runes = []rune(p.charset)
+
+	if len(p.runes) == len(p.charset) {
+		p.rnd = random.New(p.reader, random.WithByteToCharMap([]byte(p.charset)))
+	}
+
+	p.classes = p.Policy.classes(p.charset)
+
+	return p
+}
+
+// CharsetSize returns the number of characters of the effective charset, that
+// is the deduplicated charset without the excluded characters.
+func (p *Password) CharsetSize() int {
+	return len(p.runes)
+}
+
+// MaxSize returns the maximum size in bytes of the generated passwords, or zero
+// when the template is invalid.
+func (p *Password) MaxSize() int {
+	if p.Mode != ModeTemplate {
+		return p.Length * maxRuneLen(p.runes)
 	}
+
+	s, err := p.prepare()
+	tpl, ok := s.(*templateSampler)
+
+	if err != nil || !ok {
+		return 0
+	}
+
+	size := 0
+
+	for _, slot := range tpl.slots {
+		size += maxRuneLen(slot.Chars)
+	}
+
+	return size
+}
+
+// maxRuneLen returns the maximum UTF-8 size in bytes of the characters.
+func maxRuneLen(runes []rune) int {
+	n := 0
+
+	for _, c := range runes {
+		n = max(n, utf8.RuneLen(c))
+	}
+
+	return n
+}
+
+// expandCharset returns the characters of a preset, class expression or
+// literal charset. Duplicate characters would bias the output toward them, so
+// only the first occurrence of each character is kept.
+func expandCharset(value string) charset.Set {
+	return charset.Set(dedupCharset(charset.Expand(value)))
 }
 
-// dedupCharset removes duplicate bytes from the charset while preserving the
-// order of first appearance.
+// dedupCharset removes duplicate characters from the charset while preserving
+// the order of first appearance. Invalid UTF-8 bytes are kept as they are, so
+// the validation can reject them.
 func dedupCharset(charset string) string {
-	var seen [256]bool
+	seen := make(map[string]bool, len(charset))
+
+	var sb strings.Builder
 
-	out := make([]byte, 0, len(charset))
+	for i := 0; i < len(charset); {
+		_, size := utf8.DecodeRuneInString(charset[i:])
+		c := charset[i : i+size]
+		i += size
 
-	for i := range len(charset) {
-		c := charset[i]
 		if seen[c] {
 			continue
 		}
 
 		seen[c] = true
 
-		out = append(out, c)
+		sb.WriteString(c)
+	}
+
+	return sb.String()
+}
+
+// removeChars returns the charset without the characters listed in chars.
+func removeChars(charset, chars string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(chars, r) {
+			return -1
+		}
+
+		return r
+	}, charset)
+}
+
+// CheckCharset reports whether any character is left in the effective charset,
+// once expanded and without the excluded ambiguous characters.
+func (p *Password) CheckCharset() error {
+	if p.charset == "" {
+		return errEmptyCharset
+	}
+
+	return nil
+}
+
+// CheckPolicy reports whether the character-class policy, the pronounceable or
+// template mode, and the repetition and sequence constraints can be satisfied
+// by passwords of the effective charset and configured length. The reason of a
+// failure is also kept for the Policy.Problem method.
+func (p *Password) CheckPolicy() error {
+	err := p.checkPolicy()
+
+	p.Policy.problem = ""
+	if err != nil {
+		p.Policy.problem = err.Error()
+	}
+
+	return err
+}
+
+// checkPolicy implements CheckPolicy.
+func (p *Password) checkPolicy() error {
+	err := p.CheckCharset()
+	if err != nil {
+		return err
 	}
 
-	return string(out)
+	if p.Mode != ModeRandom && p.Policy.IsSet() {
+		return fmt.Errorf("%w: character-class policies are not supported in %s mode", errPolicy, p.Mode)
+	}
+
+	if p.constrained() {
+		_, err = p.prepare()
+		if err != nil {
+			return err
+		}
+	}
+
+	if !p.Constraints.IsSet() {
+		return nil
+	}
+
+	_, err = p.checkConstraints()
+
+	return err
 }
 
-// Generate returns the specified amount of random passwords.
+// Entropy returns the entropy in bits of each password, that is the base-2
+// logarithm of the number of equally likely passwords.
+func (p *Password) Entropy() (float64, error) {
+	keyspace, err := p.Keyspace()
+	if err != nil {
+		return 0, err
+	}
+
+	return log2Big(keyspace), nil
+}
+
+// Generate returns the specified amount of random passwords, all distinct when
+// the uniqueness is required.
 func (p *Password) Generate() ([]string, error) {
+	err := p.CheckUnique()
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := p.newSource()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Unique {
+		next = uniqueSource(p.Quantity, next)
+	}
+
 	lst := make([]string, p.Quantity)
 
 	for i := range p.Quantity {
-		s, err := p.rnd.RandString(p.Length)
+		s, err := next()
 		if err != nil {
 			return nil, fmt.Errorf("failed generating random password: %w", err)
 		}
@@ -65,3 +330,147 @@ func (p *Password) Generate() ([]string, error) {
 
 	return lst, nil
 }
+
+// Stream generates n passwords, ignoring the configured quantity and
+// uniqueness, and passes each one to emit as soon as it is generated, so the
+// whole set is never held in memory. It stops at the first error returned by
+// emit, or when the context is canceled.
+func (p *Password) Stream(ctx context.Context, n int, emit func(pwd string) error) error {
+	next, err := p.newSource()
+	if err != nil {
+		return err
+	}
+
+	for range n {
+		err = ctx.Err()
+		if err != nil {
+			return fmt.Errorf("password stream interrupted: %w", err)
+		}
+
+		s, err := next()
+		if err != nil {
+			return fmt.Errorf("failed generating random password: %w", err)
+		}
+
+		err = emit(s)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// constrained reports whether only a subset of the charset strings is valid.
+func (p *Password) constrained() bool {
+	return p.Mode == ModePronounceable || p.Mode == ModeTemplate || p.Policy.IsSet()
+}
+
+// newSource returns the function generating each password. The passwords
+// breaking the repetition and sequence constraints, or blocklisted, are drawn
+// again.
+func (p *Password) newSource() (func() (string, error), error) {
+	next, err := p.newBaseSource(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Constraints.IsSet() {
+		_, err = p.checkConstraints()
+		if err != nil {
+			return nil, err
+		}
+
+		next = p.Constraints.constrainedSource(next)
+	}
+
+	if p.blocklist != nil {
+		next = blocklistedSource(p.blocklist, next)
+	}
+
+	return next, nil
+}
+
+// newBaseSource returns the function generating each password of the mode and
+// policy from the reader, or from the generator source when the reader is nil.
+// Without constraints every string of the charset is valid and is drawn
+// directly, otherwise the passwords are drawn uniformly from the valid ones.
+func (p *Password) newBaseSource(reader io.Reader) (func() (string, error), error) {
+	if p.charset == "" {
+		return nil, errEmptyCharset
+	}
+
+	if !p.constrained() {
+		return p.newRandomSource(reader), nil
+	}
+
+	if reader == nil {
+		reader = p.reader
+	}
+
+	s, err := p.prepare()
+	if err != nil {
+		return nil, err
+	}
+
+	return func() (string, error) {
+		return s.sample(reader)
+	}, nil
+}
+
+// newRandomSource returns the function drawing every character independently
+// from the charset. Single-byte charsets use the faster byte mapping of the
+// random package when drawing from the generator source.
+func (p *Password) newRandomSource(reader io.Reader) func() (string, error) {
+	if reader == nil && p.rnd != nil {
+		return func() (string, error) {
+			return p.rnd.RandString(p.Length) //nolint:wrapcheck
+		}
+	}
+
+	if reader == nil {
+		reader = p.reader
+	}
+
+	return func() (string, error) {
+		out := make([]rune, p.Length)
+
+		for i := range out {
+			n, err := randInt(reader, len(p.runes))
+			if err != nil {
+				return "", err
+			}
+
+			out[i] = p.runes[n]
+		}
+
+		return string(out), nil
+	}
+}
+
+// prepare returns the sampler of the constrained modes, building it on the
+// first call.
+func (p *Password) prepare() (sampler, error) {
+	p.once.Do(func() {
+		switch p.Mode {
+		case ModePronounceable:
+			p.sampler, p.samplerErr = newPronounceableSampler(p.charset, p.Length)
+		case ModeTemplate:
+			p.sampler, p.samplerErr = newTemplateSampler(string(p.Template), p.charset, p.ExcludeAmbiguous)
+		default:
+			p.sampler, p.samplerErr = sharedClassSampler(p.classes, p.Length)
+		}
+	})
+
+	return p.sampler, p.samplerErr
+}
+
+// log2Big returns the base-2 logarithm of a positive integer.
+func log2Big(x *big.Int) float64 {
+	const mantissaBits = 53
+
+	shift := max(x.BitLen()-mantissaBits, 0)
+	f, _ := new(big.Float).SetInt(new(big.Int).Rsh(x, uint(shift))).Float64()
+
+	return math.Log2(f) + float64(shift)
+}
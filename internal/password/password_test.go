@@ -1,11 +1,13 @@
 package password
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
 	"testing"
 	"testing/iotest"
+	"unicode/utf8"
 
 	"github.com/tecnickcom/nurago/pkg/random"
 	"github.com/tecnickcom/rndpwd/internal/validator"
@@ -25,6 +27,8 @@ func TestDedupCharset(t *testing.T) {
 		{"all same", "aaaa", "a"},
 		{"preserves first-seen order", "cbacba", "cba"},
 		{"empty", "", ""},
+		{"multi-byte duplicates", "αβαγβ漢漢", "αβγ漢"},
+		{"invalid bytes kept", "a\xffb\xffa", "a\xffb"},
 	}
 
 	for _, tt := range tests {
@@ -60,6 +64,180 @@ func TestNewDedupesCharset(t *testing.T) {
 	}
 }
 
+func TestNewExpandsCharset(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		charset string
+		want    string
+	}{
+		{"literal", "aabbcc", "abc"},
+		{"preset", "hex", "0123456789abcdef"},
+		{"expression", `digit+"a1b2"-"0"`, "123456789ab"},
+		{"unknown preset kept as literal", "hex+hexx", "hex+"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := string(New(tt.charset, 8, 1).Charset); got != tt.want {
+				t.Errorf("New(%q).Charset = %q, want %q", tt.charset, got, tt.want)
+			}
+		})
+	}
+
+	pol := Policy{Classes: []Class{{Name: "vowel", Chars: `"aeiou"-"u"`, Min: 1}}}
+
+	p := New("lower", 8, 1, WithPolicy(pol))
+	if got := p.Policy.Classes[0].Chars; got != "aeio" {
+		t.Errorf("expected the expanded class characters %q, found %q", "aeio", got)
+	}
+
+	if pol.Classes[0].Chars != `"aeiou"-"u"` {
+		t.Error("the caller policy must not be modified")
+	}
+}
+
+func TestExcludeAmbiguous(t *testing.T) {
+	t.Parallel()
+
+	p := New(validator.ValidCharset, 64, 20, WithExcludeAmbiguous(true))
+	if p.Charset != validator.ValidCharset {
+		t.Errorf("expected the configured charset to be kept, found %q", p.Charset)
+	}
+
+	if want := len(validator.ValidCharset) - len(AmbiguousChars); p.CharsetSize() != want {
+		t.Errorf("expected a charset size of %d, found %d", want, p.CharsetSize())
+	}
+
+	pwds, err := p.Generate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, pwd := range pwds {
+		if strings.ContainsAny(pwd, AmbiguousChars) {
+			t.Errorf("password %q contains ambiguous characters", pwd)
+		}
+	}
+
+	if size := New("aabb00OO", 8, 1).CharsetSize(); size != 4 {
+		t.Errorf("expected a charset size of 4 without exclusion, found %d", size)
+	}
+}
+
+func TestExcludeAmbiguousEmpty(t *testing.T) {
+	t.Parallel()
+
+	p := New("0O1l", 8, 1, WithExcludeAmbiguous(true))
+
+	if err := p.CheckPolicy(); !errors.Is(err, errEmptyCharset) {
+		t.Errorf("expected the empty charset error, found %v", err)
+	}
+
+	if _, err := p.Generate(); !errors.Is(err, errEmptyCharset) {
+		t.Errorf("expected the empty charset error, found %v", err)
+	}
+
+	if _, err := p.Entropy(); !errors.Is(err, errEmptyCharset) {
+		t.Errorf("expected the empty charset error, found %v", err)
+	}
+}
+
+func TestMaxSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		p    *Password
+		want int
+	}{
+		{"ascii", New("abc", 12, 1), 12},
+		{"multi-byte", New("aβ漢", 10, 1), 30},
+		{"template", New("abc", 12, 1, WithMode(ModeTemplate), WithTemplate("9-[αβ]{2}*")), 7},
+		{"invalid template", New("abc", 12, 1, WithMode(ModeTemplate), WithTemplate("[")), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.p.MaxSize(); got != tt.want {
+				t.Errorf("MaxSize() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateUnicode(t *testing.T) {
+	t.Parallel()
+
+	charset := "αβγδεζηθΑΒΓΔЖЗИЙ漢字かな"
+
+	p := New(charset+charset, 24, 20)
+	if size := p.CharsetSize(); size != 20 {
+		t.Errorf("expected a charset size of 20, found %d", size)
+	}
+
+	pwds, err := p.Generate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, pwd := range pwds {
+		if n := utf8.RuneCountInString(pwd); n != 24 {
+			t.Errorf("expected a password of 24 characters, found %d in %q", n, pwd)
+		}
+
+		for _, c := range pwd {
+			if !strings.ContainsRune(charset, c) {
+				t.Errorf("password %q contains %q outside the charset", pwd, c)
+			}
+		}
+	}
+
+	p = New(charset, 8, 1)
+	p.reader = iotest.ErrReader(errors.New("rng failure"))
+
+	if _, err := p.Generate(); err == nil {
+		t.Error("expected an error when the random reader fails")
+	}
+}
+
+func TestGenerateUnicodePolicy(t *testing.T) {
+	t.Parallel()
+
+	// Greek and Cyrillic capitals are upper, their small letters lower and
+	// the CJK characters, which have no case, fall in the symbol class.
+	p := New("αβγΑΒΓжзЖЗ漢字", 6, 50, WithPolicy(Policy{MinUpper: 2, MinLower: 2, MinSymbol: 1}))
+
+	pwds, err := p.Generate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, pwd := range pwds {
+		var upper, lower, symbol int
+
+		for _, c := range pwd {
+			switch {
+			case strings.ContainsRune("ΑΒΓЖЗ", c):
+				upper++
+			case strings.ContainsRune("αβγжз", c):
+				lower++
+			default:
+				symbol++
+			}
+		}
+
+		if upper < 2 || lower < 2 || symbol < 1 {
+			t.Errorf("password %q does not satisfy the policy", pwd)
+		}
+	}
+}
+
 func TestGenerate(t *testing.T) {
 	t.Parallel()
 
@@ -101,6 +279,81 @@ func TestGenerateError(t *testing.T) {
 	}
 }
 
+func TestStream(t *testing.T) {
+	t.Parallel()
+
+	p := New(validator.ValidCharset, 12, 1)
+
+	var count int
+
+	err := p.Stream(t.Context(), 5000, func(pwd string) error {
+		if l := len(pwd); l != 12 {
+			t.Errorf("The expected password length is 12, found %d", l)
+		}
+
+		count++
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if count != 5000 {
+		t.Errorf("The expected quantity is 5000, found %d", count)
+	}
+}
+
+func TestStreamStop(t *testing.T) {
+	t.Parallel()
+
+	p := New(validator.ValidCharset, 12, 1)
+	errEmit := errors.New("emit failure")
+
+	var count int
+
+	err := p.Stream(t.Context(), 100, func(_ string) error {
+		count++
+
+		if count == 3 {
+			return errEmit
+		}
+
+		return nil
+	})
+	if !errors.Is(err, errEmit) || count != 3 {
+		t.Errorf("expected the emit error after 3 passwords, found %v after %d", err, count)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	count = 0
+
+	err = p.Stream(ctx, 100, func(_ string) error {
+		count++
+
+		if count == 3 {
+			cancel()
+		}
+
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || count != 3 {
+		t.Errorf("expected the context error after 3 passwords, found %v after %d", err, count)
+	}
+
+	p.rnd = random.New(iotest.ErrReader(errors.New("rng failure")), random.WithByteToCharMap([]byte(validator.ValidCharset)))
+
+	err = p.Stream(t.Context(), 1, func(_ string) error { return nil })
+	if err == nil {
+		t.Error("expected an error when the random reader fails")
+	}
+
+	err = New("0O", 12, 1, WithExcludeAmbiguous(true)).Stream(t.Context(), 1, func(_ string) error { return nil })
+	if !errors.Is(err, errEmptyCharset) {
+		t.Errorf("expected the empty charset error, found %v", err)
+	}
+}
+
 func BenchmarkGenerate(b *testing.B) {
 	p := New(validator.ValidCharset, 32, 1)
 
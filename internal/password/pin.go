@@ -0,0 +1,221 @@
+package password
+
+import (
+	"crypto/rand"
+	_ "embed" // embeds the common PIN list
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// Bounds of the PIN length.
+const (
+	MinPINLength = 4
+	MaxPINLength = 12
+)
+
+var (
+	// commonPINList contains the numeric entries of the zxcvbn list of the most
+	// common passwords (https://github.com/dropbox/zxcvbn), sorted by
+	// decreasing frequency. MIT license, see wordlist/NOTICE.
+	//
+	//go:embed wordlist/common_pins.txt
+	commonPINList string //nolint:gochecknoglobals
+
+	// commonPINs is the set of the most common PINs.
+	commonPINs = parseCommonPINs(commonPINList) //nolint:gochecknoglobals
+)
+
+// parseCommonPINs returns the set of the PINs listed one per line.
+func parseCommonPINs(data string) map[string]bool {
+	pins := make(map[string]bool)
+
+	for pin := range strings.FieldsSeq(data) {
+		pins[pin] = true
+	}
+
+	return pins
+}
+
+// PIN contains the numeric PIN generator configuration.
+//
+// The PINs are drawn uniformly from the ones that are not weak, that is not
+// palindromes (including the repeated digits like 1111), not ascending or
+// descending runs like 1234 or 9876, and not in the list of the most common
+// PINs.
+type PIN struct {
+	Length   int `json:"length"   validate:"required,min=4,max=12"`
+	Quantity int `json:"quantity" validate:"required,min=1,max=1000"`
+	reader   io.Reader
+}
+
+// PINOption is a type to allow setting custom PIN options.
+type PINOption func(p *PIN)
+
+// WithPINSource sets the entropy source of the generator (default the OS
+// CSPRNG).
+func WithPINSource(src Source) PINOption {
+	return func(p *PIN) {
+		p.reader = src
+	}
+}
+
+// NewPIN instantiate a new PIN generator object.
+func NewPIN(length, quantity int, opts ...PINOption) *PIN {
+	p := &PIN{
+		Length:   length,
+		Quantity: quantity,
+		reader:   rand.Reader,
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(p)
+	}
+
+	return p
+}
+
+// Keyspace returns the number of PINs that can be generated, all equally
+// likely, that is all the PINs of the configured length except the weak ones.
+func (p *PIN) Keyspace() int64 {
+	if p.Length < MinPINLength || p.Length > MaxPINLength {
+		return 0
+	}
+
+	// The palindromes are counted directly, the other weak PINs are listed
+	// without the palindromes to avoid double counting.
+	weak := pow10(p.Length - p.Length/2)
+	others := make(map[string]bool)
+
+	for _, pin := range digitRuns(p.Length) {
+		if !isPalindrome(pin) {
+			others[pin] = true
+		}
+	}
+
+	for pin := range commonPINs {
+		if len(pin) == p.Length && !isPalindrome(pin) {
+			others[pin] = true
+		}
+	}
+
+	return pow10(p.Length) - weak - int64(len(others))
+}
+
+// Entropy returns the entropy in bits of each PIN, that remains after
+// excluding the weak PINs.
+func (p *PIN) Entropy() float64 {
+	n := p.Keyspace()
+	if n == 0 {
+		return 0
+	}
+
+	return math.Log2(float64(n))
+}
+
+// Generate returns the specified amount of random PINs.
+func (p *PIN) Generate() ([]string, error) {
+	if p.Length < MinPINLength || p.Length > MaxPINLength {
+		return nil, fmt.Errorf("the PIN length must be between %d and %d", MinPINLength, MaxPINLength)
+	}
+
+	limit := big.NewInt(pow10(p.Length))
+	lst := make([]string, p.Quantity)
+
+	for i := range p.Quantity {
+		pin, err := p.generateOne(limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed generating random PIN: %w", err)
+		}
+
+		lst[i] = pin
+	}
+
+	return lst, nil
+}
+
+// generateOne draws PINs uniformly until a strong one is found, so the
+// accepted PINs are uniformly distributed among the strong ones.
+func (p *PIN) generateOne(limit *big.Int) (string, error) {
+	for {
+		n, err := rand.Int(p.reader, limit)
+		if err != nil {
+			return "", fmt.Errorf("failed drawing a random number: %w", err)
+		}
+
+		pin := fmt.Sprintf("%0*d", p.Length, n.Int64())
+		if !IsWeakPIN(pin) {
+			return pin, nil
+		}
+	}
+}
+
+// IsWeakPIN reports whether the PIN is a palindrome, an ascending or
+// descending run of digits, or one of the most common PINs.
+func IsWeakPIN(pin string) bool {
+	return isPalindrome(pin) || isDigitRun(pin) || commonPINs[pin]
+}
+
+// isPalindrome reports whether the string reads the same backward.
+func isPalindrome(s string) bool {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		if s[i] != s[j] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isDigitRun reports whether each digit is one more, or one less, than the
+// previous one.
+func isDigitRun(s string) bool {
+	if len(s) < 2 {
+		return false
+	}
+
+	step := int(s[1]) - int(s[0])
+	if step != 1 && step != -1 {
+		return false
+	}
+
+	for i := 2; i < len(s); i++ {
+		if int(s[i])-int(s[i-1]) != step {
+			return false
+		}
+	}
+
+	return true
+}
+
+// digitRuns returns all the ascending and descending runs of digits of the
+// given length.
+func digitRuns(length int) []string {
+	var runs []string
+
+	for first := 0; first+length <= 10; first++ {
+		var asc, desc strings.Builder
+
+		for i := range length {
+			asc.WriteByte(byte('0' + first + i))
+			desc.WriteByte(byte('9' - first - i))
+		}
+
+		runs = append(runs, asc.String(), desc.String())
+	}
+
+	return runs
+}
+
+// pow10 returns 10 to the power of n.
+func pow10(n int) int64 {
+	v := int64(1)
+
+	for range n {
+		v *= 10
+	}
+
+	return v
+}
@@ -0,0 +1,97 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsWeakPIN(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pin  string
+		weak bool
+	}{
+		{"1111", true},
+		{"0000", true},
+		{"1234", true},
+		{"9876", true},
+		{"3456789", true},
+		{"1221", true},
+		{"12321", true},
+		{"2000", true},
+		{"123123", true},
+		{"13579", true},
+		{"8406", false},
+		{"0192", false},
+		{"8901", false},
+		{"30571", false},
+		{"538271", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pin, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.weak, IsWeakPIN(tt.pin))
+		})
+	}
+}
+
+func TestPINKeyspace(t *testing.T) {
+	t.Parallel()
+
+	for length := MinPINLength; length <= 6; length++ {
+		var want int64
+
+		for n := range pow10(length) {
+			if !IsWeakPIN(fmt.Sprintf("%0*d", length, n)) {
+				want++
+			}
+		}
+
+		p := NewPIN(length, 1)
+		require.Equal(t, want, p.Keyspace(), length)
+		require.InDelta(t, math.Log2(float64(want)), p.Entropy(), 1e-9)
+	}
+
+	require.Zero(t, NewPIN(3, 1).Keyspace())
+	require.Zero(t, NewPIN(13, 1).Entropy())
+	require.Positive(t, NewPIN(MaxPINLength, 1).Keyspace())
+}
+
+func TestGeneratePIN(t *testing.T) {
+	t.Parallel()
+
+	p := NewPIN(4, 500)
+
+	pins, err := p.Generate()
+	require.NoError(t, err)
+	require.Len(t, pins, 500)
+
+	for _, pin := range pins {
+		require.Len(t, pin, 4)
+		require.Regexp(t, "^[0-9]+$", pin)
+		require.False(t, IsWeakPIN(pin), pin)
+	}
+}
+
+func TestGeneratePINError(t *testing.T) {
+	t.Parallel()
+
+	pins, err := NewPIN(3, 1).Generate()
+	require.Error(t, err)
+	require.Nil(t, pins)
+
+	p := NewPIN(6, 2)
+	p.reader = iotest.ErrReader(errors.New("rng failure"))
+
+	pins, err = p.Generate()
+	require.Error(t, err)
+	require.Nil(t, pins)
+}
@@ -0,0 +1,413 @@
+package password
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/tecnickcom/rndpwd/internal/charset"
+)
+
+// Names of the built-in character classes.
+const (
+	ClassUpper  = "upper"
+	ClassLower  = "lower"
+	ClassDigit  = "digit"
+	ClassSymbol = "symbol"
+)
+
+// errPolicy is wrapped by all the errors reporting an unsatisfiable policy.
+var errPolicy = errors.New("unsatisfiable character-class policy")
+
+// Class is a custom character class with its own occurrence bounds.
+type Class struct {
+	Name  string      `json:"name"  validate:"required,max=32"`
+	Chars charset.Set `json:"chars" validate:"required,min=1,max=256,rndcharset"`
+	Min   int         `json:"min"   validate:"min=0,max=4096"`
+	Max   int         `json:"max"   validate:"omitempty,max=4096,gtefield=Min"`
+}
+
+// Policy contains the per-class occurrence bounds that every generated password
+// must satisfy. A zero maximum means that the class has no upper bound.
+//
+// Every character of the charset belongs to exactly one class: the custom
+// classes are checked first, in order, followed by the built-in upper, lower,
+// digit and symbol classes. The upper and lower classes contain the cased
+// letters of any script, the digit class the decimal digits, and symbol
+// collects every remaining character.
+type Policy struct {
+	MinUpper  int     `json:"min_upper"  validate:"min=0,max=4096"`
+	MaxUpper  int     `json:"max_upper"  validate:"omitempty,max=4096,gtefield=MinUpper"`
+	MinLower  int     `json:"min_lower"  validate:"min=0,max=4096"`
+	MaxLower  int     `json:"max_lower"  validate:"omitempty,max=4096,gtefield=MinLower"`
+	MinDigit  int     `json:"min_digit"  validate:"min=0,max=4096"`
+	MaxDigit  int     `json:"max_digit"  validate:"omitempty,max=4096,gtefield=MinDigit"`
+	MinSymbol int     `json:"min_symbol" validate:"min=0,max=4096"`
+	MaxSymbol int     `json:"max_symbol" validate:"omitempty,max=4096,gtefield=MinSymbol"`
+	Classes   []Class `json:"classes"    validate:"max=16,dive"`
+	problem   string  // reason of the last failed Password.CheckPolicy
+}
+
+// Problem returns the reason why the last Password.CheckPolicy call failed, or
+// an empty string. It is used by the validation error messages.
+func (pol Policy) Problem() string {
+	return pol.problem
+}
+
+// IsSet reports whether the policy contains at least one bound.
+func (pol Policy) IsSet() bool {
+	for _, c := range pol.allClasses() {
+		if c.Min > 0 || c.Max > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// expand returns a copy of the policy where the characters of the custom
+// classes can be preset or class expressions, as the charset.
+func (pol Policy) expand() Policy {
+	pol.Classes = slices.Clone(pol.Classes)
+
+	for i := range pol.Classes {
+		pol.Classes[i].Chars = expandCharset(string(pol.Classes[i].Chars))
+	}
+
+	return pol
+}
+
+// allClasses returns the custom classes followed by the built-in ones, in
+// matching order. The built-in classes have no Chars as they are matched by
+// character range.
+func (pol Policy) allClasses() []Class {
+	return append(
+		append([]Class{}, pol.Classes...),
+		Class{Name: ClassUpper, Min: pol.MinUpper, Max: pol.MaxUpper},
+		Class{Name: ClassLower, Min: pol.MinLower, Max: pol.MaxLower},
+		Class{Name: ClassDigit, Min: pol.MinDigit, Max: pol.MaxDigit},
+		Class{Name: ClassSymbol, Min: pol.MinSymbol, Max: pol.MaxSymbol},
+	)
+}
+
+// charClass is a policy class resolved against the effective charset.
+type charClass struct {
+	name  string
+	chars []rune
+	min   int
+	max   int
+}
+
+// classes partitions the charset into the policy classes. A character only
+// belongs to the first class matching it, so the classes never overlap.
+func (pol Policy) classes(charset string) []charClass {
+	all := pol.allClasses()
+	cls := make([]charClass, len(all))
+
+	for i, c := range all {
+		cls[i] = charClass{name: c.Name, min: c.Min, max: c.Max}
+	}
+
+	ncustom := len(pol.Classes)
+
+	for _, c := range charset {
+		j := classIndex(pol.Classes, c)
+
+		if j < 0 {
+			j = ncustom + builtinClassIndex(c)
+		}
+
+		cls[j].chars = append(cls[j].chars, c)
+	}
+
+	return cls
+}
+
+// classIndex returns the index of the first custom class containing c, or -1.
+func classIndex(classes []Class, c rune) int {
+	for i, cl := range classes {
+		if strings.ContainsRune(string(cl.Chars), c) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// builtinClassIndex returns the position of the built-in class of c, in the
+// upper, lower, digit, symbol order.
+func builtinClassIndex(c rune) int {
+	switch {
+	case unicode.IsUpper(c):
+		return 0
+	case unicode.IsLower(c):
+		return 1
+	case unicode.IsDigit(c):
+		return 2
+	default:
+		return 3
+	}
+}
+
+// checkClasses reports whether at least one password of the given length can
+// satisfy all the class bounds.
+func checkClasses(classes []charClass, length int) error {
+	if length > MaxConstrainedLength {
+		return fmt.Errorf("%w: the length %d exceeds the maximum of %d", errPolicy, length, MaxConstrainedLength)
+	}
+
+	var minSum, maxSum int
+
+	for _, c := range classes {
+		if c.max > 0 && c.min > c.max {
+			return fmt.Errorf("%w: the %s minimum %d exceeds its maximum %d", errPolicy, c.name, c.min, c.max)
+		}
+
+		if len(c.chars) == 0 {
+			if c.min > 0 {
+				return fmt.Errorf("%w: the charset has no %s characters", errPolicy, c.name)
+			}
+
+			continue
+		}
+
+		minSum += c.min
+		maxSum += c.upperBound(length)
+	}
+
+	if minSum > length {
+		return fmt.Errorf("%w: the minimums add up to %d, more than the length %d", errPolicy, minSum, length)
+	}
+
+	if maxSum < length {
+		return fmt.Errorf("%w: the maximums add up to %d, less than the length %d", errPolicy, maxSum, length)
+	}
+
+	return nil
+}
+
+// upperBound returns the maximum number of characters of the class that fit in
+// a password of the given length.
+func (c charClass) upperBound(length int) int {
+	if c.max > 0 && c.max < length {
+		return c.max
+	}
+
+	return length
+}
+
+// classSampler draws passwords uniformly from the set of strings satisfying the
+// class bounds.
+//
+// The class counts are drawn first, each with a probability proportional to
+// the number of compliant passwords having that count, then the class
+// positions are shuffled and each position gets a uniformly random character
+// of its class. Every compliant password is therefore equally likely.
+type classSampler struct {
+	classes []charClass
+	length  int
+
+	// ways[j][r] is the number of strings of r characters drawn from the
+	// classes j and following ones that satisfy their bounds.
+	ways [][]*big.Int
+}
+
+// maxClassSamplerCache is the maximum number of cached class samplers. A
+// sampler holds up to (classes+1)*(length+1) counts of up to a few hundred
+// bytes each, so the cache stays within a few tens of MB.
+const maxClassSamplerCache = 32
+
+// classSamplerKey identifies the class partition and length of a sampler.
+type classSamplerKey struct {
+	classes string
+	length  int
+}
+
+// cachedClassSampler is the cached result of newClassSampler.
+type cachedClassSampler struct {
+	sampler *classSampler
+	err     error
+}
+
+// classSamplerCache holds the samplers shared by the generators with the same
+// classes and length, as counting the compliant passwords costs
+// O(classes*length^2) big integer operations and a new generator is built for
+// each request. The samplers are never modified once built.
+var classSamplerCache = newLRUCache[classSamplerKey, cachedClassSampler](maxClassSamplerCache) //nolint:gochecknoglobals
+
+// sharedClassSampler returns the sampler of the classes and length, building it
+// only when it is not cached. The errors are cached too, as they only depend
+// on the same settings.
+func sharedClassSampler(classes []charClass, length int) (*classSampler, error) {
+	key := classSamplerKey{classes: classesKey(classes), length: length}
+
+	c, ok := classSamplerCache.get(key)
+	if !ok {
+		c.sampler, c.err = newClassSampler(classes, length)
+		classSamplerCache.add(key, c)
+	}
+
+	return c.sampler, c.err
+}
+
+// newClassSampler counts the compliant passwords for the non-empty classes.
+func newClassSampler(classes []charClass, length int) (*classSampler, error) {
+	err := checkClasses(classes, length)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &classSampler{length: length}
+
+	for _, c := range classes {
+		if len(c.chars) > 0 {
+			s.classes = append(s.classes, c)
+		}
+	}
+
+	m := len(s.classes)
+	s.ways = make([][]*big.Int, m+1)
+	s.ways[m] = make([]*big.Int, length+1)
+
+	for r := range length + 1 {
+		s.ways[m][r] = new(big.Int)
+	}
+
+	s.ways[m][0].SetInt64(1)
+
+	for j := m - 1; j >= 0; j-- {
+		s.ways[j] = make([]*big.Int, length+1)
+
+		for r := range length + 1 {
+			total := new(big.Int)
+
+			s.eachSplit(j, r, func(_ int, n *big.Int) bool {
+				total.Add(total, n)
+				return true
+			})
+
+			s.ways[j][r] = total
+		}
+	}
+
+	return s, nil
+}
+
+// eachSplit calls fn, in increasing order of k, with the number of compliant
+// strings of r characters having exactly k characters of the class j, for
+// every k allowed by the class bounds. The iteration stops when fn returns
+// false.
+func (s *classSampler) eachSplit(j, r int, fn func(k int, n *big.Int) bool) {
+	c := s.classes[j]
+	size := big.NewInt(int64(len(c.chars)))
+	binom := big.NewInt(1) // binomial coefficient (r k)
+	pow := big.NewInt(1)   // size^k
+	hi := c.upperBound(r)
+
+	for k := 0; k <= hi; k++ {
+		if k > 0 {
+			binom.Mul(binom, big.NewInt(int64(r-k+1)))
+			binom.Quo(binom, big.NewInt(int64(k)))
+			pow.Mul(pow, size)
+		}
+
+		next := s.ways[j+1][r-k]
+		if k < c.min || next.Sign() == 0 {
+			continue
+		}
+
+		n := new(big.Int).Mul(binom, pow)
+		n.Mul(n, next)
+
+		if !fn(k, n) {
+			return
+		}
+	}
+}
+
+// sample returns a random compliant password.
+func (s *classSampler) sample(reader io.Reader) (string, error) {
+	labels := make([]int, 0, s.length)
+	r := s.length
+
+	for j := range s.classes {
+		k, err := s.pickCount(reader, j, r)
+		if err != nil {
+			return "", err
+		}
+
+		for range k {
+			labels = append(labels, j)
+		}
+
+		r -= k
+	}
+
+	for i := len(labels) - 1; i > 0; i-- {
+		n, err := randInt(reader, i+1)
+		if err != nil {
+			return "", err
+		}
+
+		labels[i], labels[n] = labels[n], labels[i]
+	}
+
+	out := make([]rune, len(labels))
+
+	for i, j := range labels {
+		chars := s.classes[j].chars
+
+		n, err := randInt(reader, len(chars))
+		if err != nil {
+			return "", err
+		}
+
+		out[i] = chars[n]
+	}
+
+	return string(out), nil
+}
+
+// size returns the number of compliant passwords.
+func (s *classSampler) size() *big.Int {
+	return s.ways[0][s.length]
+}
+
+// pickCount draws the number of characters of the class j among the r
+// remaining positions.
+func (s *classSampler) pickCount(reader io.Reader, j, r int) (int, error) {
+	x, err := rand.Int(reader, s.ways[j][r])
+	if err != nil {
+		return 0, fmt.Errorf("failed drawing the class count: %w", err)
+	}
+
+	var picked int
+
+	s.eachSplit(j, r, func(k int, n *big.Int) bool {
+		if x.Cmp(n) < 0 {
+			picked = k
+			return false
+		}
+
+		x.Sub(x, n)
+
+		return true
+	})
+
+	return picked, nil
+}
+
+// randInt returns a uniform random integer in [0, n).
+func randInt(reader io.Reader, n int) (int, error) {
+	v, err := rand.Int(reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("failed drawing a random number: %w", err)
+	}
+
+	return int(v.Int64()), nil
+}
@@ -0,0 +1,275 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/rndpwd/internal/validator"
+)
+
+func TestPolicyIsSet(t *testing.T) {
+	t.Parallel()
+
+	require.False(t, Policy{}.IsSet())
+	require.False(t, Policy{Classes: []Class{{Name: "x", Chars: "x"}}}.IsSet())
+	require.True(t, Policy{MinDigit: 1}.IsSet())
+	require.True(t, Policy{MaxSymbol: 2}.IsSet())
+	require.True(t, Policy{Classes: []Class{{Name: "x", Chars: "x", Min: 1}}}.IsSet())
+}
+
+func TestPolicyClasses(t *testing.T) {
+	t.Parallel()
+
+	pol := Policy{
+		Classes: []Class{
+			{Name: "vowel", Chars: "aeiouAEIOU"},
+			{Name: "dup", Chars: "ab"},
+		},
+	}
+
+	got := make(map[string]string)
+
+	for _, c := range pol.classes("abcAB01!?e") {
+		got[c.name] = string(c.chars)
+	}
+
+	want := map[string]string{
+		"vowel":     "aAe",
+		"dup":       "b",
+		ClassUpper:  "B",
+		ClassLower:  "c",
+		ClassDigit:  "01",
+		ClassSymbol: "!?",
+	}
+
+	require.Equal(t, want, got)
+}
+
+func TestCheckPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		charset string
+		length  int
+		policy  Policy
+		wantErr bool
+	}{
+		{
+			name:    "no policy",
+			charset: "abc",
+			length:  MaxConstrainedLength + 1,
+		},
+		{
+			name:    "all classes",
+			charset: validator.ValidCharset,
+			length:  4,
+			policy:  Policy{MinUpper: 1, MinLower: 1, MinDigit: 1, MinSymbol: 1},
+		},
+		{
+			name:    "minimums exceed length",
+			charset: validator.ValidCharset,
+			length:  3,
+			policy:  Policy{MinUpper: 1, MinLower: 1, MinDigit: 1, MinSymbol: 1},
+			wantErr: true,
+		},
+		{
+			name:    "maximums below length",
+			charset: "abc123",
+			length:  8,
+			policy:  Policy{MaxLower: 3, MaxDigit: 4},
+			wantErr: true,
+		},
+		{
+			name:    "maximums only count classes in the charset",
+			charset: "abc",
+			length:  8,
+			policy:  Policy{MaxLower: 4, MaxDigit: 4},
+			wantErr: true,
+		},
+		{
+			name:    "minimum above maximum",
+			charset: "abc123",
+			length:  8,
+			policy:  Policy{MinDigit: 3, MaxDigit: 2},
+			wantErr: true,
+		},
+		{
+			name:    "class missing from charset",
+			charset: "abc123",
+			length:  8,
+			policy:  Policy{MinUpper: 1},
+			wantErr: true,
+		},
+		{
+			name:    "custom class missing from charset",
+			charset: "abc123",
+			length:  8,
+			policy:  Policy{Classes: []Class{{Name: "x", Chars: "xyz", Min: 1}}},
+			wantErr: true,
+		},
+		{
+			name:    "length too long",
+			charset: "abc123",
+			length:  MaxConstrainedLength + 1,
+			policy:  Policy{MinDigit: 1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := New(tt.charset, tt.length, 1, WithPolicy(tt.policy)).CheckPolicy()
+			if !tt.wantErr {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, errPolicy)
+		})
+	}
+}
+
+func TestGeneratePolicy(t *testing.T) {
+	t.Parallel()
+
+	pol := Policy{
+		MinUpper:  2,
+		MinLower:  1,
+		MaxLower:  3,
+		MinDigit:  1,
+		MinSymbol: 1,
+		MaxSymbol: 1,
+		Classes:   []Class{{Name: "hex", Chars: "abcdef", Min: 2}},
+	}
+
+	p := New(validator.ValidCharset, 12, 200, WithPolicy(pol))
+
+	pwds, err := p.Generate()
+	require.NoError(t, err)
+	require.Len(t, pwds, 200)
+
+	for _, pwd := range pwds {
+		require.Len(t, pwd, 12)
+
+		count := make(map[string]int)
+
+		for _, c := range pwd {
+			j := classIndex(pol.Classes, c)
+			if j < 0 {
+				count[p.classes[len(pol.Classes)+builtinClassIndex(c)].name]++
+				continue
+			}
+
+			count[pol.Classes[j].Name]++
+		}
+
+		require.GreaterOrEqual(t, count[ClassUpper], 2, pwd)
+		require.GreaterOrEqual(t, count[ClassLower], 1, pwd)
+		require.LessOrEqual(t, count[ClassLower], 3, pwd)
+		require.GreaterOrEqual(t, count[ClassDigit], 1, pwd)
+		require.Equal(t, 1, count[ClassSymbol], pwd)
+		require.GreaterOrEqual(t, count["hex"], 2, pwd)
+	}
+}
+
+func TestGeneratePolicyUniform(t *testing.T) {
+	t.Parallel()
+
+	// With two digits and one letter, where exactly one digit is required, the
+	// compliant passwords of length 2 are: 0a 1a a0 a1. Patching a digit into a
+	// random string would favor some of them.
+	p := New("01a", 2, 4000, WithPolicy(Policy{MinDigit: 1, MaxDigit: 1}))
+
+	pwds, err := p.Generate()
+	require.NoError(t, err)
+
+	count := make(map[string]int)
+	for _, pwd := range pwds {
+		count[pwd]++
+	}
+
+	require.Len(t, count, 4)
+
+	for pwd, n := range count {
+		require.InDelta(t, 1000, n, 150, pwd)
+	}
+}
+
+func TestClassSamplerWays(t *testing.T) {
+	t.Parallel()
+
+	// length 4 over 2 upper, 3 digits with at least one of each:
+	// 5^4 - 2^4 - 3^4 = 528 compliant strings
+	p := New("AB123", 4, 1, WithPolicy(Policy{MinUpper: 1, MinDigit: 1}))
+
+	s, err := newClassSampler(p.classes, p.Length)
+	require.NoError(t, err)
+	require.Equal(t, int64(528), s.ways[0][4].Int64())
+}
+
+func TestGeneratePolicyError(t *testing.T) {
+	t.Parallel()
+
+	p := New(validator.ValidCharset, 3, 1, WithPolicy(Policy{MinUpper: 2, MinLower: 2}))
+
+	pwds, err := p.Generate()
+	require.ErrorIs(t, err, errPolicy)
+	require.Nil(t, pwds)
+
+	p = New(validator.ValidCharset, 16, 2, WithPolicy(Policy{MinUpper: 2}))
+	p.reader = iotest.ErrReader(errors.New("rng failure"))
+
+	pwds, err = p.Generate()
+	require.Error(t, err)
+	require.Nil(t, pwds)
+}
+
+func TestGeneratePolicyMaxLength(t *testing.T) {
+	t.Parallel()
+
+	pol := Policy{MinUpper: 1, MinLower: 1, MinDigit: 1, MinSymbol: 1}
+	p := New(validator.ValidCharset, MaxConstrainedLength, 1, WithPolicy(pol))
+
+	pwds, err := p.Generate()
+	require.NoError(t, err)
+	require.Len(t, pwds[0], MaxConstrainedLength)
+	require.True(t, strings.ContainsAny(pwds[0], "0123456789"))
+}
+
+func TestSharedClassSampler(t *testing.T) {
+	t.Parallel()
+
+	pol := Policy{MinUpper: 2, MinDigit: 3, MaxSymbol: 1}
+
+	s1, err := New(validator.ValidCharset, 41, 1, WithPolicy(pol)).prepare()
+	require.NoError(t, err)
+
+	s2, err := New(validator.ValidCharset, 41, 1, WithPolicy(pol)).prepare()
+	require.NoError(t, err)
+	require.Same(t, s1, s2)
+
+	s3, err := New(validator.ValidCharset, 42, 1, WithPolicy(pol)).prepare()
+	require.NoError(t, err)
+	require.NotSame(t, s1, s3)
+
+	// the errors are cached too
+	for range 2 {
+		_, err = New("abc", 4, 1, WithPolicy(Policy{MinDigit: 1})).prepare()
+		require.ErrorIs(t, err, errPolicy)
+	}
+}
+
+func BenchmarkGeneratePolicy(b *testing.B) {
+	pol := Policy{MinUpper: 1, MinLower: 1, MinDigit: 1, MinSymbol: 1}
+	p := New(validator.ValidCharset, 32, 1, WithPolicy(pol))
+
+	for b.Loop() {
+		_, _ = p.Generate()
+	}
+}
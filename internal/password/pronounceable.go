@@ -0,0 +1,171 @@
+package password
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+)
+
+// errPronounceable is wrapped by all the errors reporting that no pronounceable
+// password can be built.
+var errPronounceable = errors.New("unsatisfiable pronounceable mode")
+
+// Sound units of the pronounceable mode. The consonant units only contain
+// consonants and the vowel units only vowels, so that a password alternating
+// them splits back into its units in a single way: every distinct sequence of
+// units is a distinct password and the counted output space is exact.
+//
+//nolint:gochecknoglobals
+var (
+	consonantUnits = []string{
+		"b", "c", "d", "f", "g", "h", "j", "k", "l", "m", "n", "p", "r", "s", "t", "v", "w", "z",
+		"ch", "sh", "th", "tr", "st", "br", "pr", "cr", "dr", "gr", "bl", "cl", "fl", "pl",
+	}
+
+	vowelUnits = []string{
+		"a", "e", "i", "o", "u",
+		"ai", "au", "ea", "ee", "ie", "oa", "oo", "ou",
+	}
+)
+
+// pronounceableSampler draws passwords uniformly from the strings of the given
+// length made of alternating consonant and vowel units, where every unit only
+// contains characters of the charset.
+type pronounceableSampler struct {
+	units  [2][]string // consonant and vowel units available in the charset
+	length int
+
+	// ways[t][r] is the number of strings of r characters starting with a
+	// unit of type t and then alternating the unit types.
+	ways [2][]*big.Int
+}
+
+// newPronounceableSampler counts the pronounceable passwords of the charset.
+func newPronounceableSampler(charset string, length int) (*pronounceableSampler, error) {
+	if length > MaxConstrainedLength {
+		return nil, fmt.Errorf("%w: the length %d exceeds the maximum of %d", errPronounceable, length, MaxConstrainedLength)
+	}
+
+	s := &pronounceableSampler{
+		units:  [2][]string{filterUnits(consonantUnits, charset), filterUnits(vowelUnits, charset)},
+		length: length,
+	}
+
+	for t := range s.ways {
+		s.ways[t] = make([]*big.Int, length+1)
+		s.ways[t][0] = big.NewInt(1)
+	}
+
+	for r := 1; r <= length; r++ {
+		for t := range s.ways {
+			total := new(big.Int)
+
+			s.eachUnit(t, r, func(_ string, n *big.Int) bool {
+				total.Add(total, n)
+				return true
+			})
+
+			s.ways[t][r] = total
+		}
+	}
+
+	if s.size().Sign() == 0 {
+		return nil, fmt.Errorf("%w: the charset has not enough lowercase consonants and vowels for the length %d", errPronounceable, length)
+	}
+
+	return s, nil
+}
+
+// filterUnits returns the units made only of charset characters.
+func filterUnits(units []string, charset string) []string {
+	var out []string
+
+	for _, u := range units {
+		if strings.Trim(u, charset) == "" {
+			out = append(out, u)
+		}
+	}
+
+	return out
+}
+
+// eachUnit calls fn, in order, with every unit of type t fitting in r
+// characters and the number of strings of r characters starting with it.
+// Units followed by no valid string are skipped. The iteration stops when fn
+// returns false.
+func (s *pronounceableSampler) eachUnit(t, r int, fn func(u string, n *big.Int) bool) {
+	for _, u := range s.units[t] {
+		if len(u) > r {
+			continue
+		}
+
+		n := s.ways[1-t][r-len(u)]
+		if n.Sign() == 0 {
+			continue
+		}
+
+		if !fn(u, n) {
+			return
+		}
+	}
+}
+
+// size returns the number of pronounceable passwords.
+func (s *pronounceableSampler) size() *big.Int {
+	return new(big.Int).Add(s.ways[0][s.length], s.ways[1][s.length])
+}
+
+// sample returns a random pronounceable password.
+func (s *pronounceableSampler) sample(reader io.Reader) (string, error) {
+	x, err := rand.Int(reader, s.size())
+	if err != nil {
+		return "", fmt.Errorf("failed drawing the first unit type: %w", err)
+	}
+
+	// the strings starting with a consonant come first
+	t := 0
+	if x.Cmp(s.ways[0][s.length]) >= 0 {
+		t = 1
+	}
+
+	var sb strings.Builder
+
+	for r := s.length; r > 0; t = 1 - t {
+		u, err := s.pickUnit(reader, t, r)
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteString(u)
+
+		r -= len(u)
+	}
+
+	return sb.String(), nil
+}
+
+// pickUnit draws the unit of type t starting the r remaining characters.
+func (s *pronounceableSampler) pickUnit(reader io.Reader, t, r int) (string, error) {
+	x, err := rand.Int(reader, s.ways[t][r])
+	if err != nil {
+		return "", fmt.Errorf("failed drawing a unit: %w", err)
+	}
+
+	var picked string
+
+	s.eachUnit(t, r, func(u string, n *big.Int) bool {
+		if x.Cmp(n) < 0 {
+			picked = u
+			return false
+		}
+
+		x.Sub(x, n)
+
+		return true
+	})
+
+	return picked, nil
+}
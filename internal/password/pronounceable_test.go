@@ -0,0 +1,147 @@
+package password
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/rndpwd/internal/validator"
+)
+
+func TestPronounceableSize(t *testing.T) {
+	t.Parallel()
+
+	// consonants {b}, vowels {a, i, ai}:
+	// ba bi ab ib ai
+	s, err := newPronounceableSampler("bai", 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), s.size().Int64())
+
+	p := New("bai", 2, 1, WithMode(ModePronounceable))
+
+	bits, err := p.Entropy()
+	require.NoError(t, err)
+	require.InDelta(t, math.Log2(5), bits, 1e-9)
+}
+
+func TestGeneratePronounceable(t *testing.T) {
+	t.Parallel()
+
+	p := New(validator.ValidCharset, 14, 200, WithMode(ModePronounceable))
+
+	pwds, err := p.Generate()
+	require.NoError(t, err)
+	require.Len(t, pwds, 200)
+
+	for _, pwd := range pwds {
+		require.Len(t, pwd, 14)
+
+		// the runs of consonants and vowels must be single units
+		for _, run := range strings.FieldsFunc(pwd, isVowel) {
+			require.Contains(t, consonantUnits, run, pwd)
+		}
+
+		for _, run := range strings.FieldsFunc(pwd, func(r rune) bool { return !isVowel(r) }) {
+			require.Contains(t, vowelUnits, run, pwd)
+		}
+	}
+}
+
+func TestGeneratePronounceableUniform(t *testing.T) {
+	t.Parallel()
+
+	p := New("bai", 2, 5000, WithMode(ModePronounceable))
+
+	pwds, err := p.Generate()
+	require.NoError(t, err)
+
+	count := make(map[string]int)
+	for _, pwd := range pwds {
+		count[pwd]++
+	}
+
+	require.Len(t, count, 5)
+
+	for pwd, n := range count {
+		require.InDelta(t, 1000, n, 150, pwd)
+	}
+}
+
+func TestPronounceableEntropy(t *testing.T) {
+	t.Parallel()
+
+	rnd, err := New(validator.ValidCharset, 12, 1).Entropy()
+	require.NoError(t, err)
+	require.InDelta(t, 12*math.Log2(float64(len(validator.ValidCharset))), rnd, 1e-9)
+
+	pro, err := New(validator.ValidCharset, 12, 1, WithMode(ModePronounceable)).Entropy()
+	require.NoError(t, err)
+	require.Greater(t, pro, 30.0)
+	require.Less(t, pro, rnd)
+}
+
+func TestGeneratePronounceableError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		p       *Password
+		wantErr error
+	}{
+		{
+			name:    "no vowels",
+			p:       New("bcdxyz", 8, 1, WithMode(ModePronounceable)),
+			wantErr: errPronounceable,
+		},
+		{
+			name:    "length too long",
+			p:       New(validator.ValidCharset, MaxConstrainedLength+1, 1, WithMode(ModePronounceable)),
+			wantErr: errPronounceable,
+		},
+		{
+			name:    "with policy",
+			p:       New(validator.ValidCharset, 8, 1, WithMode(ModePronounceable), WithPolicy(Policy{MinDigit: 1})),
+			wantErr: errPolicy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.ErrorIs(t, tt.p.CheckPolicy(), tt.wantErr)
+		})
+	}
+
+	_, err := New("bcd", 8, 1, WithMode(ModePronounceable)).Generate()
+	require.ErrorIs(t, err, errPronounceable)
+
+	_, err = New("bcd", 8, 1, WithMode(ModePronounceable)).Entropy()
+	require.ErrorIs(t, err, errPronounceable)
+
+	for _, prefix := range []int{0, 16} {
+		p := New(validator.ValidCharset, 16, 1, WithMode(ModePronounceable))
+		p.reader = io.MultiReader(bytes.NewReader(make([]byte, prefix)), iotest.ErrReader(errors.New("rng failure")))
+
+		pwds, err := p.Generate()
+		require.Error(t, err)
+		require.Nil(t, pwds)
+	}
+}
+
+func TestLog2Big(t *testing.T) {
+	t.Parallel()
+
+	require.InDelta(t, 100.0, log2Big(new(big.Int).Lsh(big.NewInt(1), 100)), 1e-9)
+	require.InDelta(t, math.Log2(3), log2Big(big.NewInt(3)), 1e-9)
+}
+
+func isVowel(r rune) bool {
+	return strings.ContainsRune("aeiou", r)
+}
@@ -0,0 +1,84 @@
+package password
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// CrockfordBase32 is the lowercase Crockford base32 alphabet, without the
+// letters i, l, o and u that are easily mistaken for digits or each other.
+const CrockfordBase32 = "0123456789abcdefghjkmnpqrstvwxyz"
+
+// recoveryCodeSeparator is placed between the groups of a recovery code.
+const recoveryCodeSeparator = "-"
+
+// RecoveryCodes contains the one-time recovery codes generator configuration.
+//
+// Each code is made of groups of Crockford base32 characters joined by dashes,
+// like abcd-efgh-jkmn, and the codes of a set are all distinct.
+type RecoveryCodes struct {
+	Groups    int `json:"groups"     validate:"required,min=1,max=8"`
+	GroupSize int `json:"group_size" validate:"required,min=4,max=8"`
+	Quantity  int `json:"quantity"   validate:"required,min=1,max=100"`
+	source    Source
+}
+
+// RecoveryOption is a type to allow setting custom recovery codes options.
+type RecoveryOption func(r *RecoveryCodes)
+
+// WithRecoverySource sets the entropy source of the codes (default the OS
+// CSPRNG).
+func WithRecoverySource(src Source) RecoveryOption {
+	return func(r *RecoveryCodes) {
+		r.source = src
+	}
+}
+
+// NewRecoveryCodes instantiate a new recovery codes generator object.
+func NewRecoveryCodes(groups, groupSize, quantity int, opts ...RecoveryOption) *RecoveryCodes {
+	r := &RecoveryCodes{
+		Groups:    groups,
+		GroupSize: groupSize,
+		Quantity:  quantity,
+		source:    NewOSSource(),
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(r)
+	}
+
+	return r
+}
+
+// Entropy returns the entropy in bits of each code.
+func (r *RecoveryCodes) Entropy() float64 {
+	return float64(r.Groups*r.GroupSize) * math.Log2(float64(len(CrockfordBase32)))
+}
+
+// Size returns the size in bytes of each code, separators included.
+func (r *RecoveryCodes) Size() int {
+	return r.Groups*r.GroupSize + (r.Groups-1)*len(recoveryCodeSeparator)
+}
+
+// Generate returns the specified amount of distinct random codes. The
+// characters are drawn by the password generator, from the same entropy
+// source.
+func (r *RecoveryCodes) Generate() ([]string, error) {
+	lst, err := New(CrockfordBase32, r.Groups*r.GroupSize, r.Quantity, WithUnique(true), WithSource(r.source)).Generate()
+	if err != nil {
+		return nil, fmt.Errorf("failed generating recovery codes: %w", err)
+	}
+
+	for i, code := range lst {
+		groups := make([]string, 0, r.Groups)
+
+		for g := range r.Groups {
+			groups = append(groups, code[g*r.GroupSize:(g+1)*r.GroupSize])
+		}
+
+		lst[i] = strings.Join(groups, recoveryCodeSeparator)
+	}
+
+	return lst, nil
+}
@@ -0,0 +1,79 @@
+package password
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// errSourceStub is an entropy source that always fails.
+type errSourceStub struct{}
+
+func (errSourceStub) Read([]byte) (int, error) {
+	return 0, errors.New("rng failure")
+}
+
+func (errSourceStub) Name() string {
+	return "failing"
+}
+
+func TestRecoveryCodesGenerate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		groups    int
+		groupSize int
+		quantity  int
+		pattern   string
+	}{
+		{"default", 3, 4, 10, `^[0-9a-hjkmnp-tv-z]{4}-[0-9a-hjkmnp-tv-z]{4}-[0-9a-hjkmnp-tv-z]{4}$`},
+		{"single group", 1, 8, 16, `^[0-9a-hjkmnp-tv-z]{8}$`},
+		{"small keyspace", 1, 4, 100, `^[0-9a-hjkmnp-tv-z]{4}$`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := NewRecoveryCodes(tt.groups, tt.groupSize, tt.quantity)
+			require.InDelta(t, float64(tt.groups*tt.groupSize*5), r.Entropy(), 1e-9)
+
+			lst, err := r.Generate()
+			require.NoError(t, err)
+			require.Len(t, lst, tt.quantity)
+			require.Len(t, lst[0], r.Size())
+
+			re := regexp.MustCompile(tt.pattern)
+			seen := make(map[string]bool, len(lst))
+
+			for _, code := range lst {
+				require.Regexp(t, re, code)
+				require.False(t, seen[code], "duplicate code %s", code)
+
+				seen[code] = true
+			}
+		})
+	}
+}
+
+func TestRecoveryCodesAlphabet(t *testing.T) {
+	t.Parallel()
+
+	require.Len(t, CrockfordBase32, 32)
+	require.NotContains(t, CrockfordBase32, "i")
+	require.NotContains(t, CrockfordBase32, "l")
+	require.NotContains(t, CrockfordBase32, "o")
+	require.NotContains(t, CrockfordBase32, "u")
+	require.InDelta(t, 5.0, math.Log2(float64(len(CrockfordBase32))), 1e-9)
+}
+
+func TestRecoveryCodesGenerateError(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewRecoveryCodes(3, 4, 1, WithRecoverySource(errSourceStub{})).Generate()
+	require.Error(t, err)
+}
@@ -0,0 +1,367 @@
+package password
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"golang.org/x/crypto/chacha20"
+)
+
+// Types of entropy sources.
+const (
+	// SourceOS reads from the operating system CSPRNG (crypto/rand).
+	SourceOS = "os"
+
+	// SourceChaCha20 is a fast-key-erasure ChaCha20 DRBG seeded by the OS.
+	SourceChaCha20 = "chacha20"
+
+	// SourceHMACDRBG is an SP 800-90A HMAC_DRBG with SHA-256, seeded by the OS.
+	SourceHMACDRBG = "hmac_drbg"
+
+	// SourceFile reads from a file or device, such as /dev/hwrng.
+	SourceFile = "file"
+)
+
+// DefaultReseedInterval is the default number of reads served by a DRBG
+// between two reseeds from the operating system.
+const DefaultReseedInterval = 1 << 16
+
+const (
+	// drbgSeedSize is the size in bytes of the entropy input of each seed, for
+	// a security strength of 256 bits.
+	drbgSeedSize = 32
+
+	// drbgNonceSize is the size in bytes of the HMAC_DRBG instantiation nonce.
+	drbgNonceSize = 16
+
+	// drbgMaxRequest is the maximum number of bytes produced by a single DRBG
+	// generate call: larger reads are split. It matches the SP 800-90A limit of
+	// 2^19 bits per HMAC_DRBG request.
+	drbgMaxRequest = 1 << 16
+
+	// drbgPersonalization is the HMAC_DRBG personalization string.
+	drbgPersonalization = "rndpwd"
+)
+
+// errSource is wrapped by all the errors reporting a failing entropy source.
+var errSource = errors.New("entropy source failure")
+
+// Source is a source of cryptographically secure random bytes.
+// Implementations are safe for concurrent use.
+type Source interface {
+	io.Reader
+
+	// Name returns the name of the source construction, as reported in the
+	// status and metrics.
+	Name() string
+}
+
+// NewSource returns the entropy source of the given type. The path is only
+// used by SourceFile, and the reseed interval, in number of reads, only by
+// the DRBGs.
+func NewSource(typ, path string, reseedInterval int) (Source, error) {
+	switch typ {
+	case SourceOS:
+		return NewOSSource(), nil
+	case SourceChaCha20:
+		return NewChaCha20Source(reseedInterval), nil
+	case SourceHMACDRBG:
+		return NewHMACDRBGSource(reseedInterval), nil
+	case SourceFile:
+		return NewFileSource(path)
+	default:
+		return nil, fmt.Errorf("%w: unknown source type %q", errSource, typ)
+	}
+}
+
+// CheckSource reads a sample from the source and reports an error if the read
+// fails or if the source is stuck returning the same byte value.
+func CheckSource(src Source) error {
+	buf := make([]byte, drbgSeedSize)
+
+	_, err := io.ReadFull(src, buf)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", errSource, src.Name(), err)
+	}
+
+	for _, b := range buf[1:] {
+		if b != buf[0] {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s: constant output", errSource, src.Name())
+}
+
+// osSource reads from the operating system CSPRNG.
+type osSource struct{}
+
+// NewOSSource returns the operating system CSPRNG source.
+func NewOSSource() Source {
+	return osSource{}
+}
+
+// Read implements io.Reader.
+func (osSource) Read(b []byte) (int, error) {
+	return rand.Read(b) //nolint:wrapcheck
+}
+
+// Name implements Source.
+func (osSource) Name() string {
+	return SourceOS
+}
+
+// chacha20Source is a fast-key-erasure DRBG: each read generates a ChaCha20
+// keystream, the first 32 bytes of which replace the key before the rest is
+// returned, so a compromised state can't reveal past outputs. The key is mixed
+// with fresh OS entropy every reseed interval.
+type chacha20Source struct {
+	mu       sync.Mutex
+	seed     io.Reader
+	interval int
+	reads    int
+	seeded   bool
+	key      [chacha20.KeySize]byte
+}
+
+// NewChaCha20Source returns a ChaCha20 DRBG source, reseeded from the OS CSPRNG
+// after the given number of reads.
+func NewChaCha20Source(reseedInterval int) Source {
+	return &chacha20Source{seed: rand.Reader, interval: reseedInterval}
+}
+
+// Read implements io.Reader.
+func (s *chacha20Source) Read(b []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.seeded || s.reads >= s.interval {
+		err := s.reseed()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	s.reads++
+
+	for out := b; len(out) > 0; {
+		n := min(len(out), drbgMaxRequest)
+		s.generate(out[:n])
+		out = out[n:]
+	}
+
+	return len(b), nil
+}
+
+// Name implements Source.
+func (s *chacha20Source) Name() string {
+	return SourceChaCha20
+}
+
+// reseed replaces the key with the SHA-256 hash of the key and a fresh seed.
+func (s *chacha20Source) reseed() error {
+	seed := make([]byte, drbgSeedSize)
+
+	_, err := io.ReadFull(s.seed, seed)
+	if err != nil {
+		return fmt.Errorf("%w: %s reseed: %w", errSource, SourceChaCha20, err)
+	}
+
+	s.key = sha256.Sum256(append(s.key[:], seed...))
+	s.reads = 0
+	s.seeded = true
+
+	return nil
+}
+
+// generate fills out with keystream bytes and erases the key.
+func (s *chacha20Source) generate(out []byte) {
+	var nonce [chacha20.NonceSize]byte
+
+	// the key and nonce sizes are fixed, so the cipher can't fail; the nonce
+	// is never reused with the same key as the key changes on every call
+	c, _ := chacha20.NewUnauthenticatedCipher(s.key[:], nonce[:])
+
+	var next [chacha20.KeySize]byte
+
+	c.XORKeyStream(next[:], next[:])
+	clear(out)
+	c.XORKeyStream(out, out)
+
+	s.key = next
+}
+
+// hmacDRBGSource is the SP 800-90A Rev. 1 HMAC_DRBG with SHA-256, without
+// prediction resistance, instantiated with a 32-byte entropy input, a 16-byte
+// nonce and the "rndpwd" personalization string, and reseeded with 32 bytes of
+// OS entropy every reseed interval.
+type hmacDRBGSource struct {
+	mu       sync.Mutex
+	seed     io.Reader
+	interval int
+	counter  int // reseed counter
+	key      []byte
+	v        []byte
+}
+
+// NewHMACDRBGSource returns an HMAC_DRBG source, reseeded from the OS CSPRNG
+// after the given number of reads.
+func NewHMACDRBGSource(reseedInterval int) Source {
+	return &hmacDRBGSource{seed: rand.Reader, interval: reseedInterval}
+}
+
+// Read implements io.Reader. Each read is one generate request, split in
+// requests of at most drbgMaxRequest bytes.
+func (s *hmacDRBGSource) Read(b []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for out := b; len(out) > 0; {
+		n := min(len(out), drbgMaxRequest)
+
+		err := s.generate(out[:n])
+		if err != nil {
+			return 0, err
+		}
+
+		out = out[n:]
+	}
+
+	return len(b), nil
+}
+
+// Name implements Source.
+func (s *hmacDRBGSource) Name() string {
+	return SourceHMACDRBG
+}
+
+// instantiate implements the HMAC_DRBG instantiate function.
+func (s *hmacDRBGSource) instantiate() error {
+	seed := make([]byte, drbgSeedSize+drbgNonceSize)
+
+	_, err := io.ReadFull(s.seed, seed)
+	if err != nil {
+		return fmt.Errorf("%w: %s instantiate: %w", errSource, SourceHMACDRBG, err)
+	}
+
+	s.key = make([]byte, sha256.Size)
+	s.v = make([]byte, sha256.Size)
+
+	for i := range s.v {
+		s.v[i] = 0x01
+	}
+
+	s.update(append(seed, drbgPersonalization...))
+	s.counter = 1
+
+	return nil
+}
+
+// reseed implements the HMAC_DRBG reseed function.
+func (s *hmacDRBGSource) reseed() error {
+	seed := make([]byte, drbgSeedSize)
+
+	_, err := io.ReadFull(s.seed, seed)
+	if err != nil {
+		return fmt.Errorf("%w: %s reseed: %w", errSource, SourceHMACDRBG, err)
+	}
+
+	s.update(seed)
+	s.counter = 1
+
+	return nil
+}
+
+// generate implements the HMAC_DRBG generate function, without additional
+// input.
+func (s *hmacDRBGSource) generate(out []byte) error {
+	var err error
+
+	switch {
+	case s.key == nil:
+		err = s.instantiate()
+	case s.counter > s.interval:
+		err = s.reseed()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for n := 0; n < len(out); {
+		s.v = s.mac(s.v)
+		n += copy(out[n:], s.v)
+	}
+
+	s.update(nil)
+	s.counter++
+
+	return nil
+}
+
+// update implements the HMAC_DRBG update function.
+func (s *hmacDRBGSource) update(data []byte) {
+	s.key = s.mac(s.v, []byte{0x00}, data)
+	s.v = s.mac(s.v)
+
+	if len(data) == 0 {
+		return
+	}
+
+	s.key = s.mac(s.v, []byte{0x01}, data)
+	s.v = s.mac(s.v)
+}
+
+// mac returns the HMAC-SHA-256 of the concatenated data with the current key.
+func (s *hmacDRBGSource) mac(data ...[]byte) []byte {
+	h := hmac.New(sha256.New, s.key)
+
+	for _, d := range data {
+		h.Write(d)
+	}
+
+	return h.Sum(nil)
+}
+
+// fileSource reads the random bytes from a file or device.
+type fileSource struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+}
+
+// NewFileSource returns the source reading from the file or device at the
+// given path, which stays open for the lifetime of the process.
+func NewFileSource(path string) (Source, error) {
+	f, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errSource, err)
+	}
+
+	return &fileSource{path: path, f: f}, nil
+}
+
+// Read implements io.Reader. The buffer is always filled, as devices like
+// /dev/hwrng can return short reads.
+func (s *fileSource) Read(b []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, err := io.ReadFull(s.f, b)
+	if err != nil {
+		return n, fmt.Errorf("%w: %s: %w", errSource, s.path, err)
+	}
+
+	return n, nil
+}
+
+// Name implements Source.
+func (s *fileSource) Name() string {
+	return SourceFile
+}
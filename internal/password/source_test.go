@@ -0,0 +1,214 @@
+package password
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+// seedBytes returns the bytes 0, 1, ..., n-1.
+func seedBytes(n int) []byte {
+	b := make([]byte, n)
+
+	for i := range b {
+		b[i] = byte(i)
+	}
+
+	return b
+}
+
+func TestNewSource(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		typ      string
+		path     string
+		wantName string
+		wantErr  bool
+	}{
+		{
+			name:     "os",
+			typ:      SourceOS,
+			wantName: "os",
+		},
+		{
+			name:     "chacha20",
+			typ:      SourceChaCha20,
+			wantName: "chacha20",
+		},
+		{
+			name:     "hmac_drbg",
+			typ:      SourceHMACDRBG,
+			wantName: "hmac_drbg",
+		},
+		{
+			name:     "file",
+			typ:      SourceFile,
+			path:     "/dev/urandom",
+			wantName: "file",
+		},
+		{
+			name:    "missing file",
+			typ:     SourceFile,
+			path:    filepath.Join(t.TempDir(), "missing"),
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			typ:     "rdrand",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			src, err := NewSource(tt.typ, tt.path, 8)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errSource)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.wantName, src.Name())
+			require.NoError(t, CheckSource(src))
+
+			// reads larger than a single DRBG request are split
+			buf := make([]byte, drbgMaxRequest+100)
+			n, err := src.Read(buf)
+			require.NoError(t, err)
+			require.Len(t, buf, n)
+		})
+	}
+}
+
+func TestHMACDRBGSource(t *testing.T) {
+	t.Parallel()
+
+	// The reseed interval of 2 reads makes the third read consume the reseed
+	// entropy, following the instantiation entropy input and nonce.
+	s := &hmacDRBGSource{seed: bytes.NewReader(seedBytes(80)), interval: 2}
+
+	want := []string{
+		"050d2215dc7d12de9625cbf4d62039afd0eb644a7acb978170ea7f0c97ce8275d84a0190c355395e",
+		"8f2feb47bb39e248130769bd5f53de03",
+		"3e83752646eab51893d1a65dcb89b3c2",
+	}
+
+	for _, w := range want {
+		buf := make([]byte, len(w)/2)
+
+		_, err := s.Read(buf)
+		require.NoError(t, err)
+		require.Equal(t, w, hex.EncodeToString(buf))
+	}
+
+	_, err := s.Read(make([]byte, 16))
+	require.NoError(t, err)
+
+	// the seed is exhausted on the next reseed
+	_, err = s.Read(make([]byte, 16))
+	require.ErrorIs(t, err, errSource)
+
+	s = &hmacDRBGSource{seed: iotest.ErrReader(errors.New("no entropy")), interval: 2}
+
+	_, err = s.Read(make([]byte, 16))
+	require.ErrorIs(t, err, errSource)
+}
+
+func TestChaCha20Source(t *testing.T) {
+	t.Parallel()
+
+	a := &chacha20Source{seed: bytes.NewReader(seedBytes(64)), interval: 2}
+	b := &chacha20Source{seed: bytes.NewReader(seedBytes(64)), interval: 2}
+
+	var prev []byte
+
+	for range 4 {
+		bufA := make([]byte, 48)
+		bufB := make([]byte, 48)
+
+		_, err := a.Read(bufA)
+		require.NoError(t, err)
+
+		_, err = b.Read(bufB)
+		require.NoError(t, err)
+
+		require.Equal(t, bufA, bufB)
+		require.NotEqual(t, prev, bufA)
+
+		prev = bufA
+	}
+
+	// the seed is exhausted
+	_, err := a.Read(make([]byte, 16))
+	require.ErrorIs(t, err, errSource)
+}
+
+func TestFileSource(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "entropy")
+	require.NoError(t, os.WriteFile(path, seedBytes(40), 0o600))
+
+	src, err := NewFileSource(path)
+	require.NoError(t, err)
+	require.NoError(t, CheckSource(src))
+
+	buf := make([]byte, 16)
+	_, err = src.Read(buf)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.ErrorIs(t, err, errSource)
+}
+
+func TestCheckSource(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "zeros")
+	require.NoError(t, os.WriteFile(path, make([]byte, 64), 0o600))
+
+	src, err := NewFileSource(path)
+	require.NoError(t, err)
+	require.ErrorIs(t, CheckSource(src), errSource)
+
+	src = &chacha20Source{seed: iotest.ErrReader(errors.New("no entropy")), interval: 1}
+	require.ErrorIs(t, CheckSource(src), errSource)
+}
+
+func TestGenerateWithSource(t *testing.T) {
+	t.Parallel()
+
+	newSrc := func() Source {
+		return &chacha20Source{seed: bytes.NewReader(seedBytes(64)), interval: DefaultReseedInterval}
+	}
+
+	pwdA, err := New("abc", 16, 4, WithSource(newSrc())).Generate()
+	require.NoError(t, err)
+
+	pwdB, err := New("abc", 16, 4, WithSource(newSrc())).Generate()
+	require.NoError(t, err)
+	require.Equal(t, pwdA, pwdB)
+
+	ppA, err := NewPassphrase(WordlistEFFShort, 4, 4, WithPassphraseSource(newSrc())).Generate()
+	require.NoError(t, err)
+
+	ppB, err := NewPassphrase(WordlistEFFShort, 4, 4, WithPassphraseSource(newSrc())).Generate()
+	require.NoError(t, err)
+	require.Equal(t, ppA, ppB)
+
+	pinA, err := NewPIN(6, 4, WithPINSource(newSrc())).Generate()
+	require.NoError(t, err)
+
+	pinB, err := NewPIN(6, 4, WithPINSource(newSrc())).Generate()
+	require.NoError(t, err)
+	require.Equal(t, pinA, pinB)
+}
@@ -0,0 +1,86 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/tecnickcom/rndpwd/internal/pattern"
+)
+
+// errTemplate is wrapped by all the errors reporting an unusable template.
+var errTemplate = errors.New("invalid template")
+
+// templateSampler draws passwords uniformly from the strings matching a
+// template, each position taking a uniformly random character of its class.
+type templateSampler struct {
+	slots []pattern.Slot
+}
+
+// newTemplateSampler parses the template, where the any class (*) takes the
+// characters of the effective charset. When excludeAmbiguous is set, the
+// AmbiguousChars are also removed from the other classes, but not from the
+// literals.
+func newTemplateSampler(tpl, charset string, excludeAmbiguous bool) (*templateSampler, error) {
+	slots, err := pattern.Parse(tpl, charset)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errTemplate, err)
+	}
+
+	for i, s := range slots {
+		if s.Literal {
+			continue
+		}
+
+		chars := s.Chars
+
+		if excludeAmbiguous {
+			chars = slices.DeleteFunc(slices.Clone(chars), func(c rune) bool {
+				return strings.ContainsRune(AmbiguousChars, c)
+			})
+		}
+
+		if len(chars) == 0 {
+			return nil, fmt.Errorf("%w: position %d: no character is left in the class", errTemplate, i+1)
+		}
+
+		slots[i].Chars = chars
+	}
+
+	return &templateSampler{slots: slots}, nil
+}
+
+// sample returns a random password matching the template.
+func (s *templateSampler) sample(reader io.Reader) (string, error) {
+	out := make([]rune, len(s.slots))
+
+	for i, slot := range s.slots {
+		if len(slot.Chars) == 1 {
+			out[i] = slot.Chars[0]
+			continue
+		}
+
+		n, err := randInt(reader, len(slot.Chars))
+		if err != nil {
+			return "", err
+		}
+
+		out[i] = slot.Chars[n]
+	}
+
+	return string(out), nil
+}
+
+// size returns the number of passwords matching the template.
+func (s *templateSampler) size() *big.Int {
+	n := big.NewInt(1)
+
+	for _, slot := range s.slots {
+		n.Mul(n, big.NewInt(int64(len(slot.Chars))))
+	}
+
+	return n
+}
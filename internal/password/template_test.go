@@ -0,0 +1,84 @@
+package password
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/rndpwd/internal/validator"
+)
+
+func TestGenerateTemplate(t *testing.T) {
+	t.Parallel()
+
+	p := New(validator.ValidCharset, 8, 200, WithMode(ModeTemplate), WithTemplate("Cvcc-9{4}-SS"))
+
+	pwds, err := p.Generate()
+	require.NoError(t, err)
+	require.Len(t, pwds, 200)
+
+	for _, pwd := range pwds {
+		require.Regexp(t, `^[B-DF-HJ-NP-TV-Z][aeiou][b-df-hj-np-tv-z]{2}-[0-9]{4}-[^A-Za-z0-9]{2}$`, pwd)
+	}
+}
+
+func TestTemplateEntropy(t *testing.T) {
+	t.Parallel()
+
+	// the length is ignored in template mode
+	p := New("0123456789abcdef", 32, 1, WithMode(ModeTemplate), WithTemplate("a-9{2}*"))
+
+	bits, err := p.Entropy()
+	require.NoError(t, err)
+	require.InDelta(t, math.Log2(26*10*10*16), bits, 1e-9)
+}
+
+func TestTemplateExcludeAmbiguous(t *testing.T) {
+	t.Parallel()
+
+	p := New(validator.ValidCharset, 8, 500, WithMode(ModeTemplate), WithTemplate(`x{4}\1`), WithExcludeAmbiguous(true))
+
+	pwds, err := p.Generate()
+	require.NoError(t, err)
+
+	for _, pwd := range pwds {
+		// the literals are kept
+		require.True(t, strings.HasSuffix(pwd, "1"), pwd)
+		require.False(t, strings.ContainsAny(pwd[:4], AmbiguousChars), pwd)
+	}
+}
+
+func TestTemplateError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		p       *Password
+		wantErr error
+	}{
+		{
+			name:    "malformed template",
+			p:       New(validator.ValidCharset, 8, 1, WithMode(ModeTemplate), WithTemplate("a{0}")),
+			wantErr: errTemplate,
+		},
+		{
+			name:    "empty class after exclusion",
+			p:       New(validator.ValidCharset, 8, 1, WithMode(ModeTemplate), WithTemplate("[0O1l]"), WithExcludeAmbiguous(true)),
+			wantErr: errTemplate,
+		},
+		{
+			name:    "with policy",
+			p:       New(validator.ValidCharset, 8, 1, WithMode(ModeTemplate), WithTemplate("a"), WithPolicy(Policy{MinDigit: 1})),
+			wantErr: errPolicy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.ErrorIs(t, tt.p.CheckPolicy(), tt.wantErr)
+		})
+	}
+}
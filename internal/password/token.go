@@ -0,0 +1,188 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tecnickcom/rndpwd/internal/charset"
+)
+
+// Bounds of the entropy of the API token body, in bits.
+const (
+	MinTokenBits = 128
+	MaxTokenBits = 512
+)
+
+// Settings of the prefixed API tokens.
+const (
+	// tokenAlphabet is the base62 alphabet of the token body and checksum.
+	tokenAlphabet = charset.Digits + charset.Upper + charset.Lower
+
+	// tokenChecksumSize is the number of base62 characters of the CRC32
+	// checksum, enough for any 32-bit value.
+	tokenChecksumSize = 6
+)
+
+// ErrInvalidTokenPrefix is returned when generating tokens with a prefix that
+// is not valid.
+var ErrInvalidTokenPrefix = errors.New("the token prefix must be made of lowercase letters and digits, each word followed by an underscore")
+
+// tokenPrefixPattern matches the valid token prefixes: lowercase words of
+// letters and digits, each one followed by an underscore, like acme_live_.
+var tokenPrefixPattern = regexp.MustCompile(`^[a-z][a-z0-9]*_([a-z0-9]+_)*$`) //nolint:gochecknoglobals
+
+// Token contains the prefixed API token generator configuration.
+//
+// Each token is made of the prefix, a random base62 body and the base62 CRC32
+// checksum of the prefix and body, so the tokens can be matched by secret
+// scanners and the typos can be detected offline, as with the GitHub tokens.
+type Token struct {
+	Prefix   string `json:"prefix"   validate:"required,max=32"`
+	Bits     int    `json:"bits"     validate:"required,min=128,max=512"`
+	Quantity int    `json:"quantity" validate:"required,min=1,max=1000"`
+	source   Source
+}
+
+// TokenCheck is the result of the offline validation of a token.
+type TokenCheck struct {
+	// Valid reports whether the token is well formed and its checksum matches.
+	Valid bool `json:"valid"`
+
+	// Prefix is the prefix of the token, when found.
+	Prefix string `json:"prefix,omitempty"`
+
+	// Reason explains why the token is not valid.
+	Reason string `json:"reason,omitempty"`
+}
+
+// TokenOption is a type to allow setting custom API token options.
+type TokenOption func(t *Token)
+
+// WithTokenSource sets the entropy source of the token bodies (default the OS
+// CSPRNG).
+func WithTokenSource(src Source) TokenOption {
+	return func(t *Token) {
+		t.source = src
+	}
+}
+
+// NewToken instantiate a new prefixed API token generator object, with the
+// given prefix and minimum entropy in bits of the body.
+func NewToken(prefix string, bits, quantity int, opts ...TokenOption) *Token {
+	t := &Token{
+		Prefix:   prefix,
+		Bits:     bits,
+		Quantity: quantity,
+		source:   NewOSSource(),
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(t)
+	}
+
+	return t
+}
+
+// ValidTokenPrefix reports whether the prefix is made of lowercase words of
+// letters and digits, each one followed by an underscore.
+func ValidTokenPrefix(prefix string) bool {
+	return tokenPrefixPattern.MatchString(prefix)
+}
+
+// BodyLength returns the number of base62 characters of the random body, the
+// minimum that reaches the configured entropy.
+func (t *Token) BodyLength() int {
+	return tokenBodyLength(t.Bits)
+}
+
+// Entropy returns the entropy in bits of each token.
+func (t *Token) Entropy() float64 {
+	return float64(t.BodyLength()) * math.Log2(float64(len(tokenAlphabet)))
+}
+
+// Length returns the number of characters of each token.
+func (t *Token) Length() int {
+	return len(t.Prefix) + t.BodyLength() + tokenChecksumSize
+}
+
+// Pattern returns the regular expression matching the tokens, for the secret
+// scanners.
+func (t *Token) Pattern() string {
+	return `\b` + regexp.QuoteMeta(t.Prefix) + `[0-9A-Za-z]{` + strconv.Itoa(t.BodyLength()+tokenChecksumSize) + `}\b`
+}
+
+// Generate returns the specified amount of random tokens. The bodies are drawn
+// by the password generator, from the same entropy source.
+func (t *Token) Generate() ([]string, error) {
+	if !ValidTokenPrefix(t.Prefix) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTokenPrefix, t.Prefix)
+	}
+
+	bodies, err := New(tokenAlphabet, t.BodyLength(), t.Quantity, WithSource(t.source)).Generate()
+	if err != nil {
+		return nil, fmt.Errorf("failed generating the token bodies: %w", err)
+	}
+
+	for i, body := range bodies {
+		bodies[i] = t.Prefix + body + tokenChecksum(t.Prefix+body)
+	}
+
+	return bodies, nil
+}
+
+// ValidateToken checks the format and checksum of the token, without any
+// storage. The prefix of the token must match the given one, when not empty.
+func ValidateToken(token, prefix string) TokenCheck {
+	i := strings.LastIndexByte(token, '_')
+	if i < 0 || !ValidTokenPrefix(token[:i+1]) {
+		return TokenCheck{Reason: "missing or invalid prefix"}
+	}
+
+	res := TokenCheck{Prefix: token[:i+1]}
+
+	if prefix != "" && res.Prefix != prefix {
+		res.Reason = "unexpected prefix"
+		return res
+	}
+
+	rest := token[i+1:]
+	if len(rest) < tokenBodyLength(MinTokenBits)+tokenChecksumSize || len(rest) > tokenBodyLength(MaxTokenBits)+tokenChecksumSize || strings.Trim(rest, tokenAlphabet) != "" {
+		res.Reason = "invalid body"
+		return res
+	}
+
+	body, sum := rest[:len(rest)-tokenChecksumSize], rest[len(rest)-tokenChecksumSize:]
+	if tokenChecksum(res.Prefix+body) != sum {
+		res.Reason = "checksum mismatch"
+		return res
+	}
+
+	res.Valid = true
+
+	return res
+}
+
+// tokenBodyLength returns the number of base62 characters with at least the
+// given entropy.
+func tokenBodyLength(bits int) int {
+	return int(math.Ceil(float64(bits) / math.Log2(float64(len(tokenAlphabet)))))
+}
+
+// tokenChecksum returns the CRC32 (IEEE) checksum of the string, as fixed-size
+// base62 with the most significant digit first.
+func tokenChecksum(s string) string {
+	v := crc32.ChecksumIEEE([]byte(s))
+	out := make([]byte, tokenChecksumSize)
+
+	for i := tokenChecksumSize - 1; i >= 0; i-- {
+		out[i] = tokenAlphabet[v%uint32(len(tokenAlphabet))]
+		v /= uint32(len(tokenAlphabet))
+	}
+
+	return string(out)
+}
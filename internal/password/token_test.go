@@ -0,0 +1,122 @@
+package password
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidTokenPrefix(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		prefix string
+		valid  bool
+	}{
+		{"acme_", true},
+		{"acme_live_", true},
+		{"a1_b2_c3_", true},
+		{"", false},
+		{"acme", false},
+		{"_acme_", false},
+		{"1acme_", false},
+		{"acme__live_", false},
+		{"Acme_", false},
+		{"acme-live_", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.valid, ValidTokenPrefix(tt.prefix))
+		})
+	}
+}
+
+func TestTokenChecksum(t *testing.T) {
+	t.Parallel()
+
+	// CRC32 of "123456789" is 0xCBF43926 = 3421780262
+	require.Equal(t, "3jZRME", tokenChecksum("123456789"))
+	require.Equal(t, "000000", tokenChecksum(""))
+}
+
+func TestTokenGenerate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		bits       int
+		wantLength int
+	}{
+		{MinTokenBits, 22},
+		{160, 27},
+		{178, 30},
+		{MaxTokenBits, 86},
+	}
+
+	for _, tt := range tests {
+		tok := NewToken("acme_live_", tt.bits, 20)
+
+		require.Equal(t, tt.wantLength, tok.BodyLength())
+		require.GreaterOrEqual(t, tok.Entropy(), float64(tt.bits))
+		require.Equal(t, 10+tt.wantLength+6, tok.Length())
+
+		pattern := regexp.MustCompile(tok.Pattern())
+
+		lst, err := tok.Generate()
+		require.NoError(t, err)
+		require.Len(t, lst, 20)
+
+		for _, token := range lst {
+			require.Len(t, token, tok.Length())
+			require.True(t, pattern.MatchString("key="+token+"\n"), token)
+			require.Equal(t, TokenCheck{Valid: true, Prefix: "acme_live_"}, ValidateToken(token, ""))
+			require.Equal(t, TokenCheck{Valid: true, Prefix: "acme_live_"}, ValidateToken(token, "acme_live_"))
+		}
+	}
+}
+
+func TestTokenGenerateInvalidPrefix(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewToken("acme", 128, 1).Generate()
+	require.ErrorIs(t, err, ErrInvalidTokenPrefix)
+}
+
+func TestValidateToken(t *testing.T) {
+	t.Parallel()
+
+	body := strings.Repeat("a", 27)
+	token := "acme_live_" + body + tokenChecksum("acme_live_"+body)
+
+	// a single character typo in the body
+	typo := "acme_live_b" + body[1:] + token[len(token)-6:]
+
+	tests := []struct {
+		name   string
+		token  string
+		prefix string
+		want   TokenCheck
+	}{
+		{"valid", token, "", TokenCheck{Valid: true, Prefix: "acme_live_"}},
+		{"expected prefix", token, "acme_live_", TokenCheck{Valid: true, Prefix: "acme_live_"}},
+		{"unexpected prefix", token, "acme_test_", TokenCheck{Prefix: "acme_live_", Reason: "unexpected prefix"}},
+		{"typo", typo, "", TokenCheck{Prefix: "acme_live_", Reason: "checksum mismatch"}},
+		{"missing prefix", body, "", TokenCheck{Reason: "missing or invalid prefix"}},
+		{"invalid prefix", "Acme_" + body, "", TokenCheck{Reason: "missing or invalid prefix"}},
+		{"short body", "acme_" + body[:20], "", TokenCheck{Prefix: "acme_", Reason: "invalid body"}},
+		{"long body", "acme_" + strings.Repeat(body, 4), "", TokenCheck{Prefix: "acme_", Reason: "invalid body"}},
+		{"invalid character", "acme_" + body + "-00000", "", TokenCheck{Prefix: "acme_", Reason: "invalid body"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, ValidateToken(tt.token, tt.prefix))
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Bounds of the unique batches.
+const (
+	// uniqueKeyspaceFactor is the minimum ratio between the keyspace and the
+	// quantity of a unique batch. Beyond half of the keyspace most of the draws
+	// would be duplicates, so the request is rejected instead.
+	uniqueKeyspaceFactor = 2
+
+	// maxUniqueAttempts is the maximum number of consecutive duplicates drawn
+	// for a single password. With at most half of the keyspace taken, the odds
+	// of reaching it are below 2^-64.
+	maxUniqueAttempts = 64
+)
+
+// ErrKeyspaceTooSmall is returned when the keyspace can't provide the requested
+// quantity of unique passwords.
+var ErrKeyspaceTooSmall = errors.New("keyspace too small for unique passwords")
+
+// errDuplicates is returned when no new password is found for a unique batch.
+var errDuplicates = errors.New("only duplicate passwords generated")
+
+// WithUnique guarantees that the passwords returned by Generate are all
+// distinct. The quantity must not exceed half of the keyspace.
+func WithUnique(enable bool) Option {
+	return func(p *Password) {
+		p.Unique = enable
+	}
+}
+
+// CheckUnique reports whether the keyspace is large enough for the configured
+// quantity of unique passwords, so the request fails fast instead of drawing
+// duplicates for long. It always succeeds when the uniqueness is not required.
+func (p *Password) CheckUnique() error {
+	if !p.Unique {
+		return nil
+	}
+
+	keyspace, err := p.Keyspace()
+	if err != nil {
+		return err
+	}
+
+	need := big.NewInt(int64(p.Quantity) * uniqueKeyspaceFactor)
+	if keyspace.Cmp(need) < 0 {
+		return fmt.Errorf("%w: %d unique passwords require a keyspace of at least %s, the settings only allow %s", ErrKeyspaceTooSmall, p.Quantity, need, keyspace)
+	}
+
+	return nil
+}
+
+// uniqueSource returns the function drawing passwords from next until one was
+// not returned before.
+func uniqueSource(size int, next func() (string, error)) func() (string, error) {
+	seen := make(map[string]struct{}, size)
+
+	return func() (string, error) {
+		for range maxUniqueAttempts {
+			pwd, err := next()
+			if err != nil {
+				return "", err
+			}
+
+			if _, ok := seen[pwd]; !ok {
+				seen[pwd] = struct{}{}
+				return pwd, nil
+			}
+		}
+
+		return "", fmt.Errorf("%w: no new password found after %d attempts", errDuplicates, maxUniqueAttempts)
+	}
+}
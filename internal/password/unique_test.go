@@ -0,0 +1,139 @@
+package password
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateUnique(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		charset  string
+		length   int
+		quantity int
+		opts     []Option
+	}{
+		{
+			name:     "random",
+			charset:  "01",
+			length:   4,
+			quantity: 8,
+		},
+		{
+			name:     "unicode",
+			charset:  "αβγ",
+			length:   2,
+			quantity: 4,
+		},
+		{
+			name:     "template",
+			charset:  "01",
+			length:   1,
+			quantity: 50,
+			opts:     []Option{WithMode(ModeTemplate), WithTemplate("99")},
+		},
+		{
+			name:     "policy",
+			charset:  "abAB",
+			length:   3,
+			quantity: 12,
+			opts:     []Option{WithPolicy(Policy{MinUpper: 1, MinLower: 1})},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := New(tt.charset, tt.length, tt.quantity, append(tt.opts, WithUnique(true))...)
+
+			// repeated to catch the duplicates that only show up occasionally
+			for range 20 {
+				pwds, err := p.Generate()
+				require.NoError(t, err)
+				require.Len(t, pwds, tt.quantity)
+
+				seen := make(map[string]bool, len(pwds))
+
+				for _, pwd := range pwds {
+					require.False(t, seen[pwd], "duplicate password %q", pwd)
+					seen[pwd] = true
+				}
+			}
+		})
+	}
+}
+
+func TestCheckUnique(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		quantity int
+		unique   bool
+		wantErr  bool
+	}{
+		{
+			name:     "half of the keyspace",
+			quantity: 8,
+			unique:   true,
+		},
+		{
+			name:     "above half of the keyspace",
+			quantity: 9,
+			unique:   true,
+			wantErr:  true,
+		},
+		{
+			name:     "above the keyspace",
+			quantity: 17,
+			unique:   true,
+			wantErr:  true,
+		},
+		{
+			name:     "not unique",
+			quantity: 17,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := New("01", 4, tt.quantity, WithUnique(tt.unique))
+
+			err := p.CheckUnique()
+			if !tt.wantErr {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, ErrKeyspaceTooSmall)
+
+			// the batch fails fast instead of drawing duplicates
+			_, err = p.Generate()
+			require.ErrorIs(t, err, ErrKeyspaceTooSmall)
+		})
+	}
+
+	_, err := New("", 4, 1, WithUnique(true)).Generate()
+	require.ErrorIs(t, err, errEmptyCharset)
+}
+
+func TestUniqueSourceDuplicates(t *testing.T) {
+	t.Parallel()
+
+	next := uniqueSource(2, func() (string, error) {
+		return "same", nil
+	})
+
+	pwd, err := next()
+	require.NoError(t, err)
+	require.Equal(t, "same", pwd)
+
+	_, err = next()
+	require.ErrorIs(t, err, errDuplicates)
+}
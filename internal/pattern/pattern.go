@@ -0,0 +1,291 @@
+// Package pattern parses the password templates.
+//
+// A template describes each position of a password, from left to right:
+//
+//	c  lowercase consonant      C  uppercase consonant
+//	v  lowercase vowel          V  uppercase vowel
+//	a  lowercase letter         A  uppercase letter
+//	9  digit                    x  letter or digit
+//	S  symbol                   *  any character of the charset
+//
+// A custom class is defined between square brackets, as a preset name, a class
+// expression or a literal list of characters, e.g. [hex] or [aeiou]. Any item
+// can be followed by a repetition count, e.g. a{8}. The other punctuation and
+// symbol characters are literals, as the dashes in Cvcc-9999-SS, and a
+// backslash turns the following character into a literal, e.g. \c or \{.
+package pattern
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/tecnickcom/rndpwd/internal/charset"
+)
+
+// MaxLength is the maximum number of positions of a template.
+const MaxLength = 256
+
+// Characters of the vowel and consonant classes.
+const (
+	lowerVowels     = "aeiou"
+	lowerConsonants = "bcdfghjklmnpqrstvwxyz"
+)
+
+// classes maps the class letters to their characters. The any class (*) is
+// resolved against the charset.
+var classes = map[rune]string{ //nolint:gochecknoglobals
+	'c': lowerConsonants,
+	'C': strings.ToUpper(lowerConsonants),
+	'v': lowerVowels,
+	'V': strings.ToUpper(lowerVowels),
+	'a': charset.Lower,
+	'A': charset.Upper,
+	'9': charset.Digits,
+	'x': charset.Upper + charset.Lower + charset.Digits,
+	'S': charset.Symbols,
+}
+
+// visible are the Unicode categories allowed in the passwords: letters,
+// numbers, punctuation and symbols.
+var visible = []*unicode.RangeTable{unicode.L, unicode.N, unicode.P, unicode.S} //nolint:gochecknoglobals
+
+// Template is a password template.
+type Template string
+
+// Problem returns the description of the syntax error of the template, or an
+// empty string if the template is valid. It is used by the validation error
+// messages.
+func (t Template) Problem() string {
+	_, err := Parse(string(t), charset.Printable)
+	if err != nil {
+		return err.Error()
+	}
+
+	return ""
+}
+
+// Slot is a position of the template.
+type Slot struct {
+	// Chars are the characters that can appear at this position.
+	Chars []rune
+
+	// Literal reports whether the position is a fixed character.
+	Literal bool
+}
+
+// SyntaxError reports a malformed template.
+type SyntaxError struct {
+	// Pos is the position of the error, in characters, starting from 1.
+	Pos int
+
+	// Msg describes the error.
+	Msg string
+}
+
+// Error implements the error interface.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("position %d: %s", e.Pos, e.Msg)
+}
+
+// parser holds the state of the template parsing.
+type parser struct {
+	src   []rune
+	pos   int
+	chars []rune // characters of the any class
+	slots []Slot
+	last  []Slot // slots of the last item, for the repetitions
+}
+
+// Parse returns the positions of the template, where the any class (*) takes
+// the characters of the charset.
+func Parse(tpl, chars string) ([]Slot, error) {
+	p := &parser{src: []rune(tpl), chars: uniqueRunes(chars)}
+
+	for p.pos < len(p.src) {
+		start := p.pos
+
+		err := p.next()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(p.slots) > MaxLength {
+			return nil, syntaxErrorf(start, "the template exceeds %d characters", MaxLength)
+		}
+	}
+
+	if len(p.slots) == 0 {
+		return nil, syntaxErrorf(0, "empty template")
+	}
+
+	return p.slots, nil
+}
+
+// next parses the item at the current position.
+func (p *parser) next() error {
+	start := p.pos
+	c := p.src[p.pos]
+	p.pos++
+
+	var (
+		item []Slot
+		err  error
+	)
+
+	switch {
+	case c == '{':
+		return p.repeat(start)
+	case c == '[':
+		item, err = p.custom(start)
+	case c == '\\':
+		item, err = p.escape(start)
+	case c == '*':
+		item = []Slot{{Chars: p.chars}}
+	case classes[c] != "":
+		item = []Slot{{Chars: []rune(classes[c])}}
+	default:
+		item, err = p.literal(start, c)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	p.slots = append(p.slots, item...)
+	p.last = item
+
+	return nil
+}
+
+// literal returns the slot of an unescaped literal character.
+func (p *parser) literal(start int, c rune) ([]Slot, error) {
+	switch {
+	case c == ']' || c == '}':
+		return nil, syntaxErrorf(start, "unexpected %q", c)
+	case unicode.IsLetter(c) || unicode.IsNumber(c):
+		return nil, syntaxErrorf(start, "unknown class %q, escape it as \\%c to use it as a literal", c, c)
+	case !unicode.IsPunct(c) && !unicode.IsSymbol(c):
+		return nil, syntaxErrorf(start, "invalid character %q", c)
+	}
+
+	return []Slot{{Chars: []rune{c}, Literal: true}}, nil
+}
+
+// escape returns the slot of the escaped literal character.
+func (p *parser) escape(start int) ([]Slot, error) {
+	if p.pos == len(p.src) {
+		return nil, syntaxErrorf(start, "missing escaped character")
+	}
+
+	c := p.src[p.pos]
+	p.pos++
+
+	if !unicode.IsOneOf(visible, c) {
+		return nil, syntaxErrorf(start+1, "invalid character %q", c)
+	}
+
+	return []Slot{{Chars: []rune{c}, Literal: true}}, nil
+}
+
+// custom returns the slot of the custom class starting at the opening bracket.
+func (p *parser) custom(start int) ([]Slot, error) {
+	var sb strings.Builder
+
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		p.pos++
+
+		switch c {
+		case ']':
+			return p.customSlot(start, sb.String())
+		case '\\':
+			if p.pos == len(p.src) {
+				return nil, syntaxErrorf(p.pos-1, "missing escaped character")
+			}
+
+			c = p.src[p.pos]
+			p.pos++
+		}
+
+		sb.WriteRune(c)
+	}
+
+	return nil, syntaxErrorf(start, "missing closing ']'")
+}
+
+// customSlot returns the slot of the characters of a custom class.
+func (p *parser) customSlot(start int, def string) ([]Slot, error) {
+	if def == "" {
+		return nil, syntaxErrorf(start, "empty class")
+	}
+
+	runes := uniqueRunes(charset.Expand(def))
+
+	for _, c := range runes {
+		if !unicode.IsOneOf(visible, c) {
+			return nil, syntaxErrorf(start, "invalid character %q in class", c)
+		}
+	}
+
+	if len(runes) == 0 {
+		return nil, syntaxErrorf(start, "empty class")
+	}
+
+	return []Slot{{Chars: runes}}, nil
+}
+
+// repeat applies the repetition count starting at the opening brace to the
+// last item.
+func (p *parser) repeat(start int) error {
+	if p.last == nil {
+		return syntaxErrorf(start, "repetition without a preceding item")
+	}
+
+	end := slices.Index(p.src[p.pos:], '}')
+	if end < 0 {
+		return syntaxErrorf(start, "missing closing '}'")
+	}
+
+	num := string(p.src[p.pos : p.pos+end])
+	p.pos += end + 1
+
+	n, err := strconv.Atoi(num)
+	if err != nil || n < 1 || n > MaxLength || strings.ContainsAny(num, "+-") {
+		return syntaxErrorf(start+1, "invalid repetition count %q, it must be between 1 and %d", num, MaxLength)
+	}
+
+	for range n - 1 {
+		p.slots = append(p.slots, p.last...)
+
+		if len(p.slots) > MaxLength {
+			return syntaxErrorf(start, "the template exceeds %d characters", MaxLength)
+		}
+	}
+
+	// repetitions can't be chained
+	p.last = nil
+
+	return nil
+}
+
+// syntaxErrorf returns a syntax error at the given zero-based position.
+func syntaxErrorf(pos int, format string, args ...any) error {
+	return &SyntaxError{Pos: pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+// uniqueRunes returns the characters of s without duplicates, in order of
+// first appearance.
+func uniqueRunes(s string) []rune {
+	var out []rune
+
+	for _, c := range s {
+		if !slices.Contains(out, c) {
+			out = append(out, c)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,182 @@
+package pattern
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// slotStrings returns the characters of each slot.
+func slotStrings(slots []Slot) []string {
+	out := make([]string, len(slots))
+
+	for i, s := range slots {
+		out[i] = string(s.Chars)
+	}
+
+	return out
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		tpl     string
+		want    []string
+		wantPos int
+	}{
+		{
+			name: "classes and literals",
+			tpl:  "Cv9-S",
+			want: []string{"BCDFGHJKLMNPQRSTVWXYZ", "aeiou", "0123456789", "-", "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"},
+		},
+		{
+			name: "repetition",
+			tpl:  "9{3}v",
+			want: []string{"0123456789", "0123456789", "0123456789", "aeiou"},
+		},
+		{
+			name: "custom class",
+			tpl:  "[hex][aab]{2}",
+			want: []string{"0123456789abcdef", "ab", "ab"},
+		},
+		{
+			name: "literal class with operators",
+			tpl:  "[0-9a-f][upper+vowels]",
+			want: []string{"0-9af", "uper+vowls"},
+		},
+		{
+			name: "escaped class bracket",
+			tpl:  `[x\]]`,
+			want: []string{"x]"},
+		},
+		{
+			name: "escapes",
+			tpl:  `\c\{\9`,
+			want: []string{"c", "{", "9"},
+		},
+		{
+			name: "any",
+			tpl:  "*",
+			want: []string{"ab"},
+		},
+		{
+			name: "unicode literal",
+			tpl:  "a€",
+			want: []string{"abcdefghijklmnopqrstuvwxyz", "€"},
+		},
+		{
+			name:    "empty",
+			tpl:     "",
+			wantPos: 1,
+		},
+		{
+			name:    "unknown class",
+			tpl:     "Cvb",
+			wantPos: 3,
+		},
+		{
+			name:    "space",
+			tpl:     "aa a",
+			wantPos: 3,
+		},
+		{
+			name:    "repetition without item",
+			tpl:     "{2}",
+			wantPos: 1,
+		},
+		{
+			name:    "chained repetition",
+			tpl:     "a{2}{2}",
+			wantPos: 5,
+		},
+		{
+			name:    "invalid repetition count",
+			tpl:     "aa{x}",
+			wantPos: 4,
+		},
+		{
+			name:    "zero repetition count",
+			tpl:     "a{0}",
+			wantPos: 3,
+		},
+		{
+			name:    "unterminated repetition",
+			tpl:     "a{2",
+			wantPos: 2,
+		},
+		{
+			name:    "unexpected brace",
+			tpl:     "a}",
+			wantPos: 2,
+		},
+		{
+			name:    "unterminated class",
+			tpl:     "a[abc",
+			wantPos: 2,
+		},
+		{
+			name:    "empty class",
+			tpl:     "a[]",
+			wantPos: 2,
+		},
+		{
+			name:    "space in class",
+			tpl:     "[a b]",
+			wantPos: 1,
+		},
+		{
+			name:    "trailing escape",
+			tpl:     `a\`,
+			wantPos: 2,
+		},
+		{
+			name:    "escaped space",
+			tpl:     `a\ `,
+			wantPos: 3,
+		},
+		{
+			name:    "trailing escape in class",
+			tpl:     `[a\`,
+			wantPos: 3,
+		},
+		{
+			name:    "too long",
+			tpl:     "a{200}9{57}",
+			wantPos: 8,
+		},
+		{
+			name:    "too many items",
+			tpl:     strings.Repeat("a", MaxLength+1),
+			wantPos: MaxLength + 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Parse(tt.tpl, "abba")
+			if tt.wantPos > 0 {
+				var serr *SyntaxError
+
+				require.ErrorAs(t, err, &serr)
+				require.Equal(t, tt.wantPos, serr.Pos, err.Error())
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, slotStrings(got))
+		})
+	}
+}
+
+func TestTemplateProblem(t *testing.T) {
+	t.Parallel()
+
+	require.Empty(t, Template("Cvcc-9999-SS").Problem())
+	require.Equal(t, `position 3: unknown class 'b', escape it as \b to use it as a literal`, Template("Cvb").Problem())
+}
@@ -0,0 +1,293 @@
+// Package pwhash hashes passwords for storage, in the formats expected by the
+// databases and directories that verify them:
+//
+//   - Bcrypt: crypt(3) format, $2a$<cost>$<salt+hash>.
+//   - Argon2id: PHC format, $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<hash>.
+//   - Scrypt: PHC format, $scrypt$ln=<log2 N>,r=<r>,p=<p>$<salt>$<hash>.
+//   - PBKDF2SHA256: PHC format, $pbkdf2-sha256$i=<iterations>,l=<length>$<salt>$<hash>.
+//   - SHA512Crypt: crypt(3) format, $6$rounds=<rounds>$<salt>$<hash>.
+//
+// The salts are always drawn from the OS CSPRNG.
+package pwhash
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Hash algorithms.
+const (
+	// Bcrypt is the Blowfish-based bcrypt.
+	Bcrypt = "bcrypt"
+
+	// Argon2id is the memory-hard Argon2id, winner of the Password Hashing
+	// Competition.
+	Argon2id = "argon2id"
+
+	// Scrypt is the memory-hard scrypt.
+	Scrypt = "scrypt"
+
+	// PBKDF2SHA256 is PBKDF2 with HMAC-SHA256.
+	PBKDF2SHA256 = "pbkdf2-sha256"
+
+	// SHA512Crypt is the SHA-512 based crypt of glibc.
+	SHA512Crypt = "sha512-crypt"
+)
+
+// Sizes in bytes of the salts and derived keys of the PHC formats.
+const (
+	saltSize = 16
+	keySize  = 32
+)
+
+// Absolute bounds of the parameters, set by the algorithms.
+const (
+	minBcryptCost     = bcrypt.MinCost
+	maxBcryptCost     = bcrypt.MaxCost
+	minArgon2Memory   = 8 // KiB per thread
+	maxArgon2Threads  = 255
+	maxScryptLN       = 62
+	maxScryptRP       = 1 << 30
+	minSHACryptRounds = 1000
+	maxSHACryptRounds = 999_999_999
+	maxBcryptPassword = 72 // bytes
+
+	// maxSHACryptPassword bounds the SHA-crypt passwords, whose hashing work
+	// grows with the length times the rounds.
+	maxSHACryptPassword = 256 // bytes
+)
+
+var (
+	// ErrInvalidParams is returned when the parameters are out of the bounds
+	// of the algorithm or of the configured limits.
+	ErrInvalidParams = errors.New("invalid hash parameters")
+
+	// ErrPasswordTooLong is returned when the password exceeds the maximum
+	// length of the algorithm.
+	ErrPasswordTooLong = errors.New("password too long for the hash algorithm")
+)
+
+// Params contains the work factors of all the algorithms, each algorithm only
+// using its own.
+type Params struct {
+	// Cost is the base-2 logarithm of the bcrypt iterations.
+	Cost int `json:"cost,omitempty"`
+
+	// Memory is the Argon2id memory in KiB.
+	Memory int `json:"memory,omitempty"`
+
+	// Time is the number of Argon2id passes over the memory.
+	Time int `json:"time,omitempty"`
+
+	// Threads is the Argon2id parallelism.
+	Threads int `json:"threads,omitempty"`
+
+	// LN is the base-2 logarithm of the scrypt CPU and memory cost N.
+	LN int `json:"ln,omitempty"`
+
+	// R is the scrypt block size.
+	R int `json:"r,omitempty"`
+
+	// P is the scrypt parallelism.
+	P int `json:"p,omitempty"`
+
+	// Iterations is the number of PBKDF2 iterations.
+	Iterations int `json:"iterations,omitempty"`
+
+	// Rounds is the number of SHA-512-crypt rounds.
+	Rounds int `json:"rounds,omitempty"`
+}
+
+// DefaultParams returns the default work factors, following the OWASP
+// recommendations where they exist.
+func DefaultParams() Params {
+	return Params{
+		Cost:       12,
+		Memory:     64 << 10,
+		Time:       3,
+		Threads:    4,
+		LN:         15,
+		R:          8,
+		P:          1,
+		Iterations: 600_000,
+		Rounds:     656_000,
+	}
+}
+
+// DefaultLimits returns the default upper bounds of the work factors a request
+// can ask for.
+func DefaultLimits() Params {
+	return Params{
+		Cost:       14,
+		Memory:     256 << 10,
+		Time:       10,
+		Threads:    8,
+		LN:         17,
+		R:          16,
+		P:          4,
+		Iterations: 2_000_000,
+		Rounds:     2_000_000,
+	}
+}
+
+// Algorithms returns the supported hash algorithms.
+func Algorithms() []string {
+	return []string{Bcrypt, Argon2id, Scrypt, PBKDF2SHA256, SHA512Crypt}
+}
+
+// ParamNames returns the names of the parameters used by the algorithm, as in
+// the Params JSON fields.
+func ParamNames(alg string) []string {
+	switch alg {
+	case Bcrypt:
+		return []string{"cost"}
+	case Argon2id:
+		return []string{"memory", "time", "threads"}
+	case Scrypt:
+		return []string{"ln", "r", "p"}
+	case PBKDF2SHA256:
+		return []string{"iterations"}
+	case SHA512Crypt:
+		return []string{"rounds"}
+	default:
+		return nil
+	}
+}
+
+// Check reports whether the parameters of the algorithm are within the bounds
+// of the algorithm and the given limits.
+func (p Params) Check(alg string, limit Params) error {
+	switch alg {
+	case Bcrypt:
+		return checkRange("bcrypt cost", p.Cost, minBcryptCost, min(limit.Cost, maxBcryptCost))
+	case Argon2id:
+		return firstErr(
+			checkRange("argon2id threads", p.Threads, 1, min(limit.Threads, maxArgon2Threads)),
+			checkRange("argon2id memory", p.Memory, minArgon2Memory*max(p.Threads, 1), limit.Memory),
+			checkRange("argon2id time", p.Time, 1, limit.Time),
+		)
+	case Scrypt:
+		return firstErr(
+			checkRange("scrypt ln", p.LN, 1, min(limit.LN, maxScryptLN)),
+			checkRange("scrypt r", p.R, 1, limit.R),
+			checkRange("scrypt p", p.P, 1, limit.P),
+			checkRange("scrypt r*p", p.R*p.P, 1, maxScryptRP-1),
+		)
+	case PBKDF2SHA256:
+		return checkRange("pbkdf2-sha256 iterations", p.Iterations, 1, limit.Iterations)
+	case SHA512Crypt:
+		return checkRange("sha512-crypt rounds", p.Rounds, minSHACryptRounds, min(limit.Rounds, maxSHACryptRounds))
+	default:
+		return fmt.Errorf("%w: unknown algorithm %q, expected one of %s", ErrInvalidParams, alg, strings.Join(Algorithms(), ", "))
+	}
+}
+
+// checkRange reports whether the value is between lo and hi.
+func checkRange(name string, v, lo, hi int) error {
+	if v < lo || v > hi {
+		return fmt.Errorf("%w: the %s must be between %d and %d", ErrInvalidParams, name, lo, hi)
+	}
+
+	return nil
+}
+
+// firstErr returns the first non-nil error.
+func firstErr(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CheckPassword reports whether a password of the given size in bytes can be
+// hashed with the algorithm: bcrypt only uses the first 72 bytes, and the
+// SHA-crypt work grows with the length, so both are bounded.
+func CheckPassword(alg string, size int) error {
+	switch algorithmFamily(alg) {
+	case Bcrypt:
+		if size > maxBcryptPassword {
+			return fmt.Errorf("%w: bcrypt only uses the first %d bytes", ErrPasswordTooLong, maxBcryptPassword)
+		}
+	case SHA512Crypt:
+		if size > maxSHACryptPassword {
+			return fmt.Errorf("%w: the %s passwords must be at most %d bytes", ErrPasswordTooLong, alg, maxSHACryptPassword)
+		}
+	}
+
+	return nil
+}
+
+// Hash returns the hash of the password with the algorithm and parameters, in
+// the format of the algorithm. The parameters are not checked against any
+// limit, see Params.Check.
+func Hash(alg string, p Params, password string) (string, error) {
+	err := CheckPassword(alg, len(password))
+	if err != nil {
+		return "", err
+	}
+
+	switch alg {
+	case Bcrypt:
+		return hashBcrypt(p, password)
+	case Argon2id, Scrypt, PBKDF2SHA256:
+		return hashPHC(alg, p, password)
+	case SHA512Crypt:
+		return hashSHA512Crypt(p, password)
+	default:
+		return "", fmt.Errorf("%w: unknown algorithm %q", ErrInvalidParams, alg)
+	}
+}
+
+// hashBcrypt returns the bcrypt hash of the password.
+func hashBcrypt(p Params, password string) (string, error) {
+	h, err := bcrypt.GenerateFromPassword([]byte(password), p.Cost)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidParams, err)
+	}
+
+	return string(h), nil
+}
+
+// hashPHC returns the hash of the password in the PHC string format.
+func hashPHC(alg string, p Params, password string) (string, error) {
+	salt := make([]byte, saltSize)
+	_, _ = rand.Read(salt)
+
+	var (
+		key    []byte
+		params string
+		err    error
+	)
+
+	switch alg {
+	case Argon2id:
+		key = argon2.IDKey([]byte(password), salt, uint32(p.Time), uint32(p.Memory), uint8(p.Threads), keySize) //nolint:gosec
+		params = fmt.Sprintf("v=%d$m=%d,t=%d,p=%d", argon2.Version, p.Memory, p.Time, p.Threads)
+	case Scrypt:
+		key, err = scrypt.Key([]byte(password), salt, 1<<p.LN, p.R, p.P, keySize)
+		params = fmt.Sprintf("ln=%d,r=%d,p=%d", p.LN, p.R, p.P)
+	default:
+		key, err = pbkdf2.Key(sha256.New, password, salt, p.Iterations, keySize)
+		params = fmt.Sprintf("i=%d,l=%d", p.Iterations, keySize)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidParams, err)
+	}
+
+	enc := base64.RawStdEncoding
+
+	return "$" + alg + "$" + params + "$" + enc.EncodeToString(salt) + "$" + enc.EncodeToString(key), nil
+}
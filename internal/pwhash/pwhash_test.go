@@ -0,0 +1,323 @@
+package pwhash
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+)
+
+func TestShaCrypt(t *testing.T) {
+	t.Parallel()
+
+	// test vectors of the SHA-crypt specification
+	tests := []struct {
+		password string
+		salt     string
+		rounds   int
+		explicit bool
+		want     string
+	}{
+		{
+			password: "Hello world!",
+			salt:     "saltstring",
+			rounds:   5000,
+			want:     "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1",
+		},
+		{
+			password: "Hello world!",
+			salt:     "saltstringsaltstring",
+			rounds:   10000,
+			explicit: true,
+			want:     "$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v.",
+		},
+		{
+			password: "This is just a test",
+			salt:     "toolongsaltstring",
+			rounds:   5000,
+			explicit: true,
+			want:     "$6$rounds=5000$toolongsaltstrin$lQ8jolhgVRVhY4b5pZKaysCLi0QBxGoNeKQzQ3glMhwllF7oGDZxUhx1yxdYcz/e1JSbq3y6JMxxl8audkUEm0",
+		},
+		{
+			password: "we have a short salt string but not a short password",
+			salt:     "short",
+			rounds:   77777,
+			explicit: true,
+			want:     "$6$rounds=77777$short$WuQyW2YR.hBNpjjRhpYD/ifIw05xdfeEyQoMxIXbkvr0gge1a1x3yRULJ5CCaUeOxFmtlcGZelFl5CxtgfiAc0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, shaCrypt([]byte(tt.password), []byte(tt.salt), tt.rounds, tt.explicit))
+		})
+	}
+}
+
+func TestHash(t *testing.T) {
+	t.Parallel()
+
+	p := Params{Cost: 4, Memory: 64, Time: 1, Threads: 2, LN: 4, R: 8, P: 1, Iterations: 1000, Rounds: 1000}
+	pwd := "correct horse battery staple"
+
+	tests := []struct {
+		alg    string
+		prefix string
+		verify func(t *testing.T, h string)
+	}{
+		{
+			alg:    Bcrypt,
+			prefix: "$2a$04$",
+			verify: func(t *testing.T, h string) {
+				t.Helper()
+				require.NoError(t, bcrypt.CompareHashAndPassword([]byte(h), []byte(pwd)))
+			},
+		},
+		{
+			alg:    Argon2id,
+			prefix: "$argon2id$v=19$m=64,t=1,p=2$",
+			verify: func(t *testing.T, h string) {
+				t.Helper()
+				salt, key := splitPHC(t, h)
+				require.Equal(t, argon2.IDKey([]byte(pwd), salt, 1, 64, 2, keySize), key)
+			},
+		},
+		{
+			alg:    Scrypt,
+			prefix: "$scrypt$ln=4,r=8,p=1$",
+			verify: func(t *testing.T, h string) {
+				t.Helper()
+				salt, key := splitPHC(t, h)
+				want, err := scrypt.Key([]byte(pwd), salt, 16, 8, 1, keySize)
+				require.NoError(t, err)
+				require.Equal(t, want, key)
+			},
+		},
+		{
+			alg:    PBKDF2SHA256,
+			prefix: "$pbkdf2-sha256$i=1000,l=32$",
+			verify: func(t *testing.T, h string) {
+				t.Helper()
+				salt, key := splitPHC(t, h)
+				want, err := pbkdf2.Key(sha256.New, pwd, salt, 1000, keySize)
+				require.NoError(t, err)
+				require.Equal(t, want, key)
+			},
+		},
+		{
+			alg:    SHA512Crypt,
+			prefix: "$6$rounds=1000$",
+			verify: func(t *testing.T, h string) {
+				t.Helper()
+				parts := strings.Split(h, "$")
+				require.Len(t, parts, 5)
+				require.Len(t, parts[3], shaCryptSaltSize)
+				require.Equal(t, h, shaCrypt([]byte(pwd), []byte(parts[3]), 1000, true))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			t.Parallel()
+
+			h, err := Hash(tt.alg, p, pwd)
+			require.NoError(t, err)
+			require.True(t, strings.HasPrefix(h, tt.prefix), "unexpected hash %q", h)
+			tt.verify(t, h)
+
+			// the salts are random
+			h2, err := Hash(tt.alg, p, pwd)
+			require.NoError(t, err)
+			require.NotEqual(t, h, h2)
+		})
+	}
+}
+
+// splitPHC returns the salt and key of a PHC string.
+func splitPHC(t *testing.T, h string) ([]byte, []byte) {
+	t.Helper()
+
+	parts := strings.Split(h, "$")
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[len(parts)-2])
+	require.NoError(t, err)
+	require.Len(t, salt, saltSize)
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[len(parts)-1])
+	require.NoError(t, err)
+
+	return salt, key
+}
+
+func TestHashErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := Hash("md5-crypt", DefaultParams(), "secret")
+	require.ErrorIs(t, err, ErrInvalidParams)
+
+	_, err = Hash(Bcrypt, Params{Cost: 4}, strings.Repeat("a", 73))
+	require.ErrorIs(t, err, ErrPasswordTooLong)
+
+	_, err = Hash(SHA512Crypt, Params{Rounds: 1000}, strings.Repeat("a", 257))
+	require.ErrorIs(t, err, ErrPasswordTooLong)
+
+	_, err = Hash(Bcrypt, Params{Cost: 99}, "secret")
+	require.ErrorIs(t, err, ErrInvalidParams)
+
+	_, err = Hash(Scrypt, Params{LN: 4, R: 0, P: 1}, "secret")
+	require.ErrorIs(t, err, ErrInvalidParams)
+}
+
+func TestCheckPassword(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		alg     string
+		size    int
+		wantErr bool
+	}{
+		{Bcrypt, 72, false},
+		{Bcrypt, 73, true},
+		{SHA512Crypt, 256, false},
+		{SHA512Crypt, 4096, true},
+		{SHA256Crypt, 257, true},
+		{Argon2id, 4096, false},
+		{PBKDF2SHA256, 4096, false},
+	}
+
+	for _, tt := range tests {
+		err := CheckPassword(tt.alg, tt.size)
+		if tt.wantErr {
+			require.ErrorIs(t, err, ErrPasswordTooLong, tt.alg)
+		} else {
+			require.NoError(t, err, tt.alg)
+		}
+	}
+}
+
+func TestParamsCheck(t *testing.T) {
+	t.Parallel()
+
+	limit := DefaultLimits()
+
+	tests := []struct {
+		name    string
+		alg     string
+		fp      func(p Params) Params
+		wantErr bool
+	}{
+		{name: "bcrypt", alg: Bcrypt},
+		{name: "argon2id", alg: Argon2id},
+		{name: "scrypt", alg: Scrypt},
+		{name: "pbkdf2-sha256", alg: PBKDF2SHA256},
+		{name: "sha512-crypt", alg: SHA512Crypt},
+		{
+			name:    "unknown algorithm",
+			alg:     "md5-crypt",
+			wantErr: true,
+		},
+		{
+			name:    "bcrypt cost above the limit",
+			alg:     Bcrypt,
+			fp:      func(p Params) Params { p.Cost = limit.Cost + 1; return p },
+			wantErr: true,
+		},
+		{
+			name:    "bcrypt cost below the minimum",
+			alg:     Bcrypt,
+			fp:      func(p Params) Params { p.Cost = 3; return p },
+			wantErr: true,
+		},
+		{
+			name:    "argon2id memory above the limit",
+			alg:     Argon2id,
+			fp:      func(p Params) Params { p.Memory = limit.Memory + 1; return p },
+			wantErr: true,
+		},
+		{
+			name:    "argon2id memory below the threads minimum",
+			alg:     Argon2id,
+			fp:      func(p Params) Params { p.Memory = 31; return p },
+			wantErr: true,
+		},
+		{
+			name:    "argon2id zero time",
+			alg:     Argon2id,
+			fp:      func(p Params) Params { p.Time = 0; return p },
+			wantErr: true,
+		},
+		{
+			name:    "argon2id threads above the limit",
+			alg:     Argon2id,
+			fp:      func(p Params) Params { p.Threads = limit.Threads + 1; return p },
+			wantErr: true,
+		},
+		{
+			name:    "scrypt ln above the limit",
+			alg:     Scrypt,
+			fp:      func(p Params) Params { p.LN = limit.LN + 1; return p },
+			wantErr: true,
+		},
+		{
+			name:    "scrypt zero p",
+			alg:     Scrypt,
+			fp:      func(p Params) Params { p.P = 0; return p },
+			wantErr: true,
+		},
+		{
+			name:    "pbkdf2-sha256 iterations above the limit",
+			alg:     PBKDF2SHA256,
+			fp:      func(p Params) Params { p.Iterations = limit.Iterations + 1; return p },
+			wantErr: true,
+		},
+		{
+			name:    "sha512-crypt rounds below the minimum",
+			alg:     SHA512Crypt,
+			fp:      func(p Params) Params { p.Rounds = 999; return p },
+			wantErr: true,
+		},
+		{
+			name: "other algorithm parameters ignored",
+			alg:  Bcrypt,
+			fp:   func(p Params) Params { p.Memory = limit.Memory + 1; return p },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := DefaultParams()
+			if tt.fp != nil {
+				p = tt.fp(p)
+			}
+
+			err := p.Check(tt.alg, limit)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidParams)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestParamNames(t *testing.T) {
+	t.Parallel()
+
+	for _, alg := range Algorithms() {
+		require.NotEmpty(t, ParamNames(alg), alg)
+	}
+
+	require.Empty(t, ParamNames("md5-crypt"))
+}
@@ -0,0 +1,193 @@
+package pwhash
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"hash"
+	"strconv"
+)
+
+// Settings of the SHA-512-crypt algorithm, as specified by Ulrich Drepper in
+// "Unix crypt using SHA-256 and SHA-512".
+const (
+	shaCryptPrefix        = "$6$"
+	sha256CryptPrefix     = "$5$"
+	shaCryptRoundsPrefix  = "rounds="
+	shaCryptDefaultRounds = 5000
+	shaCryptSaltSize      = 16
+)
+
+// cryptAlphabet is the base-64 alphabet of the crypt(3) hashes.
+const cryptAlphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// cryptGroup is a group of digest bytes encoded together in the crypt(3)
+// alphabet: the bytes at the indexes, the most significant first, or zero for
+// a negative index, are written as n characters.
+type cryptGroup struct {
+	idx [3]int
+	n   int
+}
+
+// sha512CryptOrder is the order in which the bytes of the final SHA-512-crypt
+// digest are encoded.
+var sha512CryptOrder = [...]cryptGroup{ //nolint:gochecknoglobals
+	{[3]int{0, 21, 42}, 4}, {[3]int{22, 43, 1}, 4}, {[3]int{44, 2, 23}, 4}, {[3]int{3, 24, 45}, 4},
+	{[3]int{25, 46, 4}, 4}, {[3]int{47, 5, 26}, 4}, {[3]int{6, 27, 48}, 4}, {[3]int{28, 49, 7}, 4},
+	{[3]int{50, 8, 29}, 4}, {[3]int{9, 30, 51}, 4}, {[3]int{31, 52, 10}, 4}, {[3]int{53, 11, 32}, 4},
+	{[3]int{12, 33, 54}, 4}, {[3]int{34, 55, 13}, 4}, {[3]int{56, 14, 35}, 4}, {[3]int{15, 36, 57}, 4},
+	{[3]int{37, 58, 16}, 4}, {[3]int{59, 17, 38}, 4}, {[3]int{18, 39, 60}, 4}, {[3]int{40, 61, 19}, 4},
+	{[3]int{62, 20, 41}, 4}, {[3]int{-1, -1, 63}, 2},
+}
+
+// sha256CryptOrder is the order in which the bytes of the final SHA-256-crypt
+// digest are encoded.
+var sha256CryptOrder = [...]cryptGroup{ //nolint:gochecknoglobals
+	{[3]int{0, 10, 20}, 4}, {[3]int{21, 1, 11}, 4}, {[3]int{12, 22, 2}, 4}, {[3]int{3, 13, 23}, 4},
+	{[3]int{24, 4, 14}, 4}, {[3]int{15, 25, 5}, 4}, {[3]int{6, 16, 26}, 4}, {[3]int{27, 7, 17}, 4},
+	{[3]int{18, 28, 8}, 4}, {[3]int{9, 19, 29}, 4}, {[3]int{-1, 31, 30}, 3},
+}
+
+// hashSHA512Crypt returns the SHA-512-crypt hash of the password with a random
+// salt.
+func hashSHA512Crypt(p Params, password string) (string, error) {
+	salt := make([]byte, shaCryptSaltSize)
+	_, _ = rand.Read(salt)
+
+	for i, b := range salt {
+		// 64 divides 256, so every character is equally likely
+		salt[i] = cryptAlphabet[b&63]
+	}
+
+	return shaCrypt([]byte(password), salt, p.Rounds, true), nil
+}
+
+// shaCrypt returns the SHA-512-crypt hash of the password with the salt, which
+// is truncated to 16 bytes. The rounds are only written in the output when
+// explicit, as the default rounds can be omitted.
+func shaCrypt(password, salt []byte, rounds int, explicit bool) string {
+	return shaCryptDigest(sha512.New, shaCryptPrefix, sha512CryptOrder[:], password, salt, rounds, explicit)
+}
+
+// shaCryptDigest returns the SHA-crypt hash of the password computed with the
+// hash function, and encoded with the prefix and the byte order of the hash.
+func shaCryptDigest(newHash func() hash.Hash, prefix string, order []cryptGroup, password, salt []byte, rounds int, explicit bool) string {
+	salt = salt[:min(len(salt), shaCryptSaltSize)]
+
+	// digest B
+	h := newHash()
+	h.Write(password)
+	h.Write(salt)
+	h.Write(password)
+	b := h.Sum(nil)
+
+	// digest A
+	h.Reset()
+	h.Write(password)
+	h.Write(salt)
+	h.Write(repeatBytes(b, len(password)))
+
+	for n := len(password); n > 0; n >>= 1 {
+		if n&1 != 0 {
+			h.Write(b)
+		} else {
+			h.Write(password)
+		}
+	}
+
+	a := h.Sum(nil)
+
+	// sequence P
+	h.Reset()
+
+	for range password {
+		h.Write(password)
+	}
+
+	pseq := repeatBytes(h.Sum(nil), len(password))
+
+	// sequence S
+	h.Reset()
+
+	for range 16 + int(a[0]) {
+		h.Write(salt)
+	}
+
+	sseq := repeatBytes(h.Sum(nil), len(salt))
+
+	c := a
+
+	for i := range rounds {
+		h.Reset()
+
+		if i&1 != 0 {
+			h.Write(pseq)
+		} else {
+			h.Write(c)
+		}
+
+		if i%3 != 0 {
+			h.Write(sseq)
+		}
+
+		if i%7 != 0 {
+			h.Write(pseq)
+		}
+
+		if i&1 != 0 {
+			h.Write(c)
+		} else {
+			h.Write(pseq)
+		}
+
+		c = h.Sum(c[:0])
+	}
+
+	out := []byte(prefix)
+
+	if explicit {
+		out = append(out, shaCryptRoundsPrefix...)
+		out = strconv.AppendInt(out, int64(rounds), 10)
+		out = append(out, '$')
+	}
+
+	out = append(out, salt...)
+	out = append(out, '$')
+
+	for _, g := range order {
+		var v uint
+
+		for _, i := range g.idx {
+			v <<= 8
+
+			if i >= 0 {
+				v |= uint(c[i])
+			}
+		}
+
+		out = appendCrypt64(out, v, g.n)
+	}
+
+	return string(out)
+}
+
+// repeatBytes returns the first n bytes of the infinite repetition of b.
+func repeatBytes(b []byte, n int) []byte {
+	out := make([]byte, n)
+
+	for i := 0; i < n; i += len(b) {
+		copy(out[i:], b)
+	}
+
+	return out
+}
+
+// appendCrypt64 appends the n least significant 6-bit groups of v, in the
+// crypt(3) base-64 alphabet.
+func appendCrypt64(out []byte, v uint, n int) []byte {
+	for range n {
+		out = append(out, cryptAlphabet[v&63])
+		v >>= 6
+	}
+
+	return out
+}
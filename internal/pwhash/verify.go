@@ -0,0 +1,434 @@
+package pwhash
+
+import (
+	"bytes"
+	"crypto/pbkdf2"
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Legacy algorithms, only supported by Verify.
+const (
+	// Argon2i is the data-independent Argon2i.
+	Argon2i = "argon2i"
+
+	// PBKDF2SHA1 is PBKDF2 with HMAC-SHA1.
+	PBKDF2SHA1 = "pbkdf2-sha1"
+
+	// PBKDF2SHA512 is PBKDF2 with HMAC-SHA512.
+	PBKDF2SHA512 = "pbkdf2-sha512"
+
+	// SHA256Crypt is the SHA-256 based crypt of glibc.
+	SHA256Crypt = "sha256-crypt"
+
+	// SSHA is the salted SHA-1 of the LDAP directories, {SSHA}.
+	SSHA = "ssha"
+
+	// SSHA256 is the salted SHA-256 of the LDAP directories, {SSHA256}.
+	SSHA256 = "ssha256"
+
+	// SSHA512 is the salted SHA-512 of the LDAP directories, {SSHA512}.
+	SSHA512 = "ssha512"
+)
+
+// ErrInvalidHash is returned when a hash string is malformed or of an
+// unsupported algorithm.
+var ErrInvalidHash = errors.New("invalid hash")
+
+// Verification is the outcome of a hash verification.
+type Verification struct {
+	// Match reports whether the password matches the hash.
+	Match bool `json:"match"`
+
+	// Algorithm is the detected hash algorithm.
+	Algorithm string `json:"algorithm"`
+
+	// Params are the work factors of the hash.
+	Params Params `json:"params"`
+
+	// BelowMinimum reports whether any work factor of the hash is below the
+	// configured minimum, or the algorithm has no work factor at all, so the
+	// password should be hashed again.
+	BelowMinimum bool `json:"below_minimum"`
+}
+
+// parsedHash is a hash string split in its parts.
+type parsedHash struct {
+	alg    string
+	params Params
+	salt   []byte
+	key    []byte
+	raw    string
+}
+
+// DefaultMinimums returns the default minimum work factors, below which a
+// stored hash is reported as weak.
+func DefaultMinimums() Params {
+	return Params{
+		Cost:       10,
+		Memory:     19 << 10,
+		Time:       2,
+		Threads:    1,
+		LN:         15,
+		R:          8,
+		P:          1,
+		Iterations: 600_000,
+		Rounds:     shaCryptDefaultRounds,
+	}
+}
+
+// Verify reports whether the password matches the hash string, along with the
+// algorithm and work factors of the hash. The hashes with work factors above
+// the limit, or with a password too long for the algorithm, are rejected
+// without being computed, so a single request cannot exhaust the CPU or memory.
+func Verify(password, hashString string, limit, minimum Params) (Verification, error) {
+	h, err := parseHash(hashString)
+	if err != nil {
+		return Verification{}, err
+	}
+
+	family := algorithmFamily(h.alg)
+	if family != "" {
+		err = h.params.Check(family, limit)
+		if err != nil {
+			return Verification{}, err
+		}
+	}
+
+	err = CheckPassword(h.alg, len(password))
+	if err != nil {
+		return Verification{}, err
+	}
+
+	match, err := h.verify(password)
+	if err != nil {
+		return Verification{}, err
+	}
+
+	return Verification{
+		Match:        match,
+		Algorithm:    h.alg,
+		Params:       h.params,
+		BelowMinimum: h.belowMinimum(minimum),
+	}, nil
+}
+
+// algorithmFamily returns the hashing algorithm whose parameter bounds apply to
+// the algorithm, or an empty string when it has no work factor.
+func algorithmFamily(alg string) string {
+	switch alg {
+	case Argon2i:
+		return Argon2id
+	case PBKDF2SHA1, PBKDF2SHA512:
+		return PBKDF2SHA256
+	case SHA256Crypt:
+		return SHA512Crypt
+	case SSHA, SSHA256, SSHA512:
+		return ""
+	default:
+		return alg
+	}
+}
+
+// belowMinimum reports whether a work factor of the hash is below the minimum.
+// The parallelism parameters don't add to the cost of an attack and are left
+// out.
+func (h *parsedHash) belowMinimum(minimum Params) bool {
+	p := h.params
+
+	switch algorithmFamily(h.alg) {
+	case Bcrypt:
+		return p.Cost < minimum.Cost
+	case Argon2id:
+		return p.Memory < minimum.Memory || p.Time < minimum.Time
+	case Scrypt:
+		return p.LN < minimum.LN || p.R < minimum.R
+	case PBKDF2SHA256:
+		return p.Iterations < minimum.Iterations
+	case SHA512Crypt:
+		return p.Rounds < minimum.Rounds
+	default:
+		return true
+	}
+}
+
+// verify reports whether the password matches the hash.
+func (h *parsedHash) verify(password string) (bool, error) {
+	var (
+		key []byte
+		err error
+	)
+
+	p := h.params
+
+	switch h.alg {
+	case Bcrypt:
+		err = bcrypt.CompareHashAndPassword([]byte(h.raw), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+
+		if err != nil {
+			return false, fmt.Errorf("%w: %w", ErrInvalidHash, err)
+		}
+
+		return true, nil
+	case Argon2id:
+		key = argon2.IDKey([]byte(password), h.salt, uint32(p.Time), uint32(p.Memory), uint8(p.Threads), uint32(len(h.key))) //nolint:gosec
+	case Argon2i:
+		key = argon2.Key([]byte(password), h.salt, uint32(p.Time), uint32(p.Memory), uint8(p.Threads), uint32(len(h.key))) //nolint:gosec
+	case Scrypt:
+		key, err = scrypt.Key([]byte(password), h.salt, 1<<p.LN, p.R, p.P, len(h.key))
+	case PBKDF2SHA1, PBKDF2SHA256, PBKDF2SHA512:
+		key, err = pbkdf2.Key(pbkdf2Hash(h.alg), password, h.salt, p.Iterations, len(h.key))
+	case SHA256Crypt, SHA512Crypt:
+		key = []byte(h.shaCrypt(password))
+		key = key[bytes.LastIndexByte(key, '$')+1:]
+	default:
+		key = saltedDigest(h.alg, password, h.salt)
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrInvalidHash, err)
+	}
+
+	return subtle.ConstantTimeCompare(key, h.key) == 1, nil
+}
+
+// shaCrypt returns the SHA-crypt hash of the password with the salt and rounds
+// of the hash.
+func (h *parsedHash) shaCrypt(password string) string {
+	if h.alg == SHA256Crypt {
+		return shaCryptDigest(sha256.New, sha256CryptPrefix, sha256CryptOrder[:], []byte(password), h.salt, h.params.Rounds, false)
+	}
+
+	return shaCrypt([]byte(password), h.salt, h.params.Rounds, false)
+}
+
+// pbkdf2Hash returns the HMAC hash function of the PBKDF2 algorithm.
+func pbkdf2Hash(alg string) func() hash.Hash {
+	switch alg {
+	case PBKDF2SHA1:
+		return sha1.New
+	case PBKDF2SHA512:
+		return sha512.New
+	default:
+		return sha256.New
+	}
+}
+
+// saltedDigest returns the digest of the password followed by the salt, as in
+// the LDAP salted hashes.
+func saltedDigest(alg, password string, salt []byte) []byte {
+	var h hash.Hash
+
+	switch alg {
+	case SSHA256:
+		h = sha256.New()
+	case SSHA512:
+		h = sha512.New()
+	default:
+		h = sha1.New() //nolint:gosec
+	}
+
+	h.Write([]byte(password))
+	h.Write(salt)
+
+	return h.Sum(nil)
+}
+
+// parseHash splits the hash string in its algorithm, work factors, salt and
+// key.
+func parseHash(s string) (*parsedHash, error) {
+	switch {
+	case strings.HasPrefix(s, "{"):
+		return parseLDAP(s)
+	case strings.HasPrefix(s, "$2a$"), strings.HasPrefix(s, "$2b$"), strings.HasPrefix(s, "$2y$"):
+		return parseBcrypt(s)
+	case strings.HasPrefix(s, shaCryptPrefix), strings.HasPrefix(s, sha256CryptPrefix):
+		return parseSHACrypt(s)
+	case strings.HasPrefix(s, "$"):
+		return parsePHC(s)
+	default:
+		return nil, fmt.Errorf("%w: unknown hash format", ErrInvalidHash)
+	}
+}
+
+// parseBcrypt returns the cost of a bcrypt hash, which is verified as a whole.
+func parseBcrypt(s string) (*parsedHash, error) {
+	cost, err := bcrypt.Cost([]byte(s))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidHash, err)
+	}
+
+	return &parsedHash{alg: Bcrypt, params: Params{Cost: cost}, raw: s}, nil
+}
+
+// parseSHACrypt returns the rounds and salt of a SHA-crypt hash.
+func parseSHACrypt(s string) (*parsedHash, error) {
+	h := &parsedHash{alg: SHA512Crypt, params: Params{Rounds: shaCryptDefaultRounds}, raw: s}
+
+	if strings.HasPrefix(s, sha256CryptPrefix) {
+		h.alg = SHA256Crypt
+	}
+
+	parts := strings.Split(s[len(shaCryptPrefix):], "$")
+
+	if len(parts) == 3 && strings.HasPrefix(parts[0], shaCryptRoundsPrefix) {
+		rounds, err := strconv.Atoi(parts[0][len(shaCryptRoundsPrefix):])
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid %s rounds", ErrInvalidHash, h.alg)
+		}
+
+		// out of range rounds are clamped, as by glibc
+		h.params.Rounds = min(max(rounds, minSHACryptRounds), maxSHACryptRounds)
+		parts = parts[1:]
+	}
+
+	if len(parts) != 2 || len(parts[0]) > shaCryptSaltSize || parts[1] == "" {
+		return nil, fmt.Errorf("%w: malformed %s hash", ErrInvalidHash, h.alg)
+	}
+
+	h.salt = []byte(parts[0])
+	h.key = []byte(parts[1])
+
+	return h, nil
+}
+
+// parsePHC returns the parts of a hash in the PHC string format. The PBKDF2
+// hashes are also accepted in the passlib format, with the bare iterations and
+// the salt and key in the adapted base64 alphabet.
+func parsePHC(s string) (*parsedHash, error) {
+	parts := strings.Split(s[1:], "$")
+
+	// the argon2 version is a parameter set of its own, and only the current
+	// one is supported
+	if strings.HasPrefix(parts[0], "argon2") {
+		if len(parts) != 5 || parts[1] != "v="+strconv.Itoa(argon2.Version) {
+			return nil, fmt.Errorf("%w: unsupported argon2 version", ErrInvalidHash)
+		}
+
+		parts = append(parts[:1], parts[2:]...)
+	}
+
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("%w: malformed PHC string", ErrInvalidHash)
+	}
+
+	h := &parsedHash{alg: parts[0], raw: s}
+
+	if h.alg == "pbkdf2" {
+		h.alg = PBKDF2SHA1
+	}
+
+	enc := base64.RawStdEncoding
+
+	switch h.alg {
+	case Argon2id, Argon2i, Scrypt:
+	case PBKDF2SHA1, PBKDF2SHA256, PBKDF2SHA512:
+		if !strings.Contains(parts[1], "=") {
+			// passlib format
+			parts[1] = "i=" + parts[1]
+			parts[2] = strings.ReplaceAll(parts[2], ".", "+")
+			parts[3] = strings.ReplaceAll(parts[3], ".", "+")
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidHash, h.alg)
+	}
+
+	err := h.parseParams(parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	h.salt, err = enc.DecodeString(parts[2])
+	if err != nil || len(h.salt) == 0 {
+		return nil, fmt.Errorf("%w: invalid salt encoding", ErrInvalidHash)
+	}
+
+	h.key, err = enc.DecodeString(parts[3])
+	if err != nil || len(h.key) == 0 {
+		return nil, fmt.Errorf("%w: invalid hash encoding", ErrInvalidHash)
+	}
+
+	return h, nil
+}
+
+// parseParams parses the comma-separated name=value parameters of a PHC string.
+func (h *parsedHash) parseParams(s string) error {
+	var fields map[string]*int
+
+	switch h.alg {
+	case Argon2id, Argon2i:
+		fields = map[string]*int{"m": &h.params.Memory, "t": &h.params.Time, "p": &h.params.Threads}
+	case Scrypt:
+		fields = map[string]*int{"ln": &h.params.LN, "r": &h.params.R, "p": &h.params.P}
+	default:
+		// the key length is given by the encoded key
+		fields = map[string]*int{"i": &h.params.Iterations, "l": new(int)}
+	}
+
+	for kv := range strings.SplitSeq(s, ",") {
+		name, value, _ := strings.Cut(kv, "=")
+
+		field, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("%w: unknown %s parameter %q", ErrInvalidHash, h.alg, name)
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("%w: invalid %s parameter %q", ErrInvalidHash, h.alg, name)
+		}
+
+		*field = n
+	}
+
+	return nil
+}
+
+// parseLDAP returns the digest and salt of an LDAP salted hash, the base64 of
+// the digest followed by the salt.
+func parseLDAP(s string) (*parsedHash, error) {
+	scheme, value, ok := strings.Cut(s[1:], "}")
+	if !ok {
+		return nil, fmt.Errorf("%w: malformed LDAP hash", ErrInvalidHash)
+	}
+
+	h := &parsedHash{alg: strings.ToLower(scheme), raw: s}
+
+	var size int
+
+	switch h.alg {
+	case SSHA:
+		size = sha1.Size
+	case SSHA256:
+		size = sha256.Size
+	case SSHA512:
+		size = sha512.Size
+	default:
+		return nil, fmt.Errorf("%w: unsupported LDAP scheme {%s}", ErrInvalidHash, scheme)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(raw) <= size {
+		return nil, fmt.Errorf("%w: invalid {%s} encoding", ErrInvalidHash, scheme)
+	}
+
+	h.key = bytes.Clone(raw[:size])
+	h.salt = raw[size:]
+
+	return h, nil
+}
@@ -0,0 +1,221 @@
+package pwhash
+
+import (
+	"crypto/sha1" //nolint:gosec
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ssha returns the {SSHA} LDAP hash of the password with the salt.
+func ssha(password, salt string) string {
+	sum := sha1.Sum([]byte(password + salt)) //nolint:gosec
+
+	return "{SSHA}" + base64.StdEncoding.EncodeToString(append(sum[:], salt...))
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	minimum := Params{Cost: 5, Memory: 64, Time: 1, LN: 4, R: 8, Iterations: 1000, Rounds: 5000}
+
+	tests := []struct {
+		name      string
+		password  string
+		hash      string
+		wantAlg   string
+		wantMatch bool
+		wantBelow bool
+		want      Params
+	}{
+		{
+			name:      "bcrypt",
+			password:  "U*U",
+			hash:      "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW",
+			wantAlg:   Bcrypt,
+			wantMatch: true,
+			want:      Params{Cost: 5},
+		},
+		{
+			name:     "bcrypt mismatch",
+			password: "U*V",
+			hash:     "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW",
+			wantAlg:  Bcrypt,
+			want:     Params{Cost: 5},
+		},
+		{
+			name:      "sha512-crypt default rounds",
+			password:  "Hello world!",
+			hash:      "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1",
+			wantAlg:   SHA512Crypt,
+			wantMatch: true,
+			want:      Params{Rounds: 5000},
+		},
+		{
+			name:      "sha256-crypt default rounds",
+			password:  "Hello world!",
+			hash:      "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5",
+			wantAlg:   SHA256Crypt,
+			wantMatch: true,
+			want:      Params{Rounds: 5000},
+		},
+		{
+			name:      "sha256-crypt explicit rounds",
+			password:  "Hello world!",
+			hash:      "$5$rounds=10000$saltstringsaltst$3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA",
+			wantAlg:   SHA256Crypt,
+			wantMatch: true,
+			want:      Params{Rounds: 10000},
+		},
+		{
+			name:      "sha512-crypt below the minimum rounds",
+			password:  "Hello world!",
+			hash:      "$6$rounds=1000$saltstring$" + strings.Repeat("a", 86),
+			wantAlg:   SHA512Crypt,
+			wantBelow: true,
+			want:      Params{Rounds: 1000},
+		},
+		{
+			name:      "ssha",
+			password:  "secret",
+			hash:      ssha("secret", "pepper"),
+			wantAlg:   SSHA,
+			wantMatch: true,
+			wantBelow: true,
+		},
+		{
+			name:      "ssha mismatch",
+			password:  "Secret",
+			hash:      ssha("secret", "pepper"),
+			wantAlg:   SSHA,
+			wantBelow: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			v, err := Verify(tt.password, tt.hash, DefaultLimits(), minimum)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantAlg, v.Algorithm)
+			require.Equal(t, tt.wantMatch, v.Match)
+			require.Equal(t, tt.wantBelow, v.BelowMinimum)
+			require.Equal(t, tt.want, v.Params)
+		})
+	}
+}
+
+func TestVerifyHash(t *testing.T) {
+	t.Parallel()
+
+	p := Params{Cost: 4, Memory: 64, Time: 1, Threads: 2, LN: 4, R: 8, P: 1, Iterations: 1000, Rounds: 1000}
+	minimum := DefaultMinimums()
+
+	for _, alg := range Algorithms() {
+		t.Run(alg, func(t *testing.T) {
+			t.Parallel()
+
+			h, err := Hash(alg, p, "correct horse")
+			require.NoError(t, err)
+
+			v, err := Verify("correct horse", h, DefaultLimits(), minimum)
+			require.NoError(t, err)
+			require.True(t, v.Match)
+			require.Equal(t, alg, v.Algorithm)
+			require.True(t, v.BelowMinimum)
+
+			v, err = Verify("correct horse!", h, DefaultLimits(), minimum)
+			require.NoError(t, err)
+			require.False(t, v.Match)
+
+			v, err = Verify("correct horse", h, DefaultLimits(), Params{})
+			require.NoError(t, err)
+			require.False(t, v.BelowMinimum)
+		})
+	}
+}
+
+func TestVerifyPBKDF2Formats(t *testing.T) {
+	t.Parallel()
+
+	h, err := Hash(PBKDF2SHA256, Params{Iterations: 1000}, "secret")
+	require.NoError(t, err)
+
+	parts := strings.Split(h, "$")
+	salt := strings.ReplaceAll(parts[3], "+", ".")
+	key := strings.ReplaceAll(parts[4], "+", ".")
+
+	// the same hash in the passlib format
+	v, err := Verify("secret", "$pbkdf2-sha256$1000$"+salt+"$"+key, DefaultLimits(), Params{})
+	require.NoError(t, err)
+	require.True(t, v.Match)
+	require.Equal(t, 1000, v.Params.Iterations)
+
+	for _, alg := range []string{"pbkdf2", "pbkdf2-sha512"} {
+		v, err = Verify("secret", "$"+alg+"$i=1000$"+parts[3]+"$"+parts[4], DefaultLimits(), Params{})
+		require.NoError(t, err)
+		require.False(t, v.Match, "the digests differ")
+	}
+}
+
+func TestVerifyErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		hash    string
+		wantErr error
+	}{
+		{name: "empty", hash: "", wantErr: ErrInvalidHash},
+		{name: "plain text", hash: "secret", wantErr: ErrInvalidHash},
+		{name: "unknown PHC algorithm", hash: "$md5$x$c2FsdA$a2V5", wantErr: ErrInvalidHash},
+		{name: "malformed PHC", hash: "$scrypt$ln=4,r=8,p=1$c2FsdA", wantErr: ErrInvalidHash},
+		{name: "unknown PHC parameter", hash: "$scrypt$ln=4,r=8,x=1$c2FsdA$a2V5", wantErr: ErrInvalidHash},
+		{name: "invalid PHC parameter", hash: "$scrypt$ln=four,r=8,p=1$c2FsdA$a2V5", wantErr: ErrInvalidHash},
+		{name: "invalid salt", hash: "$scrypt$ln=4,r=8,p=1$!!$a2V5", wantErr: ErrInvalidHash},
+		{name: "invalid key", hash: "$scrypt$ln=4,r=8,p=1$c2FsdA$!!", wantErr: ErrInvalidHash},
+		{name: "argon2 without version", hash: "$argon2id$m=64,t=1,p=1$c2FsdA$a2V5", wantErr: ErrInvalidHash},
+		{name: "old argon2 version", hash: "$argon2id$v=16$m=64,t=1,p=1$c2FsdA$a2V5", wantErr: ErrInvalidHash},
+		{name: "argon2 memory above the limit", hash: "$argon2id$v=19$m=4194304,t=1,p=1$c2FsdA$a2V5", wantErr: ErrInvalidParams},
+		{name: "scrypt ln above the limit", hash: "$scrypt$ln=30,r=8,p=1$c2FsdA$a2V5", wantErr: ErrInvalidParams},
+		{name: "scrypt without ln", hash: "$scrypt$r=8,p=1$c2FsdA$a2V5", wantErr: ErrInvalidParams},
+		{name: "bcrypt cost above the limit", hash: "$2a$31$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW", wantErr: ErrInvalidParams},
+		{name: "malformed bcrypt", hash: "$2a$05$short", wantErr: ErrInvalidHash},
+		{name: "sha512-crypt rounds above the limit", hash: "$6$rounds=900000000$salt$hash", wantErr: ErrInvalidParams},
+		{name: "invalid sha512-crypt rounds", hash: "$6$rounds=many$salt$hash", wantErr: ErrInvalidHash},
+		{name: "malformed sha512-crypt", hash: "$6$salt", wantErr: ErrInvalidHash},
+		{name: "unknown LDAP scheme", hash: "{MD5}c2VjcmV0", wantErr: ErrInvalidHash},
+		{name: "malformed LDAP", hash: "{SSHA", wantErr: ErrInvalidHash},
+		{name: "LDAP without salt", hash: "{SSHA}" + base64.StdEncoding.EncodeToString(make([]byte, 20)), wantErr: ErrInvalidHash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Verify("secret", tt.hash, DefaultLimits(), DefaultMinimums())
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestVerifyPasswordTooLong(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("a", 1024)
+
+	for _, hash := range []string{
+		"$6$rounds=2000000$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1",
+		"$5$rounds=2000000$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZF2oM3.o",
+		"$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW",
+	} {
+		_, err := Verify(long, hash, DefaultLimits(), DefaultMinimums())
+		require.ErrorIs(t, err, ErrPasswordTooLong, hash)
+	}
+
+	_, err := Verify(strings.Repeat("a", 256), "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1", DefaultLimits(), DefaultMinimums())
+	require.NoError(t, err)
+}
@@ -0,0 +1,166 @@
+// Package sshkey generates SSH key pairs: the private key in the OpenSSH
+// format, optionally encrypted with a passphrase (aes256-ctr with a
+// bcrypt-pbkdf key derivation), the authorized_keys line and the SHA256
+// fingerprint of the public key.
+package sshkey
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Key types.
+const (
+	TypeEd25519   = "ed25519"
+	TypeECDSAP256 = "ecdsa-p256"
+	TypeECDSAP384 = "ecdsa-p384"
+	TypeRSA       = "rsa"
+)
+
+// Bounds of the RSA key size in bits.
+const (
+	MinRSABits = 3072
+	MaxRSABits = 4096
+)
+
+// ErrInvalid is wrapped by all the errors reporting invalid key settings.
+var ErrInvalid = errors.New("invalid SSH key settings")
+
+// Generator contains the SSH key pair generator configuration.
+type Generator struct {
+	// Type is the key type: ed25519, ecdsa-p256, ecdsa-p384 or rsa.
+	Type string `json:"type" validate:"required,oneof=ed25519 ecdsa-p256 ecdsa-p384 rsa"`
+
+	// Bits is the RSA key size, ignored by the other types.
+	Bits int `json:"bits" validate:"omitempty,oneof=3072 4096"`
+
+	// Comment is stored in the private key and appended to the
+	// authorized_keys line.
+	Comment string `json:"comment" validate:"max=256,printascii"`
+}
+
+// KeyPair contains a generated SSH key pair.
+type KeyPair struct {
+	// Type is the key type.
+	Type string `json:"type"`
+
+	// Bits is the key size.
+	Bits int `json:"bits"`
+
+	// Comment is the key comment.
+	Comment string `json:"comment,omitempty"`
+
+	// PrivateKey is the PEM-encoded private key in the OpenSSH format.
+	PrivateKey string `json:"private_key"`
+
+	// Encrypted reports whether the private key is encrypted with a
+	// passphrase.
+	Encrypted bool `json:"encrypted"`
+
+	// AuthorizedKey is the public key line of the authorized_keys file.
+	AuthorizedKey string `json:"authorized_key"`
+
+	// Fingerprint is the SHA256 fingerprint of the public key, as printed by
+	// ssh-keygen -l.
+	Fingerprint string `json:"fingerprint"`
+}
+
+// New instantiate a new SSH key pair generator object.
+func New(keyType string, bits int, comment string) *Generator {
+	return &Generator{
+		Type:    keyType,
+		Bits:    bits,
+		Comment: comment,
+	}
+}
+
+// Generate returns a new key pair. The private key is encrypted when the
+// passphrase is not empty. The keys are drawn from the Go cryptographic random
+// source, as the standard library ignores custom readers for key generation.
+func (g *Generator) Generate(passphrase []byte) (*KeyPair, error) {
+	priv, pub, bits, err := g.newKey()
+	if err != nil {
+		return nil, err
+	}
+
+	var block *pem.Block
+
+	if len(passphrase) > 0 {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(priv, g.Comment, passphrase)
+	} else {
+		block, err = ssh.MarshalPrivateKey(priv, g.Comment)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding the private key: %w", err)
+	}
+
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding the public key: %w", err)
+	}
+
+	authorized := strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(sshPub)), "\n")
+	if g.Comment != "" {
+		authorized += " " + g.Comment
+	}
+
+	return &KeyPair{
+		Type:          g.Type,
+		Bits:          bits,
+		Comment:       g.Comment,
+		PrivateKey:    string(pem.EncodeToMemory(block)),
+		Encrypted:     len(passphrase) > 0,
+		AuthorizedKey: authorized,
+		Fingerprint:   ssh.FingerprintSHA256(sshPub),
+	}, nil
+}
+
+// newKey returns the private and public keys of the configured type, along
+// with the key size in bits.
+func (g *Generator) newKey() (crypto.PrivateKey, crypto.PublicKey, int, error) {
+	switch g.Type {
+	case TypeEd25519:
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("failed generating the ed25519 key: %w", err)
+		}
+
+		return priv, pub, 256, nil
+	case TypeECDSAP256, TypeECDSAP384:
+		curve := elliptic.P256()
+		if g.Type == TypeECDSAP384 {
+			curve = elliptic.P384()
+		}
+
+		priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("failed generating the ecdsa key: %w", err)
+		}
+
+		return priv, &priv.PublicKey, curve.Params().BitSize, nil
+	case TypeRSA:
+		if g.Bits != MinRSABits && g.Bits != MaxRSABits {
+			return nil, nil, 0, fmt.Errorf("%w: the RSA key size must be %d or %d bits", ErrInvalid, MinRSABits, MaxRSABits)
+		}
+
+		priv, err := rsa.GenerateKey(rand.Reader, g.Bits)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("failed generating the rsa key: %w", err)
+		}
+
+		return priv, &priv.PublicKey, g.Bits, nil
+	default:
+		return nil, nil, 0, fmt.Errorf("%w: unknown key type %q", ErrInvalid, g.Type)
+	}
+}
@@ -0,0 +1,247 @@
+package strength
+
+import (
+	_ "embed" // embeds the dictionaries
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Names of the dictionaries.
+const (
+	DictPasswords   = "passwords"
+	DictEnglish     = "english"
+	DictFemaleNames = "female_names"
+	DictMaleNames   = "male_names"
+	DictSurnames    = "surnames"
+	DictUserInputs  = "user_inputs"
+)
+
+// The dictionaries are the frequency lists of zxcvbn
+// (https://github.com/dropbox/zxcvbn), Copyright (c) 2012-2016 Dan Wheeler and
+// Dropbox, Inc., MIT license, see dict/LICENSE. There is one lowercase word
+// per line sorted by decreasing frequency. Each word only appears in the list
+// where it ranks best.
+var (
+	//go:embed dict/passwords.txt
+	passwordsList string //nolint:gochecknoglobals
+
+	//go:embed dict/english.txt
+	englishList string //nolint:gochecknoglobals
+
+	//go:embed dict/female_names.txt
+	femaleNamesList string //nolint:gochecknoglobals
+
+	//go:embed dict/male_names.txt
+	maleNamesList string //nolint:gochecknoglobals
+
+	//go:embed dict/surnames.txt
+	surnamesList string //nolint:gochecknoglobals
+
+	// dictionaries are the embedded dictionaries.
+	dictionaries = []*dictionary{ //nolint:gochecknoglobals
+		newDictionary(DictPasswords, strings.Fields(passwordsList)),
+		newDictionary(DictEnglish, strings.Fields(englishList)),
+		newDictionary(DictFemaleNames, strings.Fields(femaleNamesList)),
+		newDictionary(DictMaleNames, strings.Fields(maleNamesList)),
+		newDictionary(DictSurnames, strings.Fields(surnamesList)),
+	}
+)
+
+// dictionary maps the words of a frequency list to their rank.
+type dictionary struct {
+	name  string
+	ranks map[string]int
+
+	// maxLen is the number of characters of the longest word.
+	maxLen int
+}
+
+// l33tTable maps the letters to the characters commonly substituting them.
+var l33tTable = map[rune][]rune{ //nolint:gochecknoglobals
+	'a': {'4', '@'},
+	'b': {'8'},
+	'c': {'(', '{', '[', '<'},
+	'e': {'3'},
+	'g': {'6', '9'},
+	'i': {'1', '!', '|'},
+	'l': {'1', '|', '7'},
+	'o': {'0'},
+	's': {'$', '5'},
+	't': {'+', '7'},
+	'x': {'%'},
+	'z': {'2'},
+}
+
+// newDictionary returns the dictionary of the lowercase words sorted by
+// decreasing frequency, ranked from 1. Only the first occurrence of each word
+// is ranked.
+func newDictionary(name string, words []string) *dictionary {
+	d := &dictionary{name: name, ranks: make(map[string]int, len(words))}
+
+	for _, w := range words {
+		if _, ok := d.ranks[w]; ok {
+			continue
+		}
+
+		d.ranks[w] = len(d.ranks) + 1
+		d.maxLen = max(d.maxLen, utf8.RuneCountInString(w))
+	}
+
+	return d
+}
+
+// rankedDictionaries returns the embedded dictionaries along with the one of
+// the user inputs.
+func rankedDictionaries(userInputs []string) []*dictionary {
+	var words []string
+
+	for _, in := range userInputs {
+		if w := strings.ToLower(strings.TrimSpace(in)); w != "" {
+			words = append(words, w)
+		}
+	}
+
+	return append(slices.Clone(dictionaries), newDictionary(DictUserInputs, words))
+}
+
+// dictionaryMatch returns the substrings of the password found in the
+// dictionaries, in any letter case.
+func dictionaryMatch(runes []rune, dicts []*dictionary) []*Match {
+	var matches []*Match
+
+	lower := toLower(runes)
+
+	for _, d := range dicts {
+		for i := range lower {
+			for j := i; j < len(lower) && j-i < d.maxLen; j++ {
+				word := string(lower[i : j+1])
+
+				rank, ok := d.ranks[word]
+				if !ok {
+					continue
+				}
+
+				matches = append(matches, &Match{
+					Pattern:     PatternDictionary,
+					I:           i,
+					J:           j,
+					Token:       string(runes[i : j+1]),
+					Dictionary:  d.name,
+					MatchedWord: word,
+					Rank:        rank,
+				})
+			}
+		}
+	}
+
+	return matches
+}
+
+// reverseDictionaryMatch returns the substrings of the password found
+// backwards in the dictionaries.
+func reverseDictionaryMatch(runes []rune, dicts []*dictionary) []*Match {
+	reversed := slices.Clone(runes)
+	slices.Reverse(reversed)
+
+	n := len(runes)
+	matches := dictionaryMatch(reversed, dicts)
+
+	for _, m := range matches {
+		token := []rune(m.Token)
+		slices.Reverse(token)
+
+		m.Token = string(token)
+		m.Reversed = true
+		m.I, m.J = n-1-m.J, n-1-m.I
+	}
+
+	return matches
+}
+
+// l33tMatch returns the substrings of the password found in the dictionaries
+// once the l33t characters are replaced by the letters they stand for.
+func l33tMatch(runes []rune, dicts []*dictionary) []*Match {
+	var matches []*Match
+
+	for _, sub := range l33tSubs(runes) {
+		subbed := make([]rune, len(runes))
+
+		for i, r := range runes {
+			subbed[i] = r
+
+			if c, ok := sub[r]; ok {
+				subbed[i] = c
+			}
+		}
+
+		for _, m := range dictionaryMatch(subbed, dicts) {
+			token := runes[m.I : m.J+1]
+
+			// only keep the matches containing an actual substitution
+			if len(token) < 2 || string(toLower(token)) == m.MatchedWord {
+				continue
+			}
+
+			m.Token = string(token)
+			m.L33t = true
+			m.Sub = make(map[string]string)
+
+			for l33t, letter := range sub {
+				if slices.Contains(token, l33t) {
+					m.Sub[string(l33t)] = string(letter)
+				}
+			}
+
+			if !containsMatch(matches, m) {
+				matches = append(matches, m)
+			}
+		}
+	}
+
+	return matches
+}
+
+// l33tSubs returns every way to map the l33t characters found in the password
+// to a letter, as a character can stand for several letters.
+func l33tSubs(runes []rune) []map[rune]rune {
+	candidates := make(map[rune][]rune)
+
+	for _, letter := range slices.Sorted(maps.Keys(l33tTable)) {
+		for _, c := range l33tTable[letter] {
+			if slices.Contains(runes, c) {
+				candidates[c] = append(candidates[c], letter)
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	subs := []map[rune]rune{{}}
+
+	for _, c := range slices.Sorted(maps.Keys(candidates)) {
+		var next []map[rune]rune
+
+		for _, sub := range subs {
+			for _, letter := range candidates[c] {
+				s := maps.Clone(sub)
+				s[c] = letter
+				next = append(next, s)
+			}
+		}
+
+		subs = next
+	}
+
+	return subs
+}
+
+// containsMatch reports whether an equivalent l33t match is already listed.
+func containsMatch(matches []*Match, m *Match) bool {
+	return slices.ContainsFunc(matches, func(o *Match) bool {
+		return o.I == m.I && o.J == m.J && o.Dictionary == m.Dictionary && o.MatchedWord == m.MatchedWord
+	})
+}
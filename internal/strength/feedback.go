@@ -0,0 +1,137 @@
+package strength
+
+import "strings"
+
+// Feedback explains the weakest pattern of a password and how to improve it.
+type Feedback struct {
+	// Warning describes the weakest pattern, when relevant.
+	Warning string `json:"warning"`
+
+	// Suggestions are the advices to pick a stronger password.
+	Suggestions []string `json:"suggestions"`
+}
+
+// minAcceptableScore is the score above which no feedback is given.
+const minAcceptableScore = 2
+
+// Feedback texts.
+const (
+	suggestWords       = "Use a few words, avoid common phrases."
+	suggestNoSymbols   = "No need for symbols, digits, or uppercase letters."
+	suggestAnotherWord = "Add another word or two. Uncommon words are better."
+)
+
+// feedback returns the feedback of the password with the given score and
+// sequence of matches, based on its longest match.
+func feedback(score int, seq []*Match) Feedback {
+	if len(seq) == 0 {
+		return Feedback{Suggestions: []string{suggestWords, suggestNoSymbols}}
+	}
+
+	if score > minAcceptableScore {
+		return Feedback{Suggestions: []string{}}
+	}
+
+	longest := seq[0]
+
+	for _, m := range seq[1:] {
+		if m.length() > longest.length() {
+			longest = m
+		}
+	}
+
+	fb := matchFeedback(longest, len(seq) == 1)
+	fb.Suggestions = append([]string{suggestAnotherWord}, fb.Suggestions...)
+
+	return fb
+}
+
+// matchFeedback returns the warning and suggestions of the pattern of the
+// match.
+func matchFeedback(m *Match, sole bool) Feedback {
+	switch m.Pattern {
+	case PatternDictionary:
+		return dictionaryFeedback(m, sole)
+	case PatternSpatial:
+		warning := "Short keyboard patterns are easy to guess."
+		if m.Turns == 1 {
+			warning = "Straight rows of keys are easy to guess."
+		}
+
+		return Feedback{Warning: warning, Suggestions: []string{"Use a longer keyboard pattern with more turns."}}
+	case PatternRepeat:
+		warning := `Repeats like "abcabcabc" are only slightly harder to guess than "abc".`
+		if len([]rune(m.BaseToken)) == 1 {
+			warning = `Repeats like "aaa" are easy to guess.`
+		}
+
+		return Feedback{Warning: warning, Suggestions: []string{"Avoid repeated words and characters."}}
+	case PatternSequence:
+		return Feedback{Warning: "Sequences like abc or 6543 are easy to guess.", Suggestions: []string{"Avoid sequences."}}
+	case PatternRegex:
+		return Feedback{Warning: "Recent years are easy to guess.", Suggestions: []string{"Avoid recent years.", "Avoid years that are associated with you."}}
+	case PatternDate:
+		return Feedback{Warning: "Dates are often easy to guess.", Suggestions: []string{"Avoid dates and years that are associated with you."}}
+	default:
+		return Feedback{}
+	}
+}
+
+// dictionaryFeedback returns the feedback of a dictionary word.
+func dictionaryFeedback(m *Match, sole bool) Feedback {
+	fb := Feedback{Warning: dictionaryWarning(m, sole)}
+
+	switch {
+	case startUpper.MatchString(m.Token):
+		fb.Suggestions = append(fb.Suggestions, "Capitalization doesn't help very much.")
+	case allUpper.MatchString(m.Token) && strings.ToLower(m.Token) != m.Token:
+		fb.Suggestions = append(fb.Suggestions, "All-uppercase is almost as easy to guess as all-lowercase.")
+	}
+
+	if m.Reversed && m.length() >= 4 {
+		fb.Suggestions = append(fb.Suggestions, "Reversed words aren't much harder to guess.")
+	}
+
+	if m.L33t {
+		fb.Suggestions = append(fb.Suggestions, "Predictable substitutions like '@' instead of 'a' don't help very much.")
+	}
+
+	return fb
+}
+
+// dictionaryWarning returns the warning of a dictionary word.
+func dictionaryWarning(m *Match, sole bool) string {
+	const (
+		top10      = 10
+		top100     = 100
+		similarLog = 4
+	)
+
+	switch m.Dictionary {
+	case DictPasswords:
+		switch {
+		case sole && !m.L33t && !m.Reversed && m.Rank <= top10:
+			return "This is a top-10 common password."
+		case sole && !m.L33t && !m.Reversed && m.Rank <= top100:
+			return "This is a top-100 common password."
+		case sole && !m.L33t && !m.Reversed:
+			return "This is a very common password."
+		case m.GuessesLog10 <= similarLog:
+			return "This is similar to a commonly used password."
+		}
+	case DictEnglish:
+		if sole {
+			return "A word by itself is easy to guess."
+		}
+	case DictFemaleNames, DictMaleNames, DictSurnames:
+		if sole {
+			return "Names and surnames by themselves are easy to guess."
+		}
+
+		return "Common names and surnames are easy to guess."
+	case DictUserInputs:
+		return "Passwords based on personal information are easy to guess."
+	}
+
+	return ""
+}
@@ -0,0 +1,407 @@
+package strength
+
+import (
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Guess estimation constants.
+const (
+	// bruteforceCardinality is the number of guesses of each character of
+	// the segments not matching any pattern.
+	bruteforceCardinality = 10
+
+	// minGuessesBeforeGrowingSequence penalizes the sequences made of many
+	// short matches, as an attacker tries the shorter sequences first.
+	minGuessesBeforeGrowingSequence = 10000
+
+	// minSubmatchGuessesSingleChar and minSubmatchGuessesMultiChar are the
+	// minimum guesses of a match covering only part of the password.
+	minSubmatchGuessesSingleChar = 10
+	minSubmatchGuessesMultiChar  = 50
+
+	// minYearSpace is the minimum number of years an attacker tries around
+	// the reference year.
+	minYearSpace = 20
+
+	// daysPerYear is the number of dates of a year.
+	daysPerYear = 365
+
+	// dateSeparatorGuesses is the number of date separators an attacker tries.
+	dateSeparatorGuesses = 4
+)
+
+// referenceYear is the year the dates and years are compared against.
+var referenceYear = time.Now().Year() //nolint:gochecknoglobals
+
+// Case patterns of the dictionary words.
+var (
+	startUpper = regexp.MustCompile(`^[A-Z][^A-Z]+$`) //nolint:gochecknoglobals
+	endUpper   = regexp.MustCompile(`^[^A-Z]+[A-Z]$`) //nolint:gochecknoglobals
+	allUpper   = regexp.MustCompile(`^[^a-z]+$`)      //nolint:gochecknoglobals
+	allLower   = regexp.MustCompile(`^[^A-Z]+$`)      //nolint:gochecknoglobals
+)
+
+// mostGuessableSequence returns the number of guesses of the password and the
+// non-overlapping sequence of matches and brute-force segments covering it
+// that requires the fewest guesses. A sequence of l matches needs l! times the
+// product of their guesses, as the attacker doesn't know their order, plus the
+// guesses of all the shorter sequences unless excludeAdditive is set.
+func mostGuessableSequence(runes []rune, matches []*Match, excludeAdditive bool) (float64, []*Match) {
+	n := len(runes)
+	if n == 0 {
+		return 1, []*Match{}
+	}
+
+	opt := newOptimal(runes, excludeAdditive)
+	byEnd := make([][]*Match, n)
+
+	for _, m := range matches {
+		byEnd[m.J] = append(byEnd[m.J], m)
+	}
+
+	for k := range n {
+		sortMatches(byEnd[k])
+
+		for _, m := range byEnd[k] {
+			if m.I == 0 {
+				opt.update(m, 1)
+				continue
+			}
+
+			for _, l := range slices.Sorted(maps.Keys(opt.m[m.I-1])) {
+				opt.update(m, l+1)
+			}
+		}
+
+		opt.bruteforceUpdate(k)
+	}
+
+	return opt.unwind(n)
+}
+
+// optimal holds, for each end position k and sequence length l, the best
+// sequence of l matches ending at k: its last match, the product of the
+// guesses of its matches and its overall guesses.
+type optimal struct {
+	runes           []rune
+	excludeAdditive bool
+	m               []map[int]*Match
+	pi              []map[int]float64
+	g               []map[int]float64
+}
+
+// newOptimal returns the empty optimal sequences of the password.
+func newOptimal(runes []rune, excludeAdditive bool) *optimal {
+	n := len(runes)
+	opt := &optimal{
+		runes:           runes,
+		excludeAdditive: excludeAdditive,
+		m:               make([]map[int]*Match, n),
+		pi:              make([]map[int]float64, n),
+		g:               make([]map[int]float64, n),
+	}
+
+	for k := range n {
+		opt.m[k] = make(map[int]*Match)
+		opt.pi[k] = make(map[int]float64)
+		opt.g[k] = make(map[int]float64)
+	}
+
+	return opt
+}
+
+// update records the sequence of length l ending with the match, unless a
+// sequence ending at the same position with at most l matches needs fewer
+// guesses.
+func (o *optimal) update(m *Match, l int) {
+	k := m.J
+	pi := estimateGuesses(m, len(o.runes))
+
+	if l > 1 {
+		pi *= o.pi[m.I-1][l-1]
+	}
+
+	g := factorial(l) * pi
+
+	if !o.excludeAdditive {
+		g += pow(minGuessesBeforeGrowingSequence, l-1)
+	}
+
+	for cl, cg := range o.g[k] {
+		if cl <= l && cg <= g {
+			return
+		}
+	}
+
+	o.g[k][l] = g
+	o.m[k][l] = m
+	o.pi[k][l] = pi
+}
+
+// bruteforceUpdate records the sequences ending with a brute-force segment at
+// position k, which never follows another brute-force segment.
+func (o *optimal) bruteforceUpdate(k int) {
+	o.update(o.bruteforceMatch(0, k), 1)
+
+	for i := 1; i <= k; i++ {
+		m := o.bruteforceMatch(i, k)
+
+		for _, l := range slices.Sorted(maps.Keys(o.m[i-1])) {
+			if o.m[i-1][l].Pattern == PatternBruteforce {
+				continue
+			}
+
+			o.update(m, l+1)
+		}
+	}
+}
+
+// bruteforceMatch returns the brute-force segment between i and j.
+func (o *optimal) bruteforceMatch(i, j int) *Match {
+	return &Match{Pattern: PatternBruteforce, I: i, J: j, Token: string(o.runes[i : j+1])}
+}
+
+// unwind returns the guesses and the matches of the best sequence covering the
+// whole password.
+func (o *optimal) unwind(n int) (float64, []*Match) {
+	k := n - 1
+	bestL, bestG := 0, math.Inf(1)
+
+	for l, g := range o.g[k] {
+		if g < bestG || (g == bestG && l < bestL) {
+			bestL, bestG = l, g
+		}
+	}
+
+	seq := make([]*Match, bestL)
+
+	for l := bestL; l > 0; l-- {
+		m := o.m[k][l]
+		seq[l-1] = m
+		k = m.I - 1
+	}
+
+	return bestG, seq
+}
+
+// estimateGuesses returns the guesses of the match, computing them on the
+// first call. A match shorter than the password needs a minimum number of
+// guesses, as it is combined with the other ones.
+func estimateGuesses(m *Match, passwordLength int) float64 {
+	if m.Guesses > 0 {
+		return m.Guesses
+	}
+
+	minGuesses := 1.0
+
+	if m.length() < passwordLength {
+		minGuesses = minSubmatchGuessesMultiChar
+		if m.length() == 1 {
+			minGuesses = minSubmatchGuessesSingleChar
+		}
+	}
+
+	m.Guesses = max(patternGuesses(m), minGuesses)
+	m.GuessesLog10 = math.Log10(m.Guesses)
+
+	return m.Guesses
+}
+
+// patternGuesses returns the guesses of the match for its pattern.
+func patternGuesses(m *Match) float64 {
+	switch m.Pattern {
+	case PatternDictionary:
+		return dictionaryGuesses(m)
+	case PatternSpatial:
+		return spatialGuesses(m)
+	case PatternRepeat:
+		return m.baseGuesses * float64(m.RepeatCount)
+	case PatternSequence:
+		return sequenceGuesses(m)
+	case PatternRegex:
+		return yearGuesses(m.Token)
+	case PatternDate:
+		return dateGuesses(m)
+	default:
+		return bruteforceGuesses(m)
+	}
+}
+
+// bruteforceGuesses returns the guesses of a segment not matching any pattern.
+func bruteforceGuesses(m *Match) float64 {
+	guesses := pow(bruteforceCardinality, m.length())
+
+	minGuesses := float64(minSubmatchGuessesMultiChar + 1)
+	if m.length() == 1 {
+		minGuesses = minSubmatchGuessesSingleChar + 1
+	}
+
+	return max(guesses, minGuesses)
+}
+
+// dictionaryGuesses returns the rank of the word, multiplied by the variations
+// of letter case, l33t substitutions and reversal.
+func dictionaryGuesses(m *Match) float64 {
+	guesses := float64(m.Rank) * uppercaseVariations(m.Token) * l33tVariations(m)
+
+	if m.Reversed {
+		guesses *= 2
+	}
+
+	return guesses
+}
+
+// uppercaseVariations returns the number of case variations of the word an
+// attacker tries before reaching the token: the common capitalizations first,
+// then all the mixes with the same number of uppercase letters.
+func uppercaseVariations(token string) float64 {
+	if allLower.MatchString(token) || strings.ToLower(token) == token {
+		return 1
+	}
+
+	if startUpper.MatchString(token) || endUpper.MatchString(token) || allUpper.MatchString(token) {
+		return 2
+	}
+
+	var upper, lower int
+
+	for _, r := range token {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+
+	var variations float64
+
+	for i := 1; i <= min(upper, lower); i++ {
+		variations += nCk(upper+lower, i)
+	}
+
+	return variations
+}
+
+// l33tVariations returns the number of ways to apply the substitutions of the
+// match to the word.
+func l33tVariations(m *Match) float64 {
+	if !m.L33t {
+		return 1
+	}
+
+	variations := 1.0
+	token := strings.ToLower(m.Token)
+
+	for subbed, unsubbed := range m.Sub {
+		s := strings.Count(token, subbed)
+		u := strings.Count(token, unsubbed)
+
+		if s == 0 || u == 0 {
+			// either all or none of the characters are substituted
+			variations *= 2
+			continue
+		}
+
+		var possibilities float64
+
+		for i := 1; i <= min(u, s); i++ {
+			possibilities += nCk(u+s, i)
+		}
+
+		variations *= possibilities
+	}
+
+	return variations
+}
+
+// sequenceGuesses returns the guesses of a sequence: the number of likely
+// starting characters times its length, doubled when descending.
+func sequenceGuesses(m *Match) float64 {
+	first := []rune(m.Token)[0]
+
+	var base float64
+
+	switch {
+	case strings.ContainsRune("aAzZ019", first):
+		base = 4
+	case isDigit(first):
+		base = 10
+	default:
+		// could also be the ASCII or unicode sequences
+		base = 26
+	}
+
+	if !m.Ascending {
+		base *= 2
+	}
+
+	return base * float64(m.length())
+}
+
+// yearGuesses returns the number of years between the reference year and the
+// given one.
+func yearGuesses(token string) float64 {
+	year, err := strconv.Atoi(token)
+	if err != nil {
+		return minYearSpace
+	}
+
+	return float64(max(abs(year-referenceYear), minYearSpace))
+}
+
+// dateGuesses returns the number of dates an attacker tries, from the
+// reference year backwards or forwards, with any separator.
+func dateGuesses(m *Match) float64 {
+	guesses := float64(max(abs(m.Year-referenceYear), minYearSpace)) * daysPerYear
+
+	if m.Separator != "" {
+		guesses *= dateSeparatorGuesses
+	}
+
+	return guesses
+}
+
+// nCk returns the binomial coefficient of n and k.
+func nCk(n, k int) float64 {
+	if k > n {
+		return 0
+	}
+
+	if k == 0 {
+		return 1
+	}
+
+	r := 1.0
+
+	for d := 1; d <= k; d++ {
+		r *= float64(n)
+		r /= float64(d)
+		n--
+	}
+
+	return r
+}
+
+// factorial returns n!.
+func factorial(n int) float64 {
+	f := 1.0
+
+	for i := 2; i <= n; i++ {
+		f *= float64(i)
+	}
+
+	return f
+}
+
+// pow returns base to the power of exp.
+func pow(base float64, exp int) float64 {
+	return math.Pow(base, float64(exp))
+}
@@ -0,0 +1,123 @@
+package strength
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Names of the patterns.
+const (
+	PatternDictionary = "dictionary"
+	PatternSpatial    = "spatial"
+	PatternRepeat     = "repeat"
+	PatternSequence   = "sequence"
+	PatternRegex      = "regex"
+	PatternDate       = "date"
+	PatternBruteforce = "bruteforce"
+)
+
+// Match is a pattern detected in the password. Only the fields of its pattern
+// are set.
+type Match struct {
+	// Pattern is the name of the pattern.
+	Pattern string `json:"pattern"`
+
+	// I and J are the positions of the first and last characters of the
+	// token, counted in characters from 0.
+	I int `json:"i"`
+	J int `json:"j"`
+
+	// Token is the matched part of the password.
+	Token string `json:"token"`
+
+	// Guesses is the estimated number of guesses needed to find the token.
+	Guesses float64 `json:"guesses"`
+
+	// GuessesLog10 is the base-10 logarithm of the guesses.
+	GuessesLog10 float64 `json:"guesses_log10"`
+
+	// Dictionary is the name of the dictionary containing the word.
+	Dictionary string `json:"dictionary,omitempty"`
+
+	// MatchedWord is the dictionary word, in lowercase.
+	MatchedWord string `json:"matched_word,omitempty"`
+
+	// Rank is the position of the word in the dictionary, starting from 1.
+	Rank int `json:"rank,omitempty"`
+
+	// Reversed reports whether the word is written backwards.
+	Reversed bool `json:"reversed,omitempty"`
+
+	// L33t reports whether the word contains l33t substitutions.
+	L33t bool `json:"l33t,omitempty"`
+
+	// Sub maps the l33t characters of the token to the letters they replace.
+	Sub map[string]string `json:"sub,omitempty"`
+
+	// Graph is the name of the keyboard layout of the spatial pattern.
+	Graph string `json:"graph,omitempty"`
+
+	// Turns is the number of direction changes of the spatial pattern.
+	Turns int `json:"turns,omitempty"`
+
+	// ShiftedCount is the number of shifted keys of the spatial pattern.
+	ShiftedCount int `json:"shifted_count,omitempty"`
+
+	// BaseToken is the repeated part of the repeat pattern.
+	BaseToken string `json:"base_token,omitempty"`
+
+	// RepeatCount is the number of repetitions of the base token.
+	RepeatCount int `json:"repeat_count,omitempty"`
+
+	// SequenceName is the alphabet of the sequence: lower, upper, digits or
+	// unicode.
+	SequenceName string `json:"sequence_name,omitempty"`
+
+	// Ascending reports whether the sequence is ascending.
+	Ascending bool `json:"ascending,omitempty"`
+
+	// RegexName is the name of the regex pattern.
+	RegexName string `json:"regex_name,omitempty"`
+
+	// Separator is the separator of the date parts.
+	Separator string `json:"separator,omitempty"`
+
+	// Year, Month and Day are the parts of the date.
+	Year  int `json:"year,omitempty"`
+	Month int `json:"month,omitempty"`
+	Day   int `json:"day,omitempty"`
+
+	// baseGuesses is the number of guesses of the base token of a repeat.
+	baseGuesses float64
+}
+
+// length returns the number of characters of the token.
+func (m *Match) length() int {
+	return m.J - m.I + 1
+}
+
+// omnimatch returns all the patterns found in the password, sorted by
+// position.
+func omnimatch(runes []rune, dicts []*dictionary) []*Match {
+	var matches []*Match
+
+	matches = append(matches, dictionaryMatch(runes, dicts)...)
+	matches = append(matches, reverseDictionaryMatch(runes, dicts)...)
+	matches = append(matches, l33tMatch(runes, dicts)...)
+	matches = append(matches, spatialMatch(runes)...)
+	matches = append(matches, repeatMatch(runes, dicts)...)
+	matches = append(matches, sequenceMatch(runes)...)
+	matches = append(matches, regexMatch(runes)...)
+	matches = append(matches, dateMatch(runes)...)
+
+	sortMatches(matches)
+
+	return matches
+}
+
+// sortMatches sorts the matches by start and end positions.
+func sortMatches(matches []*Match) {
+	slices.SortStableFunc(matches, func(a, b *Match) int {
+		return cmp.Or(cmp.Compare(a.I, b.I), cmp.Compare(a.J, b.J))
+	})
+}
@@ -0,0 +1,411 @@
+package strength
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Sequence limits.
+const (
+	// maxSequenceDelta is the maximum code point step between the characters
+	// of a sequence, as in aceg or 9630.
+	maxSequenceDelta = 5
+)
+
+// Names of the regex patterns.
+const (
+	RegexRecentYear = "recent_year"
+)
+
+// Date limits.
+const (
+	dateMinYear    = 1000
+	dateMaxYear    = 2050
+	minDateLength  = 4
+	maxDateLength  = 8
+	minSepDateLen  = 6
+	maxSepDateLen  = 10
+	dateSeparators = " /\\_.-"
+)
+
+// recentYear matches the years people often include in their passwords.
+var recentYear = regexp.MustCompile(`19\d\d|20[0-4]\d`) //nolint:gochecknoglobals
+
+// dateSplits lists, for each length of a date without separators, the
+// positions splitting it into three parts.
+var dateSplits = map[int][][2]int{ //nolint:gochecknoglobals
+	4: {{1, 2}, {2, 3}},         // 1191, 9111
+	5: {{1, 3}, {2, 3}},         // 1/11/91, 11/1/91
+	6: {{1, 2}, {2, 4}, {4, 5}}, // 1/1/1991, 11/11/91, 1991/1/1
+	7: {{1, 3}, {2, 3}, {4, 5}, {4, 6}},
+	8: {{2, 4}, {4, 6}}, // 11/11/1991, 1991/11/11
+}
+
+// repeatMatch returns the runs of a repeated substring, as aaa or abcabc. The
+// guesses of the repeated part are estimated as for a whole password.
+func repeatMatch(runes []rune, dicts []*dictionary) []*Match {
+	var matches []*Match
+
+	for i := 0; i < len(runes)-1; {
+		base, count := longestRepeat(runes[i:])
+		if count < 2 {
+			i++
+			continue
+		}
+
+		baseRunes := runes[i : i+base]
+		guesses, _ := mostGuessableSequence(baseRunes, omnimatch(baseRunes, dicts), false)
+
+		matches = append(matches, &Match{
+			Pattern:     PatternRepeat,
+			I:           i,
+			J:           i + base*count - 1,
+			Token:       string(runes[i : i+base*count]),
+			BaseToken:   string(baseRunes),
+			RepeatCount: count,
+			baseGuesses: guesses,
+		})
+
+		i += base * count
+	}
+
+	return matches
+}
+
+// longestRepeat returns the length of the base and the number of its
+// consecutive repetitions at the start of the characters, choosing the longest
+// run and then the shortest base.
+func longestRepeat(runes []rune) (int, int) {
+	bestBase, bestCount := 0, 0
+
+	for base := 1; base <= len(runes)/2; base++ {
+		count := 1
+
+		for end := 2 * base; end <= len(runes) && equalRunes(runes[end-base:end], runes[:base]); end += base {
+			count++
+		}
+
+		if count >= 2 && base*count > bestBase*bestCount {
+			bestBase, bestCount = base, count
+		}
+	}
+
+	return bestBase, bestCount
+}
+
+// equalRunes reports whether the two slices contain the same characters.
+func equalRunes(a, b []rune) bool {
+	return string(a) == string(b)
+}
+
+// sequenceMatch returns the runs of characters with a constant code point
+// step, as abc, 7531 or ZYX.
+func sequenceMatch(runes []rune) []*Match {
+	if len(runes) < 2 {
+		return nil
+	}
+
+	var matches []*Match
+
+	add := func(i, j, delta int) {
+		if (j-i > 1 || abs(delta) == 1) && delta != 0 && abs(delta) <= maxSequenceDelta {
+			matches = append(matches, newSequenceMatch(runes, i, j, delta))
+		}
+	}
+
+	i := 0
+	lastDelta := int(runes[1] - runes[0])
+
+	for k := 2; k < len(runes); k++ {
+		delta := int(runes[k] - runes[k-1])
+		if delta == lastDelta {
+			continue
+		}
+
+		add(i, k-1, lastDelta)
+		i = k - 1
+		lastDelta = delta
+	}
+
+	add(i, len(runes)-1, lastDelta)
+
+	return matches
+}
+
+// newSequenceMatch returns the match of the sequence between i and j.
+func newSequenceMatch(runes []rune, i, j, delta int) *Match {
+	token := runes[i : j+1]
+	name := "unicode"
+
+	switch {
+	case allRunes(token, func(r rune) bool { return r >= 'a' && r <= 'z' }):
+		name = "lower"
+	case allRunes(token, func(r rune) bool { return r >= 'A' && r <= 'Z' }):
+		name = "upper"
+	case allRunes(token, isDigit):
+		name = "digits"
+	}
+
+	return &Match{
+		Pattern:      PatternSequence,
+		I:            i,
+		J:            j,
+		Token:        string(token),
+		SequenceName: name,
+		Ascending:    delta > 0,
+	}
+}
+
+// regexMatch returns the recent years.
+func regexMatch(runes []rune) []*Match {
+	var matches []*Match
+
+	s := string(runes)
+
+	for _, loc := range recentYear.FindAllStringIndex(s, -1) {
+		// the password may contain multi-byte characters before the match
+		i := len([]rune(s[:loc[0]]))
+
+		matches = append(matches, &Match{
+			Pattern:   PatternRegex,
+			I:         i,
+			J:         i + loc[1] - loc[0] - 1,
+			Token:     s[loc[0]:loc[1]],
+			RegexName: RegexRecentYear,
+		})
+	}
+
+	return matches
+}
+
+// dateMatch returns the dates, with or without separators, not contained in a
+// longer date.
+func dateMatch(runes []rune) []*Match {
+	var matches []*Match
+
+	for i := range runes {
+		for j := i + minDateLength - 1; j < len(runes) && j < i+maxSepDateLen; j++ {
+			m := parseDate(runes[i : j+1])
+			if m == nil {
+				continue
+			}
+
+			m.I, m.J = i, j
+			matches = append(matches, m)
+		}
+	}
+
+	var out []*Match
+
+	for _, m := range matches {
+		contained := false
+
+		for _, o := range matches {
+			if o != m && o.I <= m.I && o.J >= m.J && o.length() > m.length() {
+				contained = true
+				break
+			}
+		}
+
+		if !contained {
+			out = append(out, m)
+		}
+	}
+
+	return out
+}
+
+// parseDate returns the date match of the token, or nil.
+func parseDate(token []rune) *Match {
+	n := len(token)
+
+	if n <= maxDateLength && allRunes(token, isDigit) {
+		return parseDateNoSeparator(token)
+	}
+
+	if n < minSepDateLen || n > maxSepDateLen {
+		return nil
+	}
+
+	return parseDateSeparator(token)
+}
+
+// parseDateNoSeparator returns the date of a string of digits, choosing among
+// the possible splits the one whose year is the closest to the reference year.
+func parseDateNoSeparator(token []rune) *Match {
+	var best *Match
+
+	for _, split := range dateSplits[len(token)] {
+		parts := [3]int{
+			atoi(token[:split[0]]),
+			atoi(token[split[0]:split[1]]),
+			atoi(token[split[1]:]),
+		}
+
+		y, m, d, ok := mapIntsToDMY(parts)
+		if !ok {
+			continue
+		}
+
+		if best == nil || abs(y-referenceYear) < abs(best.Year-referenceYear) {
+			best = &Match{Pattern: PatternDate, Token: string(token), Year: y, Month: m, Day: d}
+		}
+	}
+
+	return best
+}
+
+// parseDateSeparator returns the date of three groups of digits separated
+// twice by the same separator, as 3/14/1985 or 1985-03-14.
+func parseDateSeparator(token []rune) *Match {
+	var (
+		groups [3][]rune
+		sep    rune
+		g      int
+	)
+
+	for _, r := range token {
+		switch {
+		case isDigit(r):
+			groups[g] = append(groups[g], r)
+		case g < 2 && len(groups[g]) > 0 && (sep == 0 || sep == r) && strings.ContainsRune(dateSeparators, r):
+			sep = r
+			g++
+		default:
+			return nil
+		}
+	}
+
+	if g != 2 || len(groups[0]) > 4 || len(groups[1]) > 2 || len(groups[2]) == 0 || len(groups[2]) > 4 {
+		return nil
+	}
+
+	y, m, d, ok := mapIntsToDMY([3]int{atoi(groups[0]), atoi(groups[1]), atoi(groups[2])})
+	if !ok {
+		return nil
+	}
+
+	return &Match{Pattern: PatternDate, Token: string(token), Separator: string(sep), Year: y, Month: m, Day: d}
+}
+
+// mapIntsToDMY returns the year, month and day of the three numbers, where the
+// year is either the first or the last one.
+func mapIntsToDMY(parts [3]int) (int, int, int, bool) {
+	if parts[1] > 31 || parts[1] <= 0 {
+		return 0, 0, 0, false
+	}
+
+	var over12, over31, under1 int
+
+	for _, v := range parts {
+		if (v > 99 && v < dateMinYear) || v > dateMaxYear {
+			return 0, 0, 0, false
+		}
+
+		if v > 31 {
+			over31++
+		}
+
+		if v > 12 {
+			over12++
+		}
+
+		if v <= 0 {
+			under1++
+		}
+	}
+
+	if over31 >= 2 || over12 == 3 || under1 >= 2 {
+		return 0, 0, 0, false
+	}
+
+	splits := [2]struct {
+		year int
+		rest [2]int
+	}{
+		{parts[2], [2]int{parts[0], parts[1]}},
+		{parts[0], [2]int{parts[1], parts[2]}},
+	}
+
+	for _, s := range splits {
+		if s.year >= dateMinYear && s.year <= dateMaxYear {
+			m, d, ok := mapIntsToDM(s.rest)
+			return s.year, m, d, ok
+		}
+	}
+
+	for _, s := range splits {
+		m, d, ok := mapIntsToDM(s.rest)
+		if ok {
+			return twoToFourDigitYear(s.year), m, d, true
+		}
+	}
+
+	return 0, 0, 0, false
+}
+
+// mapIntsToDM returns the month and day of the two numbers, in either order.
+func mapIntsToDM(parts [2]int) (int, int, bool) {
+	for _, p := range [2][2]int{parts, {parts[1], parts[0]}} {
+		d, m := p[0], p[1]
+
+		if d >= 1 && d <= 31 && m >= 1 && m <= 12 {
+			return m, d, true
+		}
+	}
+
+	return 0, 0, false
+}
+
+// twoToFourDigitYear expands a two-digit year to the closest century.
+func twoToFourDigitYear(year int) int {
+	const (
+		pivot   = 50
+		century = 100
+	)
+
+	switch {
+	case year >= century:
+		return year
+	case year > pivot:
+		return year + 1900
+	default:
+		return year + 2000
+	}
+}
+
+// allRunes reports whether all the characters satisfy the predicate.
+func allRunes(runes []rune, fn func(rune) bool) bool {
+	for _, r := range runes {
+		if !fn(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isDigit reports whether the character is an ASCII digit.
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// atoi returns the value of a string of ASCII digits.
+func atoi(runes []rune) int {
+	n, err := strconv.Atoi(string(runes))
+	if err != nil {
+		return -1
+	}
+
+	return n
+}
+
+// abs returns the absolute value.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
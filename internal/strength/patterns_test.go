@@ -0,0 +1,96 @@
+package strength
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDateMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		token string
+		want  [3]int // year, month, day
+	}{
+		{"1/1/91", [3]int{1991, 1, 1}},
+		{"13.12.2001", [3]int{2001, 12, 13}},
+		{"2001_12_13", [3]int{2001, 12, 13}},
+		{"111991", [3]int{1991, 1, 11}},
+		{"19911231", [3]int{1991, 12, 31}},
+		{"1191", [3]int{2001, 9, 11}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			t.Parallel()
+
+			matches := dateMatch([]rune(tt.token))
+			require.NotEmpty(t, matches)
+
+			m := matches[0]
+			require.Equal(t, tt.token, m.Token)
+			require.Equal(t, tt.want[0], m.Year)
+			require.Equal(t, tt.want[1], m.Month)
+		})
+	}
+
+	require.Empty(t, dateMatch([]rune("1/1-91")))
+}
+
+func TestSpatialMatch(t *testing.T) {
+	t.Parallel()
+
+	matches := spatialMatch([]rune("xx QWERT xx 7896 xx"))
+
+	var tokens []string
+	for _, m := range matches {
+		tokens = append(tokens, m.Graph+":"+m.Token)
+	}
+
+	require.Contains(t, tokens, "qwerty:QWERT")
+	require.Contains(t, tokens, "keypad:7896")
+
+	for _, m := range matches {
+		if m.Graph == GraphQwerty && m.Token == "QWERT" {
+			require.Equal(t, 1, m.Turns)
+			require.Equal(t, 5, m.ShiftedCount)
+			require.Greater(t, spatialGuesses(m), 0.0)
+		}
+	}
+}
+
+func TestSequenceMatch(t *testing.T) {
+	t.Parallel()
+
+	matches := sequenceMatch([]rune("abcx9753"))
+	require.Len(t, matches, 2)
+
+	require.Equal(t, "abc", matches[0].Token)
+	require.True(t, matches[0].Ascending)
+	require.Equal(t, "9753", matches[1].Token)
+	require.Equal(t, "digits", matches[1].SequenceName)
+	require.False(t, matches[1].Ascending)
+}
+
+func TestRepeatMatch(t *testing.T) {
+	t.Parallel()
+
+	matches := repeatMatch([]rune("xaaayzabab"), nil)
+	require.Len(t, matches, 2)
+
+	require.Equal(t, "aaa", matches[0].Token)
+	require.Equal(t, "a", matches[0].BaseToken)
+	require.Equal(t, "abab", matches[1].Token)
+	require.Equal(t, 2, matches[1].RepeatCount)
+}
+
+func TestUppercaseVariations(t *testing.T) {
+	t.Parallel()
+
+	require.InDelta(t, 1.0, uppercaseVariations("word"), 0)
+	require.InDelta(t, 2.0, uppercaseVariations("Word"), 0)
+	require.InDelta(t, 2.0, uppercaseVariations("worD"), 0)
+	require.InDelta(t, 2.0, uppercaseVariations("WORD"), 0)
+	require.InDelta(t, nCk(4, 1)+nCk(4, 2), uppercaseVariations("wOrD"), 0)
+}
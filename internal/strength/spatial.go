@@ -0,0 +1,263 @@
+package strength
+
+import (
+	"strings"
+)
+
+// Names of the keyboard layouts.
+const (
+	GraphQwerty    = "qwerty"
+	GraphDvorak    = "dvorak"
+	GraphKeypad    = "keypad"
+	GraphMacKeypad = "mac_keypad"
+)
+
+// Keyboard layouts, where each row is offset by one character from the
+// previous one on the slanted keyboards.
+const (
+	qwertyLayout = `
+` + "`~" + ` 1! 2@ 3# 4$ 5% 6^ 7& 8* 9( 0) -_ =+
+    qQ wW eE rR tT yY uU iI oO pP [{ ]} \|
+     aA sS dD fF gG hH jJ kK lL ;: '"
+      zZ xX cC vV bB nN mM ,< .> /?
+`
+
+	dvorakLayout = `
+` + "`~" + ` 1! 2@ 3# 4$ 5% 6^ 7& 8* 9( 0) [{ ]}
+    '" ,< .> pP yY fF gG cC rR lL /? =+ \|
+     aA oO eE uU iI dD hH tT nN sS -_
+      ;: qQ jJ kK xX bB mM wW vV zZ
+`
+
+	keypadLayout = `
+  / * -
+7 8 9 +
+4 5 6
+1 2 3
+  0 .
+`
+
+	macKeypadLayout = `
+  = / *
+7 8 9 -
+4 5 6 +
+1 2 3
+  0 .
+`
+)
+
+// shiftedKeys are the characters typed with the shift key on the keyboards.
+const shiftedKeys = "~!@#$%^&*()_+QWERTYUIOP{}|ASDFGHJKL:\"ZXCVBNM<>?"
+
+// graph maps each key character to its neighbors, one per direction: each
+// neighbor contains the characters of the adjacent key, or is empty.
+type graph map[rune][]string
+
+// keyboard is a keyboard layout used to find the spatial patterns.
+type keyboard struct {
+	name  string
+	graph graph
+
+	// shifted reports whether the layout has shifted characters.
+	shifted bool
+
+	// startingPositions is the number of keys of the layout.
+	startingPositions float64
+
+	// averageDegree is the average number of neighbors of a key.
+	averageDegree float64
+}
+
+// keyboards are the layouts searched for spatial patterns.
+var keyboards = []*keyboard{ //nolint:gochecknoglobals
+	newKeyboard(GraphQwerty, qwertyLayout, true),
+	newKeyboard(GraphDvorak, dvorakLayout, true),
+	newKeyboard(GraphKeypad, keypadLayout, false),
+	newKeyboard(GraphMacKeypad, macKeypadLayout, false),
+}
+
+// newKeyboard returns the keyboard of the layout, where the slanted layouts
+// have six neighbors per key and the aligned ones eight.
+func newKeyboard(name, layout string, slanted bool) *keyboard {
+	g := buildGraph(layout, slanted)
+	kb := &keyboard{name: name, graph: g, shifted: slanted, startingPositions: float64(len(g))}
+
+	var degrees int
+
+	for _, adj := range g {
+		for _, keys := range adj {
+			if keys != "" {
+				degrees++
+			}
+		}
+	}
+
+	kb.averageDegree = float64(degrees) / float64(len(g))
+
+	return kb
+}
+
+// buildGraph returns the adjacency graph of the layout. The position of each
+// neighbor in the list is its direction, starting from the left one and going
+// clockwise.
+func buildGraph(layout string, slanted bool) graph {
+	type coord struct{ x, y int }
+
+	positions := make(map[coord]string)
+	tokenSize := len(strings.Fields(layout)[0])
+	unit := tokenSize + 1
+
+	for y, line := range strings.Split(layout, "\n") {
+		slant := 0
+		if slanted {
+			slant = y - 1
+		}
+
+		for _, token := range strings.Fields(line) {
+			positions[coord{(strings.Index(line, token) - slant) / unit, y}] = token
+		}
+	}
+
+	g := make(graph)
+
+	for pos, token := range positions {
+		var dirs []coord
+
+		if slanted {
+			dirs = []coord{{-1, 0}, {0, -1}, {1, -1}, {1, 0}, {0, 1}, {-1, 1}}
+		} else {
+			dirs = []coord{{-1, 0}, {-1, -1}, {0, -1}, {1, -1}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}}
+		}
+
+		adj := make([]string, len(dirs))
+
+		for i, d := range dirs {
+			adj[i] = positions[coord{pos.x + d.x, pos.y + d.y}]
+		}
+
+		for _, c := range token {
+			g[c] = adj
+		}
+	}
+
+	return g
+}
+
+// spatialMatch returns the keyboard walks of at least three characters.
+func spatialMatch(runes []rune) []*Match {
+	var matches []*Match
+
+	for _, kb := range keyboards {
+		matches = append(matches, kb.match(runes)...)
+	}
+
+	return matches
+}
+
+// match returns the keyboard walks of at least three characters on the
+// layout.
+func (kb *keyboard) match(runes []rune) []*Match {
+	var matches []*Match
+
+	for i := 0; i < len(runes)-1; {
+		j := i + 1
+		turns := 0
+		shifted := 0
+		lastDir := -1
+
+		if kb.shifted && strings.ContainsRune(shiftedKeys, runes[i]) {
+			shifted = 1
+		}
+
+		for ; j < len(runes); j++ {
+			dir, shift := kb.direction(runes[j-1], runes[j])
+			if dir < 0 {
+				break
+			}
+
+			if shift {
+				shifted++
+			}
+
+			if dir != lastDir {
+				turns++
+				lastDir = dir
+			}
+		}
+
+		if j-i > 2 {
+			matches = append(matches, &Match{
+				Pattern:      PatternSpatial,
+				I:            i,
+				J:            j - 1,
+				Token:        string(runes[i:j]),
+				Graph:        kb.name,
+				Turns:        turns,
+				ShiftedCount: shifted,
+			})
+		}
+
+		i = j
+	}
+
+	return matches
+}
+
+// direction returns the direction of the key of cur from the key of prev, or
+// -1 if they are not adjacent, and whether cur is a shifted character.
+func (kb *keyboard) direction(prev, cur rune) (int, bool) {
+	for dir, keys := range kb.graph[prev] {
+		idx := strings.IndexRune(keys, cur)
+		if idx >= 0 {
+			return dir, idx == 1
+		}
+	}
+
+	return -1, false
+}
+
+// spatialGuesses returns the number of keyboard walks of the length and turns
+// of the match, accounting for the shifted characters.
+func spatialGuesses(m *Match) float64 {
+	kb := keyboardByName(m.Graph)
+	length := m.length()
+
+	var guesses float64
+
+	for i := 2; i <= length; i++ {
+		possibleTurns := min(m.Turns, i-1)
+
+		for j := 1; j <= possibleTurns; j++ {
+			guesses += nCk(i-1, j-1) * kb.startingPositions * pow(kb.averageDegree, j)
+		}
+	}
+
+	if m.ShiftedCount > 0 {
+		unshifted := length - m.ShiftedCount
+
+		if unshifted == 0 {
+			guesses *= 2
+		} else {
+			var variations float64
+
+			for i := 1; i <= min(m.ShiftedCount, unshifted); i++ {
+				variations += nCk(m.ShiftedCount+unshifted, i)
+			}
+
+			guesses *= variations
+		}
+	}
+
+	return guesses
+}
+
+// keyboardByName returns the keyboard layout with the given name.
+func keyboardByName(name string) *keyboard {
+	for _, kb := range keyboards {
+		if kb.name == name {
+			return kb
+		}
+	}
+
+	return keyboards[0]
+}
@@ -0,0 +1,103 @@
+// Package strength estimates the strength of a password, following the zxcvbn
+// approach.
+//
+// The password is matched against the common patterns: words of the embedded
+// dictionaries of common passwords, English words, names and surnames (also
+// reversed or with l33t substitutions), keyboard sequences, repeats,
+// alphabetic and numeric sequences, recent years and dates. Each match is
+// assigned the number of guesses an attacker trying the most common patterns
+// first needs to find it, and the password is split into the sequence of
+// matches and brute-force segments requiring the fewest guesses overall. The
+// guesses are then mapped to a score from 0 (too guessable) to 4 (very
+// unguessable), and the weakest pattern drives the feedback.
+package strength
+
+import (
+	"math"
+	"unicode"
+)
+
+// MaxLength is the number of characters of the password that are analyzed.
+// The matching cost grows quadratically with the length, and the guesses of
+// the longer passwords exceed the highest score threshold anyway, so the
+// following characters are ignored.
+const MaxLength = 100
+
+// Score thresholds, as the number of guesses of each score.
+const (
+	// scoreDelta makes the thresholds exclusive of the minimum guesses of the
+	// matches, so a lone match of the given size falls in the lower score.
+	scoreDelta = 5
+
+	tooGuessable      = 1e3
+	veryGuessable     = 1e6
+	somewhatGuessable = 1e8
+	safelyUnguessable = 1e10
+)
+
+// Result is the strength estimate of a password.
+type Result struct {
+	// Score is the strength from 0 (too guessable) to 4 (very unguessable).
+	Score int `json:"score"`
+
+	// Guesses is the estimated number of guesses needed to find the password.
+	Guesses float64 `json:"guesses"`
+
+	// GuessesLog10 is the base-10 logarithm of the guesses.
+	GuessesLog10 float64 `json:"guesses_log10"`
+
+	// Sequence is the sequence of patterns the password is made of.
+	Sequence []*Match `json:"sequence"`
+
+	// Feedback explains the weakest pattern and how to improve the password.
+	Feedback Feedback `json:"feedback"`
+}
+
+// Evaluate returns the strength estimate of the password. The user inputs,
+// such as the user name or the email address, are matched as an additional
+// dictionary, as an attacker targeting the user would try them first.
+func Evaluate(password string, userInputs ...string) *Result {
+	runes := []rune(password)
+	if len(runes) > MaxLength {
+		runes = runes[:MaxLength]
+	}
+
+	dicts := rankedDictionaries(userInputs)
+	guesses, seq := mostGuessableSequence(runes, omnimatch(runes, dicts), false)
+	score := guessesToScore(guesses)
+
+	return &Result{
+		Score:        score,
+		Guesses:      guesses,
+		GuessesLog10: math.Log10(guesses),
+		Sequence:     seq,
+		Feedback:     feedback(score, seq),
+	}
+}
+
+// guessesToScore maps the number of guesses to the score.
+func guessesToScore(guesses float64) int {
+	switch {
+	case guesses < tooGuessable+scoreDelta:
+		return 0
+	case guesses < veryGuessable+scoreDelta:
+		return 1
+	case guesses < somewhatGuessable+scoreDelta:
+		return 2
+	case guesses < safelyUnguessable+scoreDelta:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// toLower returns the lowercase characters, preserving the positions.
+func toLower(runes []rune) []rune {
+	out := make([]rune, len(runes))
+
+	for i, r := range runes {
+		out[i] = unicode.ToLower(r)
+	}
+
+	return out
+}
@@ -0,0 +1,172 @@
+package strength
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		password   string
+		userInputs []string
+		wantScore  int
+		wantWarn   string
+		wantMatch  Match
+	}{
+		{
+			name:      "top common password",
+			password:  "password",
+			wantScore: 0,
+			wantWarn:  "This is a top-10 common password.",
+			wantMatch: Match{Pattern: PatternDictionary, Dictionary: DictPasswords, Token: "password"},
+		},
+		{
+			name:      "reversed common password",
+			password:  "drowssap",
+			wantScore: 0,
+			wantWarn:  "This is similar to a commonly used password.",
+			wantMatch: Match{Pattern: PatternDictionary, Dictionary: DictPasswords, Token: "drowssap", Reversed: true},
+		},
+		{
+			name:      "l33t common password",
+			password:  "p@ssw0rd",
+			wantScore: 0,
+			wantWarn:  "This is similar to a commonly used password.",
+			wantMatch: Match{Pattern: PatternDictionary, Dictionary: DictPasswords, Token: "p@ssw0rd", L33t: true},
+		},
+		{
+			name:      "keyboard walk",
+			password:  "rfvbgt",
+			wantScore: 1,
+			wantWarn:  "Short keyboard patterns are easy to guess.",
+			wantMatch: Match{Pattern: PatternSpatial, Graph: GraphQwerty, Token: "rfvbgt"},
+		},
+		{
+			name:      "repeat",
+			password:  "abcabcabc",
+			wantScore: 0,
+			wantWarn:  `Repeats like "abcabcabc" are only slightly harder to guess than "abc".`,
+			wantMatch: Match{Pattern: PatternRepeat, Token: "abcabcabc", BaseToken: "abc", RepeatCount: 3},
+		},
+		{
+			name:      "sequence",
+			password:  "lmnopq",
+			wantScore: 0,
+			wantWarn:  "Sequences like abc or 6543 are easy to guess.",
+			wantMatch: Match{Pattern: PatternSequence, Token: "lmnopq", SequenceName: "lower", Ascending: true},
+		},
+		{
+			name:      "date",
+			password:  "1985-03-14",
+			wantScore: 1,
+			wantWarn:  "Dates are often easy to guess.",
+			wantMatch: Match{Pattern: PatternDate, Token: "1985-03-14", Separator: "-", Year: 1985, Month: 3, Day: 14},
+		},
+		{
+			name:      "names",
+			password:  "JohnSmith",
+			wantScore: 1,
+			wantWarn:  "Common names and surnames are easy to guess.",
+			wantMatch: Match{Pattern: PatternDictionary, Dictionary: DictSurnames, Token: "Smith"},
+		},
+		{
+			name:       "user input",
+			password:   "Alice.Doe",
+			userInputs: []string{"alice.doe", "alice@example.com"},
+			wantScore:  0,
+			wantWarn:   "Passwords based on personal information are easy to guess.",
+			wantMatch:  Match{Pattern: PatternDictionary, Dictionary: DictUserInputs, Token: "Alice.Doe"},
+		},
+		{
+			name:      "passphrase",
+			password:  "correcthorsebatterystaple",
+			wantScore: 4,
+			wantMatch: Match{Pattern: PatternDictionary, Dictionary: DictEnglish, Token: "staple"},
+		},
+		{
+			name:      "random",
+			password:  "jgF8#kq2!vT9",
+			wantScore: 4,
+			wantMatch: Match{Pattern: PatternBruteforce, Token: "jgF8#kq2!vT9"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := Evaluate(tt.password, tt.userInputs...)
+			require.Equal(t, tt.wantScore, r.Score)
+			require.Equal(t, tt.wantWarn, r.Feedback.Warning)
+			require.Positive(t, r.Guesses)
+
+			if tt.wantScore <= minAcceptableScore {
+				require.NotEmpty(t, r.Feedback.Suggestions)
+			}
+
+			var found bool
+
+			for _, m := range r.Sequence {
+				if m.Pattern != tt.wantMatch.Pattern || m.Token != tt.wantMatch.Token {
+					continue
+				}
+
+				found = true
+
+				require.Equal(t, tt.wantMatch.Dictionary, m.Dictionary)
+				require.Equal(t, tt.wantMatch.Reversed, m.Reversed)
+				require.Equal(t, tt.wantMatch.L33t, m.L33t)
+				require.Equal(t, tt.wantMatch.Graph, m.Graph)
+				require.Equal(t, tt.wantMatch.BaseToken, m.BaseToken)
+				require.Equal(t, tt.wantMatch.RepeatCount, m.RepeatCount)
+				require.Equal(t, tt.wantMatch.SequenceName, m.SequenceName)
+				require.Equal(t, tt.wantMatch.Separator, m.Separator)
+				require.Equal(t, tt.wantMatch.Year, m.Year)
+			}
+
+			require.True(t, found, "%+v", r.Sequence)
+		})
+	}
+}
+
+func TestEvaluateSequenceCoversPassword(t *testing.T) {
+	t.Parallel()
+
+	for _, pwd := range []string{"", "a", "Tr0ub4dour&3", "coRrecth0rseba++ery9.23.2007staple$", "漢字かなカナ1234"} {
+		r := Evaluate(pwd)
+
+		next := 0
+
+		for _, m := range r.Sequence {
+			require.Equal(t, next, m.I, pwd)
+			next = m.J + 1
+		}
+
+		require.Equal(t, len([]rune(pwd)), next, pwd)
+	}
+}
+
+func TestEvaluateMaxLength(t *testing.T) {
+	t.Parallel()
+
+	r := Evaluate(strings.Repeat("x7#Kq", 40))
+
+	last := r.Sequence[len(r.Sequence)-1]
+	require.Equal(t, MaxLength-1, last.J)
+}
+
+func TestGuessesToScore(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, 0, guessesToScore(1))
+	require.Equal(t, 0, guessesToScore(1e3+4))
+	require.Equal(t, 1, guessesToScore(1e3+5))
+	require.Equal(t, 2, guessesToScore(1e6+5))
+	require.Equal(t, 3, guessesToScore(1e8+5))
+	require.Equal(t, 4, guessesToScore(1e10+5))
+}
@@ -0,0 +1,271 @@
+package uid
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tecnickcom/rndpwd/internal/charset"
+)
+
+// FormatUUID is the format reported by Inspect for all the UUID versions.
+const FormatUUID = "uuid"
+
+// UUID variants reported by Inspect.
+const (
+	VariantNCS       = "ncs"
+	VariantRFC9562   = "rfc9562"
+	VariantMicrosoft = "microsoft"
+	VariantFuture    = "future"
+	VariantNil       = "nil"
+	VariantMax       = "max"
+)
+
+const (
+	// gregorianOffset is the number of 100-nanosecond intervals between the
+	// UUID epoch (1582-10-15) and the Unix epoch.
+	gregorianOffset = 0x01b21dd213814000
+
+	// maxSnowflakeDigits is the number of decimal digits of the largest
+	// Snowflake ID.
+	maxSnowflakeDigits = 19
+)
+
+// Digit mappings from the identifier alphabets to the math/big text input.
+var (
+	// crockfordValues maps the uppercase Crockford base32 alphabet of the
+	// ULIDs to the big.Int base 32 digits.
+	crockfordValues = charset.Translator("0123456789ABCDEFGHJKMNPQRSTVWXYZ", "0123456789abcdefghijklmnopqrstuv") //nolint:gochecknoglobals
+
+	// base62Values maps the base62 alphabet of the KSUIDs to the big.Int base
+	// 62 digits.
+	base62Values = charset.Translator( //nolint:gochecknoglobals
+		"0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+		"0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	)
+)
+
+// Info contains the fields decoded from an identifier.
+type Info struct {
+	// ID is the inspected identifier.
+	ID string `json:"id"`
+
+	// Format is the detected format: uuid, ulid, ksuid or snowflake.
+	Format string `json:"format,omitempty"`
+
+	// Valid reports whether the identifier is well formed.
+	Valid bool `json:"valid"`
+
+	// Reason explains why the identifier is not valid.
+	Reason string `json:"reason,omitempty"`
+
+	// Version is the UUID version.
+	Version int `json:"version,omitempty"`
+
+	// Variant is the UUID variant: ncs, rfc9562, microsoft, future, or nil
+	// and max for the special UUIDs.
+	Variant string `json:"variant,omitempty"`
+
+	// Time is the embedded timestamp.
+	Time *time.Time `json:"time,omitempty"`
+
+	// Node is the node ID of the Snowflake IDs, or the MAC address of the
+	// version 1 and 6 UUIDs.
+	Node string `json:"node,omitempty"`
+
+	// Sequence is the sequence number of the Snowflake IDs, or the clock
+	// sequence of the version 1 and 6 UUIDs.
+	Sequence *int64 `json:"sequence,omitempty"`
+}
+
+// Inspect detects the format of the identifier from its shape and decodes its
+// embedded fields. The Snowflake timestamps are relative to the epoch, in
+// milliseconds since the Unix epoch.
+func Inspect(id string, snowflakeEpoch int64) Info {
+	info := Info{ID: id}
+
+	switch {
+	case len(id) == 36 && strings.Count(id, "-") == 4:
+		inspectUUID(&info)
+	case len(id) == ulidLength:
+		inspectULID(&info)
+	case len(id) == ksuidLength:
+		inspectKSUID(&info)
+	case len(id) > 0 && len(id) <= maxSnowflakeDigits && isDigits(id):
+		inspectSnowflake(&info, snowflakeEpoch)
+	default:
+		info.Reason = "unrecognized identifier format"
+	}
+
+	return info
+}
+
+// inspectUUID decodes the version, variant and, for the time-based versions,
+// the timestamp, clock sequence and node of the UUID.
+func inspectUUID(info *Info) {
+	info.Format = FormatUUID
+
+	u, err := ParseUUID(strings.ToLower(info.ID))
+	if err != nil {
+		info.Reason = "invalid UUID characters"
+		return
+	}
+
+	switch u {
+	case [16]byte{}:
+		info.Valid = true
+		info.Variant = VariantNil
+
+		return
+	case [16]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}:
+		info.Valid = true
+		info.Variant = VariantMax
+
+		return
+	}
+
+	info.Version = int(u[6] >> 4)
+	info.Variant = uuidVariant(u[8])
+
+	if info.Variant != VariantRFC9562 {
+		info.Reason = "not an RFC 9562 variant UUID"
+		return
+	}
+
+	if info.Version < 1 || info.Version > 8 {
+		info.Reason = "unknown UUID version"
+		return
+	}
+
+	info.Valid = true
+
+	switch info.Version {
+	case 1:
+		ts := uint64(binary.BigEndian.Uint16(u[6:8])&0x0fff)<<48 |
+			uint64(binary.BigEndian.Uint16(u[4:6]))<<32 |
+			uint64(binary.BigEndian.Uint32(u[0:4]))
+		setGregorian(info, u, ts)
+	case 6:
+		ts := uint64(binary.BigEndian.Uint32(u[0:4]))<<28 |
+			uint64(binary.BigEndian.Uint16(u[4:6]))<<12 |
+			uint64(binary.BigEndian.Uint16(u[6:8])&0x0fff)
+		setGregorian(info, u, ts)
+	case 7:
+		ms := binary.BigEndian.Uint64(u[0:8]) >> 16
+		setTime(info, time.UnixMilli(int64(ms))) //nolint:gosec
+	}
+}
+
+// uuidVariant returns the variant encoded in the most significant bits of the
+// octet 8 of a UUID.
+func uuidVariant(b byte) string {
+	switch {
+	case b&0x80 == 0:
+		return VariantNCS
+	case b&0xc0 == 0x80:
+		return VariantRFC9562
+	case b&0xe0 == 0xc0:
+		return VariantMicrosoft
+	default:
+		return VariantFuture
+	}
+}
+
+// setGregorian sets the timestamp, clock sequence and MAC address of the
+// version 1 and 6 UUIDs, where ts counts the 100-nanosecond intervals since
+// 1582-10-15.
+func setGregorian(info *Info, u [16]byte, ts uint64) {
+	ns := (int64(ts) - gregorianOffset) * 100 //nolint:gosec
+	setTime(info, time.Unix(0, ns))
+
+	seq := int64(binary.BigEndian.Uint16(u[8:10]) & 0x3fff)
+	info.Sequence = &seq
+
+	mac := make([]string, 0, 6)
+	for _, b := range u[10:] {
+		mac = append(mac, fmt.Sprintf("%02x", b))
+	}
+
+	info.Node = strings.Join(mac, ":")
+}
+
+// inspectULID decodes the millisecond timestamp of the ULID.
+func inspectULID(info *Info) {
+	info.Format = FormatULID
+
+	s := strings.ToUpper(info.ID)
+	if strings.Trim(s, "0123456789ABCDEFGHJKMNPQRSTVWXYZ") != "" {
+		info.Reason = "invalid Crockford base32 characters"
+		return
+	}
+
+	if s[0] > '7' {
+		info.Reason = "the value exceeds 128 bits"
+		return
+	}
+
+	v, _ := new(big.Int).SetString(crockfordValues.Replace(s), 32)
+
+	var u [16]byte
+
+	v.FillBytes(u[:])
+
+	info.Valid = true
+	setTime(info, time.UnixMilli(int64(binary.BigEndian.Uint64(u[0:8])>>16))) //nolint:gosec
+}
+
+// inspectKSUID decodes the second timestamp of the KSUID.
+func inspectKSUID(info *Info) {
+	info.Format = FormatKSUID
+
+	if strings.Trim(info.ID, "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz") != "" {
+		info.Reason = "invalid base62 characters"
+		return
+	}
+
+	v, _ := new(big.Int).SetString(base62Values.Replace(info.ID), 62)
+	if v.BitLen() > 160 {
+		info.Reason = "the value exceeds 160 bits"
+		return
+	}
+
+	var u [20]byte
+
+	v.FillBytes(u[:])
+
+	info.Valid = true
+	setTime(info, time.Unix(int64(binary.BigEndian.Uint32(u[0:4]))+ksuidEpoch, 0))
+}
+
+// inspectSnowflake decodes the timestamp, node ID and sequence number of the
+// Snowflake ID.
+func inspectSnowflake(info *Info, epoch int64) {
+	info.Format = FormatSnowflake
+
+	id, err := strconv.ParseInt(info.ID, 10, 64)
+	if err != nil {
+		info.Reason = "the value exceeds 63 bits"
+		return
+	}
+
+	info.Valid = true
+	setTime(info, time.UnixMilli(id>>(snowflakeNodeBits+snowflakeSeqBits)+epoch))
+	info.Node = strconv.FormatInt(id>>snowflakeSeqBits&MaxSnowflakeNode, 10)
+
+	seq := id & (1<<snowflakeSeqBits - 1)
+	info.Sequence = &seq
+}
+
+// setTime sets the embedded timestamp, in UTC.
+func setTime(info *Info, t time.Time) {
+	t = t.UTC()
+	info.Time = &t
+}
+
+// isDigits reports whether the string only contains decimal digits.
+func isDigits(s string) bool {
+	return strings.Trim(s, "0123456789") == ""
+}
@@ -0,0 +1,207 @@
+package uid
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect(t *testing.T) {
+	t.Parallel()
+
+	// RFC 9562 Appendix A test vectors: Tuesday, February 22, 2022 2:22:22 PM GMT-05:00
+	rfcTime := time.Date(2022, 2, 22, 19, 22, 22, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		id           string
+		wantFormat   string
+		wantValid    bool
+		wantVersion  int
+		wantVariant  string
+		wantTime     time.Time
+		wantNode     string
+		wantSequence int64
+	}{
+		{
+			name:         "uuidv1",
+			id:           "C232AB00-9414-11EC-B3C8-9F6BDECED846",
+			wantFormat:   FormatUUID,
+			wantValid:    true,
+			wantVersion:  1,
+			wantVariant:  VariantRFC9562,
+			wantTime:     rfcTime,
+			wantNode:     "9f:6b:de:ce:d8:46",
+			wantSequence: 0x33c8,
+		},
+		{
+			name:         "uuidv6",
+			id:           "1ec9414c-232a-6b00-b3c8-9f6bdeced846",
+			wantFormat:   FormatUUID,
+			wantValid:    true,
+			wantVersion:  6,
+			wantVariant:  VariantRFC9562,
+			wantTime:     rfcTime,
+			wantNode:     "9f:6b:de:ce:d8:46",
+			wantSequence: 0x33c8,
+		},
+		{
+			name:        "uuidv7",
+			id:          "017f22e2-79b0-7cc3-98c4-dc0c0c07398f",
+			wantFormat:  FormatUUID,
+			wantValid:   true,
+			wantVersion: 7,
+			wantVariant: VariantRFC9562,
+			wantTime:    rfcTime,
+		},
+		{
+			name:        "uuidv4",
+			id:          "919108f7-52d1-4320-9bac-f847db4148a8",
+			wantFormat:  FormatUUID,
+			wantValid:   true,
+			wantVersion: 4,
+			wantVariant: VariantRFC9562,
+		},
+		{
+			name:        "nil uuid",
+			id:          "00000000-0000-0000-0000-000000000000",
+			wantFormat:  FormatUUID,
+			wantValid:   true,
+			wantVariant: VariantNil,
+		},
+		{
+			name:        "max uuid",
+			id:          "FFFFFFFF-FFFF-FFFF-FFFF-FFFFFFFFFFFF",
+			wantFormat:  FormatUUID,
+			wantValid:   true,
+			wantVariant: VariantMax,
+		},
+		{
+			name:        "microsoft variant",
+			id:          "919108f7-52d1-4320-dbac-f847db4148a8",
+			wantFormat:  FormatUUID,
+			wantVersion: 4,
+			wantVariant: VariantMicrosoft,
+		},
+		{
+			name:        "unknown uuid version",
+			id:          "919108f7-52d1-9320-9bac-f847db4148a8",
+			wantFormat:  FormatUUID,
+			wantVersion: 9,
+			wantVariant: VariantRFC9562,
+		},
+		{
+			name:       "invalid uuid characters",
+			id:         "919108f7-52d1-4320-9bac-f847db4148ag",
+			wantFormat: FormatUUID,
+		},
+		{
+			name:       "ulid",
+			id:         "01arz3ndektsv4rrffq69g5fav",
+			wantFormat: FormatULID,
+			wantValid:  true,
+			wantTime:   time.UnixMilli(1469922850259).UTC(),
+		},
+		{
+			name:       "ulid overflow",
+			id:         "81ARZ3NDEKTSV4RRFFQ69G5FAV",
+			wantFormat: FormatULID,
+		},
+		{
+			name:       "ulid invalid characters",
+			id:         "01ARZ3NDEKTSV4RRFFQ69G5FAU",
+			wantFormat: FormatULID,
+		},
+		{
+			name:       "ksuid",
+			id:         "0ujtsYcgvSTl8PAuAdqWYSMnLOv",
+			wantFormat: FormatKSUID,
+			wantValid:  true,
+			wantTime:   time.Unix(1507608047, 0).UTC(),
+		},
+		{
+			name:       "ksuid overflow",
+			id:         "zzzzzzzzzzzzzzzzzzzzzzzzzzz",
+			wantFormat: FormatKSUID,
+		},
+		{
+			name:         "snowflake",
+			id:           "1382350606417817604",
+			wantFormat:   FormatSnowflake,
+			wantValid:    true,
+			wantTime:     time.UnixMilli(1618413042059).UTC(),
+			wantNode:     "327",
+			wantSequence: 4,
+		},
+		{
+			name:       "snowflake overflow",
+			id:         "9999999999999999999",
+			wantFormat: FormatSnowflake,
+		},
+		{
+			name: "unrecognized",
+			id:   "not-an-id",
+		},
+		{
+			name: "empty",
+			id:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			info := Inspect(tt.id, DefaultSnowflakeEpoch)
+
+			require.Equal(t, tt.id, info.ID)
+			require.Equal(t, tt.wantFormat, info.Format)
+			require.Equal(t, tt.wantValid, info.Valid)
+			require.Equal(t, tt.wantValid, info.Reason == "")
+			require.Equal(t, tt.wantVersion, info.Version)
+			require.Equal(t, tt.wantVariant, info.Variant)
+			require.Equal(t, tt.wantNode, info.Node)
+
+			if tt.wantTime.IsZero() || !tt.wantValid {
+				require.Nil(t, info.Time)
+			} else {
+				require.NotNil(t, info.Time)
+				require.True(t, tt.wantTime.Equal(*info.Time), "got %v", *info.Time)
+			}
+
+			if tt.wantSequence != 0 {
+				require.NotNil(t, info.Sequence)
+				require.Equal(t, tt.wantSequence, *info.Sequence)
+			}
+		})
+	}
+}
+
+func TestInspectGenerated(t *testing.T) {
+	t.Parallel()
+
+	at := time.UnixMilli(1700000000123)
+	g := New(WithClock(func() time.Time { return at }), WithSnowflake(42, DefaultSnowflakeEpoch))
+
+	for _, format := range []string{FormatUUIDv7, FormatULID, FormatKSUID, FormatSnowflake} {
+		lst, err := g.Generate(format, 2, Params{})
+		require.NoError(t, err)
+
+		info := Inspect(lst[1], DefaultSnowflakeEpoch)
+		require.True(t, info.Valid, format)
+		require.NotNil(t, info.Time, format)
+
+		want := at
+		if format == FormatKSUID {
+			want = at.Truncate(time.Second)
+		}
+
+		require.True(t, want.Equal(*info.Time), "%s: got %v", format, *info.Time)
+
+		if format == FormatSnowflake {
+			require.Equal(t, "42", info.Node)
+			require.Equal(t, int64(1), *info.Sequence)
+		}
+	}
+}
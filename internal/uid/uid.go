@@ -0,0 +1,553 @@
+// Package uid generates unique identifiers in several formats: random and
+// name-based UUIDs, time-ordered UUIDs, ULIDs, KSUIDs and Snowflake IDs, and
+// NanoIDs.
+//
+// The time-ordered identifiers produced by a Generator are strictly monotonic:
+// within the same clock tick, or when the clock goes backwards, the random or
+// sequence part of the last identifier is incremented instead of drawn again.
+package uid
+
+import (
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tecnickcom/rndpwd/internal/charset"
+)
+
+// Identifier formats.
+const (
+	// FormatUUIDv4 is the random UUID of RFC 9562.
+	FormatUUIDv4 = "uuidv4"
+
+	// FormatUUIDv5 is the name-based SHA-1 UUID of RFC 9562.
+	FormatUUIDv5 = "uuidv5"
+
+	// FormatUUIDv7 is the time-ordered UUID of RFC 9562, with a millisecond
+	// Unix timestamp.
+	FormatUUIDv7 = "uuidv7"
+
+	// FormatULID is the Universally Unique Lexicographically Sortable
+	// Identifier, with a millisecond Unix timestamp.
+	FormatULID = "ulid"
+
+	// FormatKSUID is the K-Sortable Unique Identifier of Segment, with a
+	// timestamp in seconds.
+	FormatKSUID = "ksuid"
+
+	// FormatNanoID is the random string of the NanoID library.
+	FormatNanoID = "nanoid"
+
+	// FormatSnowflake is the 63-bit Twitter Snowflake ID, made of a
+	// millisecond timestamp, a node ID and a sequence number.
+	FormatSnowflake = "snowflake"
+)
+
+// Default settings of the identifiers.
+const (
+	// DefaultNanoIDAlphabet is the URL-safe alphabet of the NanoIDs.
+	DefaultNanoIDAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789_-"
+
+	// DefaultNanoIDLength is the number of characters of the NanoIDs, for
+	// about 126 bits of entropy.
+	DefaultNanoIDLength = 21
+
+	// DefaultSnowflakeEpoch is the Twitter Snowflake epoch, in milliseconds
+	// since the Unix epoch (2010-11-04T01:42:54.657Z).
+	DefaultSnowflakeEpoch = 1288834974657
+)
+
+// Bounds of the identifier settings.
+const (
+	// MaxNanoIDLength is the maximum number of characters of the NanoIDs.
+	MaxNanoIDLength = 256
+
+	// MaxSnowflakeNode is the maximum node ID of the Snowflake IDs (10 bits).
+	MaxSnowflakeNode = 1<<snowflakeNodeBits - 1
+)
+
+const (
+	// ksuidEpoch is the KSUID epoch, in seconds since the Unix epoch.
+	ksuidEpoch = 1400000000
+
+	// ksuidLength is the number of base62 characters of a KSUID.
+	ksuidLength = 27
+
+	// ulidLength is the number of base32 characters of a ULID.
+	ulidLength = 26
+
+	// snowflakeNodeBits and snowflakeSeqBits are the sizes of the Snowflake
+	// node ID and sequence number; the timestamp takes the remaining 41 bits.
+	snowflakeNodeBits = 10
+	snowflakeSeqBits  = 12
+	snowflakeTimeBits = 41
+
+	// uuidv7LowBits and uuidv7HighMax define the 74 random bits of a UUIDv7,
+	// split into a 62-bit low part (rand_b) and a 12-bit high part (rand_a).
+	uuidv7LowBits = 62
+	uuidv7HighMax = 1<<12 - 1
+)
+
+// ErrInvalid is wrapped by all the errors reporting invalid identifier
+// settings.
+var ErrInvalid = errors.New("invalid identifier settings")
+
+// Digit mappings from the math/big text output to the identifier alphabets.
+var (
+	// crockfordDigits maps the big.Int base 32 digits to the uppercase
+	// Crockford base32 alphabet of the ULIDs.
+	crockfordDigits = charset.Translator("0123456789abcdefghijklmnopqrstuv", "0123456789ABCDEFGHJKMNPQRSTVWXYZ") //nolint:gochecknoglobals
+
+	// base62Digits maps the big.Int base 62 digits to the base62 alphabet of
+	// the KSUIDs.
+	base62Digits = charset.Translator( //nolint:gochecknoglobals
+		"0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ",
+		"0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+	)
+
+	// namespaces are the predefined UUIDv5 namespaces of RFC 9562.
+	namespaces = map[string]string{ //nolint:gochecknoglobals
+		"dns":  "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		"url":  "6ba7b811-9dad-11d1-80b4-00c04fd430c8",
+		"oid":  "6ba7b812-9dad-11d1-80b4-00c04fd430c8",
+		"x500": "6ba7b814-9dad-11d1-80b4-00c04fd430c8",
+	}
+)
+
+// Params contains the settings of the formats that require them.
+type Params struct {
+	// Namespace is the UUIDv5 namespace: dns, url, oid, x500 or a UUID.
+	Namespace string
+
+	// Name is the UUIDv5 name.
+	Name string
+
+	// Alphabet is the NanoID alphabet (default DefaultNanoIDAlphabet).
+	Alphabet string
+
+	// Length is the NanoID length (default DefaultNanoIDLength).
+	Length int
+}
+
+// Generator produces unique identifiers. It is safe for concurrent use.
+type Generator struct {
+	mu     sync.Mutex
+	reader io.Reader
+	now    func() time.Time
+	node   int64
+	epoch  int64
+
+	// last state of the time-ordered identifiers
+	v7Time    int64
+	v7High    uint64
+	v7Low     uint64
+	ulidTime  int64
+	ulidRand  [10]byte
+	ksuidTime int64
+	ksuidRand [16]byte
+	sfTime    int64
+	sfSeq     int64
+}
+
+// Option is a type to allow setting custom generator options.
+type Option func(g *Generator)
+
+// WithSource sets the entropy source of the generator (default the OS CSPRNG).
+func WithSource(r io.Reader) Option {
+	return func(g *Generator) {
+		g.reader = r
+	}
+}
+
+// WithClock sets the clock of the time-ordered identifiers (default
+// time.Now).
+func WithClock(now func() time.Time) Option {
+	return func(g *Generator) {
+		g.now = now
+	}
+}
+
+// WithSnowflake sets the node ID, from 0 to MaxSnowflakeNode, and the epoch in
+// milliseconds since the Unix epoch of the Snowflake IDs (default 0 and
+// DefaultSnowflakeEpoch).
+func WithSnowflake(node, epoch int64) Option {
+	return func(g *Generator) {
+		g.node = node
+		g.epoch = epoch
+	}
+}
+
+// New instantiate a new identifier generator.
+func New(opts ...Option) *Generator {
+	g := &Generator{
+		reader: rand.Reader,
+		now:    time.Now,
+		epoch:  DefaultSnowflakeEpoch,
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(g)
+	}
+
+	return g
+}
+
+// Formats returns the supported identifier formats.
+func Formats() []string {
+	return []string{FormatUUIDv4, FormatUUIDv5, FormatUUIDv7, FormatULID, FormatKSUID, FormatNanoID, FormatSnowflake}
+}
+
+// TimeOrdered reports whether the identifiers of the format embed a timestamp.
+func TimeOrdered(format string) bool {
+	switch format {
+	case FormatUUIDv7, FormatULID, FormatKSUID, FormatSnowflake:
+		return true
+	default:
+		return false
+	}
+}
+
+// Generate returns n identifiers of the format. The time-ordered identifiers
+// are strictly increasing, also across calls.
+func (g *Generator) Generate(format string, n int, p Params) ([]string, error) {
+	next, err := g.newFormat(format, p)
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	lst := make([]string, n)
+
+	for i := range n {
+		lst[i], err = next()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return lst, nil
+}
+
+// newFormat returns the function producing the next identifier of the format,
+// once the settings are checked.
+func (g *Generator) newFormat(format string, p Params) (func() (string, error), error) {
+	switch format {
+	case FormatUUIDv4:
+		return g.uuidv4, nil
+	case FormatUUIDv5:
+		return uuidv5Func(p.Namespace, p.Name)
+	case FormatUUIDv7:
+		return g.uuidv7, nil
+	case FormatULID:
+		return g.ulid, nil
+	case FormatKSUID:
+		return g.ksuid, nil
+	case FormatNanoID:
+		return g.nanoIDFunc(p.Alphabet, p.Length)
+	case FormatSnowflake:
+		if g.node < 0 || g.node > MaxSnowflakeNode {
+			return nil, fmt.Errorf("%w: the Snowflake node ID must be between 0 and %d", ErrInvalid, MaxSnowflakeNode)
+		}
+
+		return g.snowflake, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown format %q", ErrInvalid, format)
+	}
+}
+
+// uuidv4 returns a random UUID.
+func (g *Generator) uuidv4() (string, error) {
+	var u [16]byte
+
+	_, err := io.ReadFull(g.reader, u[:])
+	if err != nil {
+		return "", fmt.Errorf("failed reading the UID bytes: %w", err)
+	}
+
+	return formatUUID(u, 4), nil
+}
+
+// uuidv5Func returns the function producing the name-based UUID of the
+// namespace and name, which is always the same.
+func uuidv5Func(namespace, name string) (func() (string, error), error) {
+	if name == "" {
+		return nil, fmt.Errorf("%w: the UUIDv5 name is required", ErrInvalid)
+	}
+
+	if ns, ok := namespaces[strings.ToLower(namespace)]; ok {
+		namespace = ns
+	}
+
+	ns, err := ParseUUID(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("%w: the UUIDv5 namespace must be dns, url, oid, x500 or a UUID", ErrInvalid)
+	}
+
+	h := sha1.New() //nolint:gosec
+	h.Write(ns[:])
+	h.Write([]byte(name))
+
+	var u [16]byte
+
+	copy(u[:], h.Sum(nil))
+
+	id := formatUUID(u, 5)
+
+	return func() (string, error) { return id, nil }, nil
+}
+
+// uuidv7 returns the next time-ordered UUID. The 74 random bits are
+// incremented, as a counter, when the millisecond has not changed.
+func (g *Generator) uuidv7() (string, error) {
+	ms := g.now().UnixMilli()
+
+	if ms <= g.v7Time {
+		ms = g.v7Time
+		g.v7Low++
+
+		if g.v7Low>>uuidv7LowBits != 0 {
+			g.v7Low = 0
+			g.v7High++
+		}
+
+		if g.v7High > uuidv7HighMax {
+			// the counter is exhausted: borrow the next millisecond
+			ms++
+		}
+	}
+
+	if ms != g.v7Time {
+		var b [10]byte
+
+		_, err := io.ReadFull(g.reader, b[:])
+		if err != nil {
+			return "", fmt.Errorf("failed reading the UID bytes: %w", err)
+		}
+
+		g.v7Time = ms
+		g.v7High = uint64(binary.BigEndian.Uint16(b[:2])) & uuidv7HighMax
+		g.v7Low = binary.BigEndian.Uint64(b[2:]) >> (64 - uuidv7LowBits)
+	}
+
+	var u [16]byte
+
+	binary.BigEndian.PutUint64(u[:8], uint64(ms)<<16|g.v7High) //nolint:gosec
+	binary.BigEndian.PutUint64(u[8:], g.v7Low)
+
+	return formatUUID(u, 7), nil
+}
+
+// ulid returns the next ULID. The 80 random bits are incremented when the
+// millisecond has not changed.
+func (g *Generator) ulid() (string, error) {
+	ms := g.now().UnixMilli()
+
+	if ms <= g.ulidTime {
+		ms = g.ulidTime
+
+		if increment(g.ulidRand[:]) {
+			// the random part overflowed: borrow the next millisecond
+			ms++
+		}
+	}
+
+	if ms != g.ulidTime {
+		_, err := io.ReadFull(g.reader, g.ulidRand[:])
+		if err != nil {
+			return "", fmt.Errorf("failed reading the UID bytes: %w", err)
+		}
+
+		g.ulidTime = ms
+	}
+
+	var u [16]byte
+
+	binary.BigEndian.PutUint64(u[:8], uint64(ms)<<16) //nolint:gosec
+	copy(u[6:], g.ulidRand[:])
+
+	return encodeBig(u[:], 32, crockfordDigits, ulidLength), nil
+}
+
+// ksuid returns the next KSUID. The 128-bit payload is incremented when the
+// second has not changed.
+func (g *Generator) ksuid() (string, error) {
+	sec := g.now().Unix() - ksuidEpoch
+
+	if sec <= g.ksuidTime {
+		sec = g.ksuidTime
+
+		if increment(g.ksuidRand[:]) {
+			// the payload overflowed: borrow the next second
+			sec++
+		}
+	}
+
+	if sec != g.ksuidTime {
+		_, err := io.ReadFull(g.reader, g.ksuidRand[:])
+		if err != nil {
+			return "", fmt.Errorf("failed reading the UID bytes: %w", err)
+		}
+
+		g.ksuidTime = sec
+	}
+
+	if sec < 0 || sec > 1<<32-1 {
+		return "", fmt.Errorf("%w: the time is outside of the KSUID range", ErrInvalid)
+	}
+
+	var u [20]byte
+
+	binary.BigEndian.PutUint32(u[:4], uint32(sec))
+	copy(u[4:], g.ksuidRand[:])
+
+	return encodeBig(u[:], 62, base62Digits, ksuidLength), nil
+}
+
+// snowflake returns the next Snowflake ID. The sequence number is incremented
+// when the millisecond has not changed.
+func (g *Generator) snowflake() (string, error) {
+	ms := g.now().UnixMilli() - g.epoch
+	if ms < 0 {
+		return "", fmt.Errorf("%w: the time is before the Snowflake epoch", ErrInvalid)
+	}
+
+	if ms <= g.sfTime {
+		ms = g.sfTime
+		g.sfSeq++
+
+		if g.sfSeq>>snowflakeSeqBits != 0 {
+			// the sequence is exhausted: borrow the next millisecond
+			ms++
+		}
+	}
+
+	if ms != g.sfTime {
+		g.sfTime = ms
+		g.sfSeq = 0
+	}
+
+	if ms>>snowflakeTimeBits != 0 {
+		return "", fmt.Errorf("%w: the time is outside of the Snowflake epoch range", ErrInvalid)
+	}
+
+	return strconv.FormatInt(ms<<(snowflakeNodeBits+snowflakeSeqBits)|g.node<<snowflakeSeqBits|g.sfSeq, 10), nil
+}
+
+// nanoIDFunc returns the function producing the NanoIDs of the alphabet and
+// length. The characters are drawn uniformly by rejecting the random bytes
+// beyond the largest multiple of the alphabet size.
+func (g *Generator) nanoIDFunc(alphabet string, length int) (func() (string, error), error) {
+	if alphabet == "" {
+		alphabet = DefaultNanoIDAlphabet
+	}
+
+	if length == 0 {
+		length = DefaultNanoIDLength
+	}
+
+	if len(alphabet) < 2 || len(alphabet) > 256 || !distinctPrintable(alphabet) {
+		return nil, fmt.Errorf("%w: the NanoID alphabet must contain 2 to 256 distinct printable ASCII characters", ErrInvalid)
+	}
+
+	if length < 1 || length > MaxNanoIDLength {
+		return nil, fmt.Errorf("%w: the NanoID length must be between 1 and %d", ErrInvalid, MaxNanoIDLength)
+	}
+
+	limit := 256 - 256%len(alphabet)
+
+	return func() (string, error) {
+		out := make([]byte, 0, length)
+		buf := make([]byte, length)
+
+		for len(out) < length {
+			_, err := io.ReadFull(g.reader, buf)
+			if err != nil {
+				return "", fmt.Errorf("failed reading the UID bytes: %w", err)
+			}
+
+			for _, b := range buf {
+				if int(b) < limit && len(out) < length {
+					out = append(out, alphabet[int(b)%len(alphabet)])
+				}
+			}
+		}
+
+		return string(out), nil
+	}, nil
+}
+
+// ParseUUID returns the 16 bytes of the UUID in the canonical 8-4-4-4-12
+// hexadecimal format.
+func ParseUUID(s string) ([16]byte, error) {
+	var u [16]byte
+
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, fmt.Errorf("%w: not a UUID", ErrInvalid)
+	}
+
+	_, err := hex.Decode(u[:], []byte(s[0:8]+s[9:13]+s[14:18]+s[19:23]+s[24:]))
+	if err != nil {
+		return u, fmt.Errorf("%w: not a UUID", ErrInvalid)
+	}
+
+	return u, nil
+}
+
+// formatUUID sets the version and the RFC 9562 variant of the UUID and
+// returns its canonical 8-4-4-4-12 hexadecimal format.
+func formatUUID(u [16]byte, version byte) string {
+	u[6] = u[6]&0x0f | version<<4
+	u[8] = u[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
+
+// encodeBig returns the big-endian number in the base, with the digits
+// mapped to the alphabet and left-padded with zeros to the length.
+func encodeBig(b []byte, base int, digits *strings.Replacer, length int) string {
+	s := digits.Replace(new(big.Int).SetBytes(b).Text(base))
+
+	return strings.Repeat("0", length-len(s)) + s
+}
+
+// increment adds one to the big-endian number and reports whether it
+// overflowed.
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+
+		if b[i] != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// distinctPrintable reports whether the string only contains distinct
+// printable ASCII characters.
+func distinctPrintable(s string) bool {
+	var seen [256]bool
+
+	for i := range len(s) {
+		c := s[i]
+		if c <= ' ' || c > '~' || seen[c] {
+			return false
+		}
+
+		seen[c] = true
+	}
+
+	return true
+}
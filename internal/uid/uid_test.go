@@ -0,0 +1,260 @@
+package uid
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		format  string
+		params  Params
+		pattern string
+	}{
+		{
+			name:    "uuidv4",
+			format:  FormatUUIDv4,
+			pattern: `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+		},
+		{
+			name:    "uuidv7",
+			format:  FormatUUIDv7,
+			pattern: `^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+		},
+		{
+			name:    "ulid",
+			format:  FormatULID,
+			pattern: `^[0-7][0-9A-HJKMNP-TV-Z]{25}$`,
+		},
+		{
+			name:    "ksuid",
+			format:  FormatKSUID,
+			pattern: `^[0-9A-Za-z]{27}$`,
+		},
+		{
+			name:    "snowflake",
+			format:  FormatSnowflake,
+			pattern: `^[0-9]{1,19}$`,
+		},
+		{
+			name:    "nanoid default",
+			format:  FormatNanoID,
+			pattern: `^[A-Za-z0-9_-]{21}$`,
+		},
+		{
+			name:    "nanoid custom",
+			format:  FormatNanoID,
+			params:  Params{Alphabet: "abc", Length: 40},
+			pattern: `^[abc]{40}$`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			lst, err := New().Generate(tt.format, 5, tt.params)
+			require.NoError(t, err)
+			require.Len(t, lst, 5)
+
+			seen := make(map[string]bool, len(lst))
+
+			for _, id := range lst {
+				require.Regexp(t, regexp.MustCompile(tt.pattern), id)
+				require.False(t, seen[id], "duplicate identifier %s", id)
+
+				seen[id] = true
+			}
+		})
+	}
+}
+
+func TestGenerateUUIDv5(t *testing.T) {
+	t.Parallel()
+
+	g := New()
+
+	// RFC 9562 Appendix A.4 test vector
+	lst, err := g.Generate(FormatUUIDv5, 1, Params{Namespace: "dns", Name: "www.example.com"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"2ed6657d-e927-568b-95e1-2665a8aea6a2"}, lst)
+
+	lst, err = g.Generate(FormatUUIDv5, 1, Params{Namespace: "6ba7b810-9dad-11d1-80b4-00c04fd430c8", Name: "www.example.com"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"2ed6657d-e927-568b-95e1-2665a8aea6a2"}, lst)
+}
+
+func TestGenerateMonotonic(t *testing.T) {
+	t.Parallel()
+
+	// a frozen clock forces the counter and sequence increments
+	at := time.UnixMilli(1700000000000)
+
+	for _, format := range []string{FormatUUIDv7, FormatULID, FormatKSUID} {
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			g := New(WithClock(func() time.Time { return at }))
+
+			first, err := g.Generate(format, 500, Params{})
+			require.NoError(t, err)
+
+			second, err := g.Generate(format, 500, Params{})
+			require.NoError(t, err)
+
+			lst := append(first, second...)
+
+			for i := 1; i < len(lst); i++ {
+				require.Less(t, lst[i-1], lst[i])
+			}
+		})
+	}
+
+	t.Run(FormatSnowflake, func(t *testing.T) {
+		t.Parallel()
+
+		g := New(WithClock(func() time.Time { return at }), WithSnowflake(7, DefaultSnowflakeEpoch))
+
+		// more than 4096 IDs overflow the sequence into the next millisecond
+		lst, err := g.Generate(FormatSnowflake, 5000, Params{})
+		require.NoError(t, err)
+
+		prev := int64(-1)
+
+		for _, s := range lst {
+			id, err := strconv.ParseInt(s, 10, 64)
+			require.NoError(t, err)
+			require.Greater(t, id, prev)
+			require.Equal(t, int64(7), id>>12&0x3ff)
+
+			prev = id
+		}
+
+		first, _ := strconv.ParseInt(lst[0], 10, 64)
+		require.Equal(t, at.UnixMilli()-DefaultSnowflakeEpoch, first>>22)
+	})
+}
+
+func TestGenerateClockBackwards(t *testing.T) {
+	t.Parallel()
+
+	at := time.UnixMilli(1700000000000)
+	g := New(WithClock(func() time.Time { return at }))
+
+	before, err := g.Generate(FormatUUIDv7, 1, Params{})
+	require.NoError(t, err)
+
+	at = at.Add(-time.Second)
+
+	after, err := g.Generate(FormatUUIDv7, 1, Params{})
+	require.NoError(t, err)
+	require.Less(t, before[0], after[0])
+}
+
+func TestGenerateDeterministic(t *testing.T) {
+	t.Parallel()
+
+	seed := bytes.Repeat([]byte{0xa5}, 64)
+
+	a, err := New(WithSource(bytes.NewReader(seed))).Generate(FormatUUIDv4, 2, Params{})
+	require.NoError(t, err)
+
+	b, err := New(WithSource(bytes.NewReader(seed))).Generate(FormatUUIDv4, 2, Params{})
+	require.NoError(t, err)
+
+	require.Equal(t, a, b)
+	require.Equal(t, "a5a5a5a5-a5a5-45a5-a5a5-a5a5a5a5a5a5", a[0])
+}
+
+func TestGenerateErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		gen     *Generator
+		format  string
+		params  Params
+		invalid bool
+	}{
+		{
+			name:    "unknown format",
+			gen:     New(),
+			format:  "uuidv1",
+			invalid: true,
+		},
+		{
+			name:    "uuidv5 missing name",
+			gen:     New(),
+			format:  FormatUUIDv5,
+			params:  Params{Namespace: "dns"},
+			invalid: true,
+		},
+		{
+			name:    "uuidv5 invalid namespace",
+			gen:     New(),
+			format:  FormatUUIDv5,
+			params:  Params{Namespace: "example", Name: "test"},
+			invalid: true,
+		},
+		{
+			name:    "nanoid duplicate characters",
+			gen:     New(),
+			format:  FormatNanoID,
+			params:  Params{Alphabet: "abca"},
+			invalid: true,
+		},
+		{
+			name:    "nanoid too long",
+			gen:     New(),
+			format:  FormatNanoID,
+			params:  Params{Length: MaxNanoIDLength + 1},
+			invalid: true,
+		},
+		{
+			name:    "snowflake invalid node",
+			gen:     New(WithSnowflake(MaxSnowflakeNode+1, DefaultSnowflakeEpoch)),
+			format:  FormatSnowflake,
+			invalid: true,
+		},
+		{
+			name:    "snowflake before epoch",
+			gen:     New(WithSnowflake(0, time.Now().Add(time.Hour).UnixMilli())),
+			format:  FormatSnowflake,
+			invalid: true,
+		},
+		{
+			name:   "source failure",
+			gen:    New(WithSource(iotest.ErrReader(errors.New("rng failure")))),
+			format: FormatNanoID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := tt.gen.Generate(tt.format, 1, tt.params)
+			require.Error(t, err)
+			require.Equal(t, tt.invalid, errors.Is(err, ErrInvalid))
+		})
+	}
+}
+
+func TestTimeOrdered(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range Formats() {
+		want := format == FormatUUIDv7 || format == FormatULID || format == FormatKSUID || format == FormatSnowflake
+		require.Equal(t, want, TimeOrdered(format), format)
+	}
+}
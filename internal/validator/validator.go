@@ -3,18 +3,32 @@ package validator
 
 import (
 	"context"
-	"regexp"
 
 	vt "github.com/go-playground/validator/v10"
 	val "github.com/tecnickcom/nurago/pkg/validator"
+	"github.com/tecnickcom/rndpwd/internal/charset"
+	"github.com/tecnickcom/rndpwd/internal/pattern"
 )
 
 const (
-	// ValidCharset is a string containing the valid characters for a password.
-	ValidCharset = "!\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~"
+	// ValidCharset is a string containing all the printable ASCII characters
+	// that are valid for a password.
+	ValidCharset = charset.Printable
 )
 
-var regexValidCharset = regexp.MustCompile("[^" + regexp.QuoteMeta(ValidCharset) + "]")
+// PolicyChecker is implemented by the generator settings whose
+// character-class policy can be checked as a whole against the other settings.
+// The policy field must have a Problem method returning the reason of the last
+// failed check, which is reported by the error message.
+type PolicyChecker interface {
+	CheckPolicy() error
+}
+
+// CharsetChecker is implemented by the generator settings whose effective
+// charset, once expanded and without the excluded characters, can be checked.
+type CharsetChecker interface {
+	CheckCharset() error
+}
 
 // Validator is the contract with the parent validator.
 type Validator interface {
@@ -25,11 +39,17 @@ type Validator interface {
 // New instantiate a new Validator.
 func New(fieldTagName string) (Validator, error) {
 	customValidationTags := map[string]vt.FuncCtx{
-		"rndcharset": validateRandomCharset(),
+		"rndcharset":    validateRandomCharset(),
+		"rndeffcharset": validateEffectiveCharset(),
+		"rndpolicy":     validatePolicy(),
+		"rndtemplate":   validateTemplate(),
 	}
 
 	errorTemplates := map[string]string{
-		"rndcharset": `{{.Namespace}} must contain only characters:` + ValidCharset,
+		"rndcharset":    `{{.Namespace}} is not a valid charset: {{.Value.Problem}}`,
+		"rndeffcharset": `{{.Namespace}} has no characters left once expanded and without the excluded ambiguous characters`,
+		"rndpolicy":     `{{.Namespace}} cannot be satisfied: {{.Value.Problem}}`,
+		"rndtemplate":   `{{.Namespace}} is not a valid template: {{.Value.Problem}}`,
 	}
 
 	//nolint:wrapcheck
@@ -42,6 +62,9 @@ func New(fieldTagName string) (Validator, error) {
 	)
 }
 
+// validateRandomCharset checks the characters of a literal charset, preset or
+// class expression. The field must be a charset.Set, so the error message can
+// report the offending character.
 func validateRandomCharset() vt.FuncCtx {
 	return func(_ context.Context, fl vt.FieldLevel) bool {
 		value := fl.Field().String()
@@ -50,6 +73,73 @@ func validateRandomCharset() vt.FuncCtx {
 			return true
 		}
 
-		return !regexValidCharset.MatchString(value)
+		// the expressions are expanded first, so only the resulting
+		// characters are checked
+		return charset.Set(value).Problem() == ""
+	}
+}
+
+// validateEffectiveCharset checks the field against the CheckCharset method of
+// its parent struct, so a charset emptied by the exclusion of the ambiguous
+// characters is reported as such.
+func validateEffectiveCharset() vt.FuncCtx {
+	return func(_ context.Context, fl vt.FieldLevel) bool {
+		cc, ok := parentInterface(fl).(CharsetChecker)
+		if !ok {
+			// nothing to check against
+			return true
+		}
+
+		return cc.CheckCharset() == nil
 	}
 }
+
+// validateTemplate checks the syntax of a password template. The field must be
+// a pattern.Template, so the error message can report the position of the
+// syntax error.
+func validateTemplate() vt.FuncCtx {
+	return func(_ context.Context, fl vt.FieldLevel) bool {
+		value := fl.Field().String()
+		if value == "" {
+			// the template is only required in template mode
+			return true
+		}
+
+		_, err := pattern.Parse(value, ValidCharset)
+
+		return err == nil
+	}
+}
+
+// validatePolicy checks the field against the CheckPolicy method of its parent
+// struct, so impossible combinations of class bounds, charset and length are
+// rejected (e.g. minimums adding up to more than the length).
+func validatePolicy() vt.FuncCtx {
+	return func(_ context.Context, fl vt.FieldLevel) bool {
+		parent := parentInterface(fl)
+
+		pc, ok := parent.(PolicyChecker)
+		if !ok {
+			// nothing to check against
+			return true
+		}
+
+		if cc, ok := parent.(CharsetChecker); ok && cc.CheckCharset() != nil {
+			// an empty effective charset is reported on the charset field
+			return true
+		}
+
+		return pc.CheckPolicy() == nil
+	}
+}
+
+// parentInterface returns the parent struct of the field, by pointer when
+// addressable so its pointer methods are available.
+func parentInterface(fl vt.FieldLevel) any {
+	parent := fl.Parent()
+	if parent.CanAddr() {
+		parent = parent.Addr()
+	}
+
+	return parent.Interface()
+}
@@ -1,16 +1,19 @@
 package validator
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/rndpwd/internal/charset"
+	"github.com/tecnickcom/rndpwd/internal/pattern"
 )
 
 func TestValidator(t *testing.T) {
 	t.Parallel()
 
 	type valTest struct {
-		Charset string `json:"charset" validate:"rndcharset"`
+		Charset charset.Set `json:"charset" validate:"rndcharset"`
 	}
 
 	tests := []struct {
@@ -33,6 +36,61 @@ func TestValidator(t *testing.T) {
 			in:      &valTest{Charset: ""},
 			wantErr: false,
 		},
+		{
+			name:    "printable ascii",
+			in:      &valTest{Charset: ValidCharset},
+			wantErr: false,
+		},
+		{
+			name:    "unicode scripts",
+			in:      &valTest{Charset: "àéîõüßÆαβγΩабвгЖ漢字かなカナ€£¥±½"},
+			wantErr: false,
+		},
+		{
+			name:    "control character",
+			in:      &valTest{Charset: "abc\t"},
+			wantErr: true,
+		},
+		{
+			name:    "non-breaking space",
+			in:      &valTest{Charset: "abc\u00a0"},
+			wantErr: true,
+		},
+		{
+			name:    "combining mark",
+			in:      &valTest{Charset: "abc\u0301"},
+			wantErr: true,
+		},
+		{
+			name:    "zero-width joiner",
+			in:      &valTest{Charset: "abc\u200d"},
+			wantErr: true,
+		},
+		{
+			name:    "preset expression",
+			in:      &valTest{Charset: `alnum-"0O1l"`},
+			wantErr: false,
+		},
+		{
+			name:    "expression with invalid characters",
+			in:      &valTest{Charset: `digits+"a b"`},
+			wantErr: true,
+		},
+		{
+			name:    "literal with operators",
+			in:      &valTest{Charset: "ABCDEF-abcdef+0-9"},
+			wantErr: false,
+		},
+		{
+			name:    "expression with unknown preset",
+			in:      &valTest{Charset: "digits unknown"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid utf-8",
+			in:      &valTest{Charset: "abc\xff"},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -47,3 +105,85 @@ func TestValidator(t *testing.T) {
 		})
 	}
 }
+
+// policyTest is a struct whose policy field is checked by its CheckPolicy method.
+type policyTest struct {
+	Charset    string     `json:"charset" validate:"rndeffcharset"`
+	Policy     testPolicy `json:"policy"  validate:"rndpolicy"`
+	err        error
+	charsetErr error
+}
+
+func (p *policyTest) CheckPolicy() error {
+	if p.err != nil {
+		p.Policy.problem = p.err.Error()
+	}
+
+	return p.err
+}
+
+func (p *policyTest) CheckCharset() error {
+	return p.charsetErr
+}
+
+// testPolicy is a policy field reporting the reason of the last failed check.
+type testPolicy struct {
+	problem string
+}
+
+func (p testPolicy) Problem() string {
+	return p.problem
+}
+
+func TestValidatorPolicy(t *testing.T) {
+	t.Parallel()
+
+	type noChecker struct {
+		Policy string `json:"policy" validate:"rndpolicy"`
+	}
+
+	v, err := New("json")
+	require.NoError(t, err)
+
+	require.NoError(t, v.ValidateStruct(&policyTest{}))
+	require.ErrorContains(t, v.ValidateStruct(&policyTest{err: errors.New("class x has no characters")}), "cannot be satisfied: class x has no characters")
+
+	err = v.ValidateStruct(&policyTest{err: errors.New("empty"), charsetErr: errors.New("empty")})
+	require.ErrorContains(t, err, "charset has no characters left")
+	require.NotContains(t, err.Error(), "cannot be satisfied")
+	require.NoError(t, v.ValidateStruct(&noChecker{}))
+}
+
+func TestValidatorTemplate(t *testing.T) {
+	t.Parallel()
+
+	type valTest struct {
+		Template pattern.Template `json:"template" validate:"rndtemplate"`
+	}
+
+	v, err := New("json")
+	require.NoError(t, err)
+
+	require.NoError(t, v.ValidateStruct(&valTest{}))
+	require.NoError(t, v.ValidateStruct(&valTest{Template: "Cvcc-9999-SS"}))
+
+	err = v.ValidateStruct(&valTest{Template: "Cvcc-9999-[SS"})
+	require.ErrorContains(t, err, "position 11")
+}
+
+func TestValidatorCharsetMessage(t *testing.T) {
+	t.Parallel()
+
+	type valTest struct {
+		Charset charset.Set `json:"charset" validate:"rndcharset"`
+	}
+
+	v, err := New("json")
+	require.NoError(t, err)
+
+	err = v.ValidateStruct(&valTest{Charset: "abc\t"})
+	require.ErrorContains(t, err, `invalid character '\t'`)
+
+	err = v.ValidateStruct(&valTest{Charset: "digits unknown"})
+	require.ErrorContains(t, err, `"unknown" is not a preset`)
+}